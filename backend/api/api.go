@@ -0,0 +1,50 @@
+// Package api holds the request and response types of the backend's HTTP
+// API, so Go clients can build requests and decode replies with the same
+// types the server uses.
+package api
+
+import "fmt"
+
+// Prefix is the path every current route lives under. The unversioned
+// /api paths still work but are deprecated.
+const Prefix = "/api/v1"
+
+// Error codes
+const (
+	CodeInvalidRequest   = "invalid_request" // The request or one of its fields is malformed
+	CodeForbidden        = "forbidden"       // The credentials given do not allow it
+	CodeNotFound         = "not_found"       // The route or record does not exist
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeTooLarge         = "too_large"     // The body or batch is over its limit
+	CodeUnprocessable    = "unprocessable" // Valid, but cannot be acted on
+	CodeUnavailable      = "unavailable"   // The server is shutting down or busy
+	CodeInternal         = "internal"
+)
+
+// Error is the body of every error response. Field names the request field
+// at fault, as it is spelled in JSON, when there is one. Details carries
+// anything else that helps, such as the limits a value broke.
+type Error struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Field   string                 `json:"field,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return e.Field + ": " + e.Message
+	}
+	return e.Message
+}
+
+// InvalidField reports a request field with a bad value
+func InvalidField(field, format string, args ...interface{}) *Error {
+	return &Error{Code: CodeInvalidRequest, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithLimits adds the range a value must lie in to the error's details
+func (e *Error) WithLimits(min, max int) *Error {
+	e.Details = map[string]interface{}{"min": min, "max": max}
+	return e
+}
@@ -0,0 +1,18 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	e := &Error{Code: CodeInvalidRequest, Message: "Must provide exactly 2 hole cards", Field: "holeCards"}
+	if got := e.Error(); got != "holeCards: Must provide exactly 2 hole cards" {
+		t.Errorf("Expected the field before the message, got %q", got)
+	}
+
+	data, _ := json.Marshal(&Error{Code: CodeNotFound, Message: "Not found"})
+	if string(data) != `{"code":"not_found","message":"Not found"}` {
+		t.Errorf("Expected field and details to be left out when empty, got %s", data)
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import "encoding/json"
+
+type EvaluateHandRequest struct {
+	HoleCards  []string `json:"holeCards"`
+	BoardCards []string `json:"boardCards"`
+}
+
+type EvaluateHandResponse struct {
+	BestHand  string        `json:"bestHand"`
+	HandValue string        `json:"handValue"`
+	Cards     []string      `json:"cards"`
+	Strength  *HandStrength `json:"strength,omitempty"`
+}
+
+// RankedHand is a hand value of the board, with how many holdings make it
+// and one of them
+type RankedHand struct {
+	Hand    string   `json:"hand"`
+	Value   string   `json:"value"`
+	Combos  int      `json:"combos"`
+	Example []string `json:"example"`
+}
+
+// HandStrength places a holding among every other holding on the same
+// board, as poker.HandStrength does
+type HandStrength struct {
+	Nuts         RankedHand   `json:"nuts"`
+	Position     int          `json:"position"` // 1 for the nuts
+	Label        string       `json:"label"`    // e.g. "2nd nuts"
+	Beats        float64      `json:"beats"`    // Share of other holdings beaten
+	CombosAhead  int          `json:"combosAhead"`
+	CombosTied   int          `json:"combosTied"`
+	CombosBehind int          `json:"combosBehind"`
+	Ahead        []RankedHand `json:"ahead"` // What beats the holding, best first, kickers merged
+}
+
+type CompareHandsRequest struct {
+	Player1HoleCards []string `json:"player1HoleCards"`
+	Player2HoleCards []string `json:"player2HoleCards"`
+	CommunityCards   []string `json:"communityCards"`
+}
+
+type CompareHandsResponse struct {
+	Player1 EvaluateHandResponse `json:"player1"`
+	Player2 EvaluateHandResponse `json:"player2"`
+	Winner  string               `json:"winner"`
+}
+
+// ShowdownRequest holds the hole cards of two or more players and the five
+// community cards
+type ShowdownRequest struct {
+	Hands          [][]string `json:"hands"`
+	CommunityCards []string   `json:"communityCards"`
+}
+
+// ShowdownResponse has each player's best hand, in the order given, and the
+// indexes of the players who win, more than one on a split
+type ShowdownResponse struct {
+	Hands   []EvaluateHandResponse `json:"hands"`
+	Winners []int                  `json:"winners"`
+}
+
+// EquityRequest holds the hole cards of two or more players and 0 to 5
+// board cards. Boards missing one or two cards are enumerated exactly;
+// earlier streets are sampled NumSimulations times, 10000 by default.
+type EquityRequest struct {
+	Hands          [][]string `json:"hands"`
+	BoardCards     []string   `json:"boardCards"`
+	NumSimulations int        `json:"numSimulations"`
+	Seed           int64      `json:"seed,omitempty"` // The same seed gives the same equities; from the clock when zero
+}
+
+// EquityResponse has each player's share of the pot, split pots included.
+// While progress is being streamed Done counts the simulations so far out
+// of Simulations; the last message has Done equal to Simulations.
+type EquityResponse struct {
+	Equity      []float64 `json:"equity"`
+	Done        int       `json:"done"`
+	Simulations int       `json:"simulations"`
+	Exact       bool      `json:"exact"` // Every runout was enumerated
+}
+
+// BatchResult is the outcome of one item of a batch request, by its
+// position in the input. Result is an EvaluateHandResponse or a
+// CompareHandsResponse.
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Result interface{} `json:"result,omitempty"`
+	Error  *Error      `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results   []BatchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
+
+type MonteCarloRequest struct {
+	HoleCards      []string `json:"holeCards"`
+	BoardCards     []string `json:"boardCards"`
+	NumPlayers     int      `json:"numPlayers"`
+	NumSimulations int      `json:"numSimulations"`
+	Seed           int64    `json:"seed,omitempty"` // The same seed gives the same odds; from the clock when zero
+}
+
+type MonteCarloResponse struct {
+	WinProbability  float64 `json:"winProbability"`
+	TieProbability  float64 `json:"tieProbability"`
+	LossProbability float64 `json:"lossProbability"`
+	Margin          float64 `json:"margin"` // 95% margin of error of the probabilities
+	Simulations     int     `json:"simulations"`
+	Source          string  `json:"source"` // "simulation", or "table" for preflop lookups
+	JobID           string  `json:"jobId,omitempty"`
+}
+
+type OutsRequest struct {
+	HoleCards  []string   `json:"holeCards"`
+	BoardCards []string   `json:"boardCards"`
+	Opponents  [][]string `json:"opponents"`
+}
+
+type PotentialRequest struct {
+	HoleCards  []string `json:"holeCards"`
+	BoardCards []string `json:"boardCards"`
+	Range      string   `json:"range"` // e.g. "TT+,AQs+", empty for any two cards
+	Opponents  int      `json:"opponents"`
+}
+
+type PreflopResponse struct {
+	Hand      string `json:"hand"`
+	Villain   string `json:"villain,omitempty"`
+	Opponents int    `json:"opponents,omitempty"`
+	Odds
+	Loss float64 `json:"loss"`
+}
+
+// Odds are the chances of winning and tying an all-in, and the share of
+// the pot it is worth
+type Odds struct {
+	Win    float64 `json:"win"`
+	Tie    float64 `json:"tie"`
+	Equity float64 `json:"equity"`
+	Margin float64 `json:"margin"` // 95% margin of error of sampled odds, 0 when exact
+}
+
+type PlayerUpdateRequest struct {
+	DisplayName string `json:"displayName"`
+	Notes       string `json:"notes"`
+}
+
+type ImportedHand struct {
+	HandID      string `json:"handId"`
+	Site        string `json:"site"`
+	Table       string `json:"table"`
+	Players     int    `json:"players"`
+	TotalPot    int64  `json:"totalPot"`
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verifyError,omitempty"`
+}
+
+type ImportResponse struct {
+	Imported   int            `json:"imported"`
+	Duplicates int            `json:"duplicates"`
+	Hands      []ImportedHand `json:"hands"`
+	Errors     []ImportError  `json:"errors"`
+}
+
+// ConvertOHHResponse holds one Open Hand History file, an object with an
+// "ohh" member, per hand converted
+type ConvertOHHResponse struct {
+	Hands  []json.RawMessage `json:"hands"`
+	Errors []ImportError     `json:"errors"`
+}
+
+type StatsResponse struct {
+	TotalHands int           `json:"totalHands"`
+	Players    []PlayerStats `json:"players"`
+}
+
+// Stat is a count out of the chances there were for it, and their ratio
+type Stat struct {
+	Count         int     `json:"count"`
+	Opportunities int     `json:"opportunities"`
+	Value         float64 `json:"value"`
+}
+
+// PlayerStats are the HUD statistics of one player. The aggression factor
+// counts postflop bets and raises against postflop calls, so its
+// Opportunities are the calls and its Value can exceed 1.
+type PlayerStats struct {
+	ID         string `json:"id"`
+	Player     string `json:"player"`
+	Hands      int    `json:"hands"`
+	VPIP       Stat   `json:"vpip"`
+	PFR        Stat   `json:"pfr"`
+	ThreeBet   Stat   `json:"threeBet"`
+	FoldTo3Bet Stat   `json:"foldToThreeBet"`
+	CBet       Stat   `json:"cbet"`
+	Aggression Stat   `json:"aggressionFactor"`
+	WTSD       Stat   `json:"wtsd"`
+	WonAtSD    Stat   `json:"wsd"`
+	SawFlop    Stat   `json:"sawFlop"`
+}
+
+// ImportError is a hand history that could not be read, by file and line
+type ImportError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	HandID  string `json:"handId,omitempty"`
+	Message string `json:"message"`
+}
+
+// ReadinessResponse tells whether the server should be sent traffic, with
+// the reasons when it should not
+type ReadinessResponse struct {
+	Ready              bool     `json:"ready"`
+	SelfTest           string   `json:"selfTest"` // "running", "passed" or "failed"
+	Draining           bool     `json:"draining"`
+	Simulations        int      `json:"simulations"`
+	SimulationCapacity int      `json:"simulationCapacity"`
+	Reasons            []string `json:"reasons,omitempty"`
+}
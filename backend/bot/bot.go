@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"math/rand"
+	"time"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/poker"
+)
+
+// Bot decides what to do when it is its turn at a table
+type Bot interface {
+	Decide(state game.TableState) game.Action
+}
+
+// New creates a built-in bot by strategy name: "random", "tag" or "equity"
+func New(strategy string, rng *rand.Rand) (Bot, bool) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	switch strategy {
+	case "random":
+		return &RandomBot{Rand: rng}, true
+	case "tag":
+		return &TightAggressiveBot{}, true
+	case "equity":
+		return &EquityBot{Simulations: 300}, true
+	}
+	return nil, false
+}
+
+// RandomBot picks a uniformly random legal action and bet size
+type RandomBot struct {
+	Rand *rand.Rand
+}
+
+func (b *RandomBot) Decide(state game.TableState) game.Action {
+	opts := options(state)
+	action := game.Action{Type: opts.Actions[b.Rand.Intn(len(opts.Actions))]}
+	if action.Type == game.Bet || action.Type == game.Raise {
+		action.Amount = opts.MinAmount + b.Rand.Intn(opts.MaxAmount-opts.MinAmount+1)
+	}
+	return action
+}
+
+// TightAggressiveBot plays few starting hands, chosen by preflop hand
+// group, and bets them hard. After the flop it bets made hands of two pair
+// or better, calls with a pair and gives up otherwise.
+type TightAggressiveBot struct{}
+
+func (b *TightAggressiveBot) Decide(state game.TableState) game.Action {
+	opts := options(state)
+	hole := holeCards(state)
+
+	if state.Street == game.Preflop {
+		group := HandGroup(hole)
+		cheap := opts.CallAmount <= state.BigBlind
+		unopened := state.CurrentBet <= state.BigBlind
+
+		switch {
+		case group <= 2:
+			return aggress(opts, state.CurrentBet*3)
+		case group <= 4 && unopened:
+			return aggress(opts, state.BigBlind*3)
+		case group <= 4:
+			return passive(opts)
+		case group <= 6 && cheap:
+			return passive(opts)
+		}
+		return giveUp(opts)
+	}
+
+	score := madeHand(hole, state.Board)
+	switch {
+	case score.Rank >= poker.TwoPair:
+		return aggress(opts, state.CurrentBet+state.Pot*2/3)
+	case score.Rank == poker.OnePair:
+		return passive(opts)
+	}
+	return giveUp(opts)
+}
+
+// EquityBot calls whenever its Monte Carlo equity against the players left
+// in the hand beats the pot odds, and raises when it is a clear favourite.
+type EquityBot struct {
+	Simulations int
+}
+
+func (b *EquityBot) Decide(state game.TableState) game.Action {
+	opts := options(state)
+	hole := holeCards(state)
+
+	players := 0
+	for _, s := range state.Seats {
+		if s.InHand && !s.Folded {
+			players++
+		}
+	}
+	if players < 2 {
+		players = 2
+	}
+
+	win, tie, _ := poker.MonteCarloSimulation(hole, state.Board, players, b.Simulations)
+	equity := win + tie/2
+
+	potOdds := 0.0
+	if opts.CallAmount > 0 {
+		potOdds = float64(opts.CallAmount) / float64(state.Pot+opts.CallAmount)
+	}
+
+	switch {
+	case equity > 0.5+potOdds/2:
+		return aggress(opts, state.CurrentBet+state.Pot)
+	case equity > potOdds:
+		return passive(opts)
+	}
+	return giveUp(opts)
+}
+
+func options(state game.TableState) game.ActionOptions {
+	if state.Options == nil {
+		return game.ActionOptions{Actions: []game.ActionType{game.Fold}}
+	}
+	return *state.Options
+}
+
+func holeCards(state game.TableState) []string {
+	for _, s := range state.Seats {
+		if s.Seat == state.YourSeat {
+			return s.Cards
+		}
+	}
+	return nil
+}
+
+func madeHand(hole, board []string) poker.HandScore {
+	cards, err := poker.ParseCards(append(append([]string(nil), hole...), board...))
+	if err != nil {
+		return poker.HandScore{}
+	}
+	return poker.EvaluateBestHand(cards)
+}
+
+// aggress bets or raises to the target, clamped to the legal range, and
+// falls back to calling when raising is not allowed
+func aggress(opts game.ActionOptions, target int) game.Action {
+	for _, t := range []game.ActionType{game.Raise, game.Bet} {
+		if opts.Allows(t) {
+			if target < opts.MinAmount {
+				target = opts.MinAmount
+			}
+			if target > opts.MaxAmount {
+				target = opts.MaxAmount
+			}
+			return game.Action{Type: t, Amount: target}
+		}
+	}
+	return passive(opts)
+}
+
+// passive checks or calls
+func passive(opts game.ActionOptions) game.Action {
+	if opts.Allows(game.Check) {
+		return game.Action{Type: game.Check}
+	}
+	if opts.Allows(game.Call) {
+		return game.Action{Type: game.Call}
+	}
+	return game.Action{Type: game.Fold}
+}
+
+// giveUp checks when free and folds otherwise
+func giveUp(opts game.ActionOptions) game.Action {
+	if opts.Allows(game.Check) {
+		return game.Action{Type: game.Check}
+	}
+	return game.Action{Type: game.Fold}
+}
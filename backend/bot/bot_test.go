@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"math/rand"
+	"testing"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/poker"
+)
+
+func TestHandClassAndGroup(t *testing.T) {
+	tests := []struct {
+		hole          []string
+		expectedClass string
+		expectedGroup int
+	}{
+		{[]string{"HA", "SA"}, "AA", 1},
+		{[]string{"SK", "SA"}, "AKs", 1},
+		{[]string{"HA", "SK"}, "AKo", 2},
+		{[]string{"DT", "DJ"}, "JTs", 3},
+		{[]string{"C5", "CA"}, "A5s", 5},
+		{[]string{"H2", "S2"}, "22", 7},
+		{[]string{"H7", "S2"}, "72o", 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedClass, func(t *testing.T) {
+			if class, _ := poker.HandClass(tt.hole); class != tt.expectedClass {
+				t.Errorf("Expected class %s, got %s", tt.expectedClass, class)
+			}
+			if group := HandGroup(tt.hole); group != tt.expectedGroup {
+				t.Errorf("Expected group %d, got %d", tt.expectedGroup, group)
+			}
+		})
+	}
+}
+
+func decisionState(hole, board []string, opts game.ActionOptions, pot int) game.TableState {
+	street := game.Preflop
+	switch len(board) {
+	case 3:
+		street = game.Flop
+	case 4:
+		street = game.Turn
+	case 5:
+		street = game.River
+	}
+	return game.TableState{
+		Street:     street,
+		BigBlind:   2,
+		Board:      board,
+		Pot:        pot,
+		CurrentBet: opts.CallAmount,
+		YourSeat:   0,
+		Seats: []game.SeatState{
+			{Seat: 0, InHand: true, Cards: hole},
+			{Seat: 1, InHand: true},
+		},
+		Options: &opts,
+	}
+}
+
+func TestBotDecisions(t *testing.T) {
+	facingBet := game.ActionOptions{Actions: []game.ActionType{game.Fold, game.Call, game.Raise}, CallAmount: 50, MinAmount: 100, MaxAmount: 500}
+
+	tests := []struct {
+		name     string
+		bot      Bot
+		state    game.TableState
+		expected game.ActionType
+	}{
+		{"TAG raises aces", &TightAggressiveBot{}, decisionState([]string{"HA", "SA"}, nil, facingBet, 60), game.Raise},
+		{"TAG folds trash", &TightAggressiveBot{}, decisionState([]string{"H7", "S2"}, nil, facingBet, 60), game.Fold},
+		{"TAG raises a set", &TightAggressiveBot{}, decisionState([]string{"H7", "S7"}, []string{"D7", "CK", "S2"}, facingBet, 150), game.Raise},
+		{"TAG calls with a pair", &TightAggressiveBot{}, decisionState([]string{"HK", "SQ"}, []string{"DK", "C8", "S2"}, facingBet, 150), game.Call},
+		{"Equity bot raises the nuts", &EquityBot{Simulations: 200}, decisionState([]string{"HA", "HK"}, []string{"HQ", "HJ", "HT"}, facingBet, 150), game.Raise},
+		{"Equity bot folds without odds", &EquityBot{Simulations: 200}, decisionState([]string{"H7", "S2"}, []string{"DA", "CK", "SQ", "DJ", "C9"}, facingBet, 60), game.Fold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := tt.bot.Decide(tt.state)
+			if action.Type != tt.expected {
+				t.Errorf("Expected %s, got %+v", tt.expected, action)
+			}
+			if err := tt.state.Options.Validate(action); err != nil {
+				t.Errorf("Bot chose an illegal action: %v", err)
+			}
+		})
+	}
+}
+
+func TestHeadlessRun(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	structures := []game.BettingStructure{game.NoLimit{}, game.PotLimit{}, game.FixedLimit{SmallBet: 2, BigBet: 4}}
+
+	for _, structure := range structures {
+		t.Run(structure.Name(), func(t *testing.T) {
+			bots := []Bot{&RandomBot{Rand: rng}, &TightAggressiveBot{}, &RandomBot{Rand: rng}, &TightAggressiveBot{}, &RandomBot{Rand: rng}}
+			cfg := game.TableConfig{SmallBlind: 1, BigBlind: 2, Structure: structure, Rand: rng}
+
+			result, err := Run(cfg, bots, 2000, 200)
+			if err != nil {
+				t.Fatalf("Run failed after %d hands: %v", result.Hands, err)
+			}
+			net := 0
+			for _, n := range result.Net {
+				net += n
+			}
+			if net != 0 {
+				t.Errorf("Expected bots to net zero, got %d", net)
+			}
+		})
+	}
+}
+
+func TestHeadlessRunWithEquityBot(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	bots := []Bot{&EquityBot{Simulations: 100}, &RandomBot{Rand: rng}}
+	cfg := game.TableConfig{SmallBlind: 1, BigBlind: 2, Rand: rng}
+
+	if _, err := Run(cfg, bots, 30, 200); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"fmt"
+
+	"texas-holdem-backend/game"
+)
+
+// Play lets the bots act for as long as it is a bot's turn. It returns when
+// the hand is over or a player without a bot is to act.
+func Play(table *game.Table, bots map[string]Bot) error {
+	for table.InHand() {
+		p := table.ToAct()
+		b, ok := bots[p.ID]
+		if !ok {
+			return nil
+		}
+		action := b.Decide(table.State(p.ID))
+		if err := table.Act(p.ID, action); err != nil {
+			return fmt.Errorf("hand %d: %s chose %+v: %w", table.HandNumber(), p.Name, action, err)
+		}
+	}
+	return nil
+}
+
+// RunResult summarises a headless bot session
+type RunResult struct {
+	Hands  int
+	Net    []int // Chips won or lost by each bot, rebuys included
+	Rebuys []int
+}
+
+// Run plays hands between the bots at a fresh table, rebuying busted bots,
+// and checks after every hand that no chips were created or lost.
+func Run(cfg game.TableConfig, bots []Bot, hands, buyIn int) (RunResult, error) {
+	if cfg.MaxSeats < len(bots) {
+		cfg.MaxSeats = len(bots)
+	}
+	table := game.NewTable("headless", cfg)
+	byID := make(map[string]Bot)
+	result := RunResult{Net: make([]int, len(bots)), Rebuys: make([]int, len(bots))}
+
+	for i, b := range bots {
+		id := fmt.Sprintf("bot-%d", i)
+		byID[id] = b
+		if err := table.Sit(id, fmt.Sprintf("Bot %d", i+1), i, buyIn); err != nil {
+			return result, err
+		}
+	}
+
+	bankroll := buyIn * len(bots)
+	for result.Hands < hands {
+		for i := range bots {
+			id := fmt.Sprintf("bot-%d", i)
+			if table.Player(id).Stack == 0 {
+				table.AddChips(id, buyIn)
+				result.Rebuys[i]++
+				bankroll += buyIn
+			}
+		}
+
+		if err := table.StartHand(); err != nil {
+			return result, err
+		}
+		if err := Play(table, byID); err != nil {
+			return result, err
+		}
+		result.Hands++
+
+		total := 0
+		for i := range bots {
+			total += table.Player(fmt.Sprintf("bot-%d", i)).Stack
+		}
+		if total != bankroll {
+			return result, fmt.Errorf("hand %d: %d chips in play, expected %d", table.HandNumber(), total, bankroll)
+		}
+	}
+
+	for i := range bots {
+		p := table.Player(fmt.Sprintf("bot-%d", i))
+		result.Net[i] = p.Stack - buyIn*(1+result.Rebuys[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+// Command botrunner plays bot-vs-bot hands without a server to smoke-test
+// the game engine.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"texas-holdem-backend/bot"
+	"texas-holdem-backend/game"
+)
+
+func main() {
+	hands := flag.Int("hands", 5000, "number of hands to play")
+	strategies := flag.String("bots", "random,tag,random,tag,random,tag", "comma-separated bot strategies (random, tag, equity)")
+	structure := flag.String("structure", "nl", "betting structure: nl, pl or fl")
+	buyIn := flag.Int("buyin", 200, "buy-in in chips")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed")
+	flag.Parse()
+
+	rng := rand.New(rand.NewSource(*seed))
+
+	var bots []bot.Bot
+	names := strings.Split(*strategies, ",")
+	for _, name := range names {
+		b, ok := bot.New(strings.TrimSpace(name), rng)
+		if !ok {
+			log.Fatalf("Unknown bot strategy: %s", name)
+		}
+		bots = append(bots, b)
+	}
+
+	cfg := game.TableConfig{SmallBlind: 1, BigBlind: 2, Rand: rng}
+	switch *structure {
+	case "nl":
+		cfg.Structure = game.NoLimit{}
+	case "pl":
+		cfg.Structure = game.PotLimit{}
+	case "fl":
+		cfg.Structure = game.FixedLimit{SmallBet: 2, BigBet: 4}
+	default:
+		log.Fatalf("Unknown betting structure: %s", *structure)
+	}
+
+	start := time.Now()
+	result, err := bot.Run(cfg, bots, *hands, *buyIn)
+	if err != nil {
+		log.Fatalf("Engine error after %d hands: %v", result.Hands, err)
+	}
+
+	fmt.Printf("Played %d hands in %v (seed %d)\n", result.Hands, time.Since(start).Round(time.Millisecond), *seed)
+	for i, name := range names {
+		fmt.Printf("  Bot %d (%s): %+d chips, %d rebuys\n", i+1, name, result.Net[i], result.Rebuys[i])
+	}
+}
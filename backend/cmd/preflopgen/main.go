@@ -0,0 +1,36 @@
+// Command preflopgen generates the preflop all-in equity table embedded in
+// the poker package. Run it through go generate in backend/poker.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"texas-holdem-backend/poker"
+)
+
+func main() {
+	out := flag.String("o", "preflop_equity.bin", "output file")
+	multiway := flag.Int("multiway", 1000000, "boards sampled per hand class and number of random opponents")
+	seed := flag.Int64("seed", 1, "random seed")
+	flag.Parse()
+
+	start := time.Now()
+	table := poker.BuildPreflopTable(*multiway, *seed, func(done, total int) {
+		if done%13 == 0 || done == total {
+			fmt.Printf("%d/%d hand classes (%v)\n", done, total, time.Since(start).Round(time.Second))
+		}
+	})
+
+	data, err := table.MarshalBinary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %s (%d bytes) in %v\n", *out, len(data), time.Since(start).Round(time.Second))
+}
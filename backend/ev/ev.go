@@ -0,0 +1,123 @@
+// Package ev works out all-in adjusted winnings: what each player would
+// have won on average had the board after an all-in been run out every
+// possible way, as a measure of results with the luck taken out.
+package ev
+
+import (
+	"fmt"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+	"texas-holdem-backend/poker"
+)
+
+// AllIn is the point of a hand after which no more betting was possible
+type AllIn struct {
+	Street game.Street        `json:"street"`
+	Board  []string           `json:"board"`  // Board when the money went in
+	Equity map[string]float64 `json:"equity"` // Expected share of the whole pot
+}
+
+// Hand is the outcome of one hand. Net is what each player won less what
+// they put in, EV is the same at the all-in equities; without an all-in
+// with every hand known the two are equal.
+type Hand struct {
+	HandID string             `json:"handId"`
+	Net    map[string]int64   `json:"net"`
+	EV     map[string]float64 `json:"ev"`
+	AllIn  *AllIn             `json:"allIn,omitempty"`
+}
+
+// boardSize is the number of board cards out while betting on a street
+var boardSize = map[game.Street]int{game.Preflop: 0, game.Flop: 3, game.Turn: 4}
+
+// Analyze works out the actual and all-in adjusted result of every player
+// in a hand. Hands where players were all-in before the river are run out
+// exactly from the board at the time, provided all their cards are known.
+func Analyze(hh *handhistory.HandHistory) (Hand, error) {
+	committed := hh.Committed()
+	collected := make(map[string]int64)
+	folded := make(map[string]bool)
+	shown := make(map[string][]string)
+	last := game.Preflop
+	for _, a := range hh.Actions {
+		switch {
+		case a.Type == handhistory.Collect:
+			collected[a.Player] += a.Amount
+		case a.Type == handhistory.Fold:
+			folded[a.Player] = true
+		case a.Type == handhistory.Show:
+			shown[a.Player] = a.Cards
+		}
+		if a.IsVoluntary() {
+			last = a.Street
+		}
+	}
+
+	out := Hand{HandID: hh.HandID, Net: make(map[string]int64), EV: make(map[string]float64)}
+	for _, s := range hh.Seats {
+		if s.SittingOut {
+			continue
+		}
+		net := collected[s.Name] - committed[s.Name]
+		out.Net[s.Name] = net
+		out.EV[s.Name] = float64(net)
+	}
+
+	// Betting stopped before the river yet the board was run out, so
+	// every player left but one was all-in
+	size, ok := boardSize[last]
+	if !ok || len(hh.Board) != 5 {
+		return out, nil
+	}
+	players := make([]poker.PotPlayer, len(hh.Seats))
+	hands := make([][]string, len(hh.Seats))
+	live := 0
+	for i, s := range hh.Seats {
+		players[i] = poker.PotPlayer{Contribution: int(committed[s.Name]), Folded: folded[s.Name] || s.SittingOut}
+		if players[i].Folded {
+			continue
+		}
+		live++
+		hands[i] = shown[s.Name]
+		if hands[i] == nil {
+			hands[i] = s.HoleCards
+		}
+		if len(hands[i]) != 2 {
+			return out, nil
+		}
+	}
+	if live < 2 {
+		return out, nil
+	}
+
+	pots := poker.BuildPots(players)
+	board := hh.Board[:size]
+	won, err := poker.PotEquity(hands, board, pots)
+	if err != nil {
+		return out, fmt.Errorf("hand %s: %v", hh.HandID, err)
+	}
+
+	// Rake comes out of the pots in proportion to their size
+	var total, paid int64
+	for _, p := range players {
+		total += int64(p.Contribution)
+	}
+	for _, amount := range collected {
+		paid += amount
+	}
+	if total == 0 {
+		return out, nil
+	}
+	kept := float64(paid) / float64(total)
+
+	out.AllIn = &AllIn{Street: last, Board: append([]string{}, board...), Equity: make(map[string]float64)}
+	for i, s := range hh.Seats {
+		if players[i].Folded {
+			continue
+		}
+		out.AllIn.Equity[s.Name] = won[i] / float64(total)
+		out.EV[s.Name] = won[i]*kept - float64(committed[s.Name])
+	}
+	return out, nil
+}
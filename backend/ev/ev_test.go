@@ -0,0 +1,203 @@
+package ev
+
+import (
+	"math"
+	"testing"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+)
+
+const turnAllIn = `PokerStars Hand #1002:  Hold'em No Limit ($0.25/$0.50 USD) - 2023/03/05 15:15:42 ET
+Table 'Andromeda V' 6-max Seat #3 is the button
+Seat 1: Villain1 ($48.75 in chips)
+Seat 3: Hero ($50 in chips)
+Seat 5: big fish ($61.20 in chips)
+big fish: posts small blind $0.25
+Villain1: posts big blind $0.50
+*** HOLE CARDS ***
+Dealt to Hero [Qs Qd]
+Hero: raises $1 to $1.50
+big fish: calls $1.25
+Villain1: folds
+*** FLOP *** [Qh 7c 2d]
+big fish: checks
+Hero: bets $2
+big fish: raises $4 to $6
+Hero: calls $4
+*** TURN *** [Qh 7c 2d] [9s]
+big fish: bets $10
+Hero: raises $32.50 to $42.50 and is all-in
+big fish: calls $32.50
+*** RIVER *** [Qh 7c 2d 9s] [3h]
+*** SHOW DOWN ***
+big fish: shows [7d 7s] (three of a kind, Sevens)
+Hero: shows [Qs Qd] (three of a kind, Queens)
+Hero collected $97.50 from pot
+*** SUMMARY ***
+Total pot $100.50 | Rake $3
+`
+
+const preflopAllIn = `PokerStars Hand #1003:  Hold'em No Limit ($0.50/$1 USD) - 2023/03/05 15:17:02 ET
+Table 'Andromeda V' 6-max Seat #3 is the button
+Seat 3: Hero ($100 in chips)
+Seat 5: big fish ($60 in chips)
+Hero: posts small blind $0.50
+big fish: posts big blind $1
+*** HOLE CARDS ***
+Dealt to Hero [As Ad]
+Hero: raises $99 to $100 and is all-in
+big fish: calls $59 and is all-in
+Uncalled bet ($40) returned to Hero
+*** FLOP *** [Kh 7c 2d]
+*** TURN *** [Kh 7c 2d] [9s]
+*** RIVER *** [Kh 7c 2d 9s] [3h]
+*** SHOW DOWN ***
+Hero: shows [As Ad] (a pair of Aces)
+big fish: shows [Ks Kc] (three of a kind, Kings)
+big fish collected $120 from pot
+*** SUMMARY ***
+Total pot $120 | Rake $0
+`
+
+const noShowdown = `PokerStars Hand #1004:  Hold'em No Limit ($0.50/$1 USD) - 2023/03/05 15:18:40 ET
+Table 'Andromeda V' 6-max Seat #5 is the button
+Seat 3: Hero ($40 in chips)
+Seat 5: big fish ($120 in chips)
+big fish: posts small blind $0.50
+Hero: posts big blind $1
+*** HOLE CARDS ***
+Dealt to Hero [Th 9h]
+big fish: raises $2 to $3
+Hero: calls $2
+*** FLOP *** [Kh 7c 2d]
+Hero: checks
+big fish: bets $4
+Hero: folds
+Uncalled bet ($4) returned to big fish
+big fish collected $6 from pot
+*** SUMMARY ***
+Total pot $6 | Rake $0
+`
+
+func parse(t *testing.T, text string) *handhistory.HandHistory {
+	hands, errs := handhistory.ParseString(text)
+	if len(errs) != 0 || len(hands) != 1 {
+		t.Fatalf("Expected 1 hand, got %d hands and %v", len(hands), errs)
+	}
+	return hands[0]
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name   string
+		hand   string
+		street game.Street
+		net    map[string]int64
+		ev     map[string]float64
+	}{
+		{
+			name:   "Turn all-in with one out, raked",
+			hand:   turnAllIn,
+			street: game.Turn,
+			net:    map[string]int64{"Hero": 4750, "big fish": -5000, "Villain1": -50},
+			ev:     map[string]float64{"Hero": 9750*43.0/44 - 5000, "big fish": 9750.0/44 - 5000, "Villain1": -50},
+		},
+		{
+			name:   "Preflop all-in with the uncalled bet returned",
+			hand:   preflopAllIn,
+			street: game.Preflop,
+			net:    map[string]int64{"Hero": -6000, "big fish": 6000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := Analyze(parse(t, tt.hand))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if h.AllIn == nil || h.AllIn.Street != tt.street {
+				t.Fatalf("Expected an all-in on the %v, got %+v", tt.street, h.AllIn)
+			}
+			for name, net := range tt.net {
+				if h.Net[name] != net {
+					t.Errorf("Expected %s to net %d, got %d", name, net, h.Net[name])
+				}
+			}
+			for name, ev := range tt.ev {
+				if math.Abs(h.EV[name]-ev) > 1e-6 {
+					t.Errorf("Expected %s to have EV %v, got %v", name, ev, h.EV[name])
+				}
+			}
+			var sum float64
+			for _, e := range h.AllIn.Equity {
+				sum += e
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("Expected equities to add up to 1, got %v", h.AllIn.Equity)
+			}
+		})
+	}
+
+	h, err := Analyze(parse(t, preflopAllIn))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e := h.AllIn.Equity["Hero"]; e < 0.8 || e > 0.84 || len(h.AllIn.Board) != 0 {
+		t.Errorf("Expected aces to have about 82%% preflop, got %v on %v", e, h.AllIn.Board)
+	}
+
+	h, err = Analyze(parse(t, noShowdown))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if h.AllIn != nil || h.EV["Hero"] != -300 || h.Net["big fish"] != 300 {
+		t.Errorf("Expected actual results without an all-in, got %+v", h)
+	}
+
+	// Unknown cards leave the result as it was
+	hh := parse(t, turnAllIn)
+	hh.SeatOf("big fish").HoleCards = nil
+	var actions []handhistory.Action
+	for _, a := range hh.Actions {
+		if a.Type != handhistory.Show {
+			actions = append(actions, a)
+		}
+	}
+	hh.Actions = actions
+	h, err = Analyze(hh)
+	if err != nil || h.AllIn != nil || h.EV["Hero"] != 4750 {
+		t.Errorf("Expected no all-in adjustment without villain's cards, got %+v, %v", h, err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	hands := []*handhistory.HandHistory{parse(t, turnAllIn), parse(t, preflopAllIn), parse(t, noShowdown)}
+	report := Session(hands, "Hero")
+
+	if report.Hands != 3 || report.AllIns != 2 || report.Currency != "USD" || len(report.Points) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Net != 4750-6000-300 {
+		t.Errorf("Expected to net %d, got %d", 4750-6000-300, report.Net)
+	}
+
+	var ev float64
+	for i, p := range report.Points {
+		ev += p.EV
+		if p.Hand != i+1 || math.Abs(p.CumulativeEV-ev) > 1e-6 {
+			t.Errorf("Point %d: unexpected %+v", i, p)
+		}
+	}
+	if last := report.Points[2]; last.AllIn != nil || last.CumulativeNet != report.Net {
+		t.Errorf("Unexpected last point: %+v", last)
+	}
+	if math.Abs(report.Luck-(float64(report.Net)-report.EV)) > 1e-9 || report.Luck >= 0 {
+		t.Errorf("Expected Hero to have run below EV, got luck %v", report.Luck)
+	}
+
+	if villain := Session(hands, "Villain1"); villain.Hands != 1 || villain.AllIns != 0 || villain.Net != -50 {
+		t.Errorf("Unexpected report for a player in one hand: %+v", villain)
+	}
+}
@@ -0,0 +1,76 @@
+package ev
+
+import (
+	"time"
+
+	"texas-holdem-backend/handhistory"
+)
+
+// Point is one hand of a session, ready to plot against the hand number
+type Point struct {
+	Hand          int       `json:"hand"` // 1 for the first hand of the session
+	HandID        string    `json:"handId"`
+	Time          time.Time `json:"time"`
+	Net           int64     `json:"net"`
+	EV            float64   `json:"ev"`
+	CumulativeNet int64     `json:"cumulativeNet"`
+	CumulativeEV  float64   `json:"cumulativeEv"`
+	AllIn         *AllIn    `json:"allIn,omitempty"`
+}
+
+// Report compares a player's actual winnings over a session with their
+// all-in adjusted winnings. Amounts are in the units of the hand histories:
+// cents for cash games, chips otherwise.
+type Report struct {
+	Player   string   `json:"player"`
+	Currency string   `json:"currency,omitempty"`
+	Hands    int      `json:"hands"`
+	AllIns   int      `json:"allIns"`
+	Net      int64    `json:"net"`
+	EV       float64  `json:"ev"`
+	Luck     float64  `json:"luck"` // Net less EV
+	Points   []Point  `json:"points"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Session reports on the hands the player was dealt into, in the order
+// given. Hands whose all-in cannot be evaluated count at their actual
+// result and are listed in Errors.
+func Session(hands []*handhistory.HandHistory, player string) Report {
+	report := Report{Player: player, Points: []Point{}}
+	for _, hh := range hands {
+		h, err := Analyze(hh)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		net, ok := h.Net[player]
+		if !ok {
+			continue
+		}
+		if report.Currency == "" {
+			report.Currency = hh.Currency
+		}
+
+		report.Hands++
+		report.Net += net
+		report.EV += h.EV[player]
+		p := Point{
+			Hand:          report.Hands,
+			HandID:        hh.HandID,
+			Time:          hh.Time,
+			Net:           net,
+			EV:            h.EV[player],
+			CumulativeNet: report.Net,
+			CumulativeEV:  report.EV,
+		}
+		if h.AllIn != nil {
+			if _, in := h.AllIn.Equity[player]; in {
+				report.AllIns++
+				p.AllIn = h.AllIn
+			}
+		}
+		report.Points = append(report.Points, p)
+	}
+	report.Luck = float64(report.Net) - report.EV
+	return report
+}
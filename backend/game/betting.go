@@ -0,0 +1,203 @@
+package game
+
+import "fmt"
+
+// Street is a betting round of a hand
+type Street int
+
+const (
+	Preflop Street = iota
+	Flop
+	Turn
+	River
+	Showdown
+)
+
+func (s Street) String() string {
+	names := []string{"Preflop", "Flop", "Turn", "River", "Showdown"}
+	return names[s]
+}
+
+// ActionType is a player decision during a betting round
+type ActionType string
+
+const (
+	Fold  ActionType = "fold"
+	Check ActionType = "check"
+	Call  ActionType = "call"
+	Bet   ActionType = "bet"
+	Raise ActionType = "raise"
+)
+
+// Action is a player decision. For bets and raises Amount is the total the
+// player's bet on this street is brought to, not the size of the increase.
+type Action struct {
+	Type   ActionType `json:"type"`
+	Amount int        `json:"amount,omitempty"`
+}
+
+// BettingState describes the betting situation for the player to act
+type BettingState struct {
+	Street        Street
+	Pot           int // All chips in the middle, including bets on this street
+	CurrentBet    int // Highest bet on this street
+	PlayerBet     int // Chips the player has already bet on this street
+	Stack         int // Chips the player has behind
+	LastRaise     int // Size of the last bet or raise on this street
+	BigBlind      int
+	Raises        int // Bets and raises made on this street; the big blind counts as the preflop bet
+	ActivePlayers int // Players who have not folded
+}
+
+// ActionOptions lists the legal actions for the player to act and the
+// allowed bet or raise amounts, expressed as raise-to totals.
+type ActionOptions struct {
+	Actions    []ActionType `json:"actions"`
+	CallAmount int          `json:"callAmount"`
+	MinAmount  int          `json:"minAmount"`
+	MaxAmount  int          `json:"maxAmount"`
+}
+
+// Allows reports whether the action type is in the legal set
+func (o ActionOptions) Allows(t ActionType) bool {
+	for _, a := range o.Actions {
+		if a == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks an action against the legal options
+func (o ActionOptions) Validate(a Action) error {
+	if !o.Allows(a.Type) {
+		return fmt.Errorf("%s is not allowed, legal actions are %v", a.Type, o.Actions)
+	}
+	if a.Type == Bet || a.Type == Raise {
+		if a.Amount < o.MinAmount || a.Amount > o.MaxAmount {
+			return fmt.Errorf("%s to %d is outside the allowed range %d-%d", a.Type, a.Amount, o.MinAmount, o.MaxAmount)
+		}
+	}
+	return nil
+}
+
+// BettingStructure decides the legal actions and bet sizes of a game
+type BettingStructure interface {
+	Name() string
+	Options(s BettingState) ActionOptions
+}
+
+// NoLimit lets players bet any amount from a minimum raise up to their stack
+type NoLimit struct{}
+
+func (NoLimit) Name() string { return "No Limit" }
+
+func (NoLimit) Options(s BettingState) ActionOptions {
+	return buildOptions(s, minRaiseTo(s), s.PlayerBet+s.Stack, true)
+}
+
+// PotLimit caps every bet or raise at the size of the pot after calling
+type PotLimit struct{}
+
+func (PotLimit) Name() string { return "Pot Limit" }
+
+func (PotLimit) Options(s BettingState) ActionOptions {
+	toCall := s.CurrentBet - s.PlayerBet
+	if toCall > s.Stack {
+		toCall = s.Stack
+	}
+	maxTo := s.CurrentBet + s.Pot + toCall
+	if s.CurrentBet == 0 && maxTo < s.BigBlind {
+		maxTo = s.BigBlind
+	}
+	return buildOptions(s, minRaiseTo(s), maxTo, true)
+}
+
+// FixedLimit allows bets of exactly SmallBet preflop and on the flop and
+// BigBet on the turn and river. At most RaiseCap bets and raises are made
+// per street, a cap that does not apply when play is heads-up.
+type FixedLimit struct {
+	SmallBet int
+	BigBet   int
+	RaiseCap int
+}
+
+// DefaultRaiseCap is a bet and three raises
+const DefaultRaiseCap = 4
+
+func (FixedLimit) Name() string { return "Limit" }
+
+func (f FixedLimit) Options(s BettingState) ActionOptions {
+	size := f.SmallBet
+	if s.Street >= Turn {
+		size = f.BigBet
+	}
+	raiseCap := f.RaiseCap
+	if raiseCap == 0 {
+		raiseCap = DefaultRaiseCap
+	}
+	canRaise := s.Raises < raiseCap || s.ActivePlayers == 2
+	to := s.CurrentBet + size
+	return buildOptions(s, to, to, canRaise)
+}
+
+// StructureByName returns the betting structure with the given Name. Limit
+// games bet the big blind preflop and on the flop and twice that later.
+func StructureByName(name string, bigBlind int) (BettingStructure, bool) {
+	switch name {
+	case NoLimit{}.Name():
+		return NoLimit{}, true
+	case PotLimit{}.Name():
+		return PotLimit{}, true
+	case FixedLimit{}.Name():
+		return FixedLimit{SmallBet: bigBlind, BigBet: 2 * bigBlind}, true
+	}
+	return nil, false
+}
+
+func minRaiseTo(s BettingState) int {
+	increment := s.LastRaise
+	if increment < s.BigBlind {
+		increment = s.BigBlind
+	}
+	return s.CurrentBet + increment
+}
+
+func buildOptions(s BettingState, minTo, maxTo int, canRaise bool) ActionOptions {
+	opts := ActionOptions{}
+
+	toCall := s.CurrentBet - s.PlayerBet
+	if toCall > s.Stack {
+		toCall = s.Stack
+	}
+	if toCall > 0 {
+		opts.Actions = append(opts.Actions, Fold, Call)
+		opts.CallAmount = toCall
+	} else {
+		opts.Actions = append(opts.Actions, Check)
+	}
+
+	allIn := s.PlayerBet + s.Stack
+	if !canRaise || allIn <= s.CurrentBet {
+		return opts
+	}
+
+	if maxTo > allIn {
+		maxTo = allIn
+	}
+	if minTo > allIn {
+		minTo = allIn
+	}
+	if maxTo < minTo {
+		maxTo = minTo
+	}
+
+	if s.CurrentBet == 0 {
+		opts.Actions = append(opts.Actions, Bet)
+	} else {
+		opts.Actions = append(opts.Actions, Raise)
+	}
+	opts.MinAmount = minTo
+	opts.MaxAmount = maxTo
+	return opts
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBettingStructureOptions(t *testing.T) {
+	limit := FixedLimit{SmallBet: 2, BigBet: 4, RaiseCap: 4}
+
+	tests := []struct {
+		name      string
+		structure BettingStructure
+		state     BettingState
+		expected  ActionOptions
+	}{
+		{
+			name:      "No limit - open preflop",
+			structure: NoLimit{},
+			state:     BettingState{Street: Preflop, Pot: 3, CurrentBet: 2, LastRaise: 2, BigBlind: 2, Stack: 200, Raises: 1, ActivePlayers: 6},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 2, MinAmount: 4, MaxAmount: 200},
+		},
+		{
+			name:      "No limit - min raise follows the last raise size",
+			structure: NoLimit{},
+			state:     BettingState{Street: Flop, Pot: 70, CurrentBet: 30, PlayerBet: 10, LastRaise: 20, BigBlind: 2, Stack: 500, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 20, MinAmount: 50, MaxAmount: 510},
+		},
+		{
+			name:      "No limit - check or bet at least the big blind",
+			structure: NoLimit{},
+			state:     BettingState{Street: Turn, Pot: 40, BigBlind: 2, Stack: 100, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Check, Bet}, MinAmount: 2, MaxAmount: 100},
+		},
+		{
+			name:      "No limit - short stack can only go all-in for less",
+			structure: NoLimit{},
+			state:     BettingState{Street: Flop, Pot: 200, CurrentBet: 100, LastRaise: 100, BigBlind: 2, Stack: 150, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 100, MinAmount: 150, MaxAmount: 150},
+		},
+		{
+			name:      "No limit - stack smaller than the bet can only call all-in",
+			structure: NoLimit{},
+			state:     BettingState{Street: River, Pot: 300, CurrentBet: 200, BigBlind: 2, LastRaise: 200, Stack: 80, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call}, CallAmount: 80},
+		},
+		{
+			name:      "No limit - big blind option",
+			structure: NoLimit{},
+			state:     BettingState{Street: Preflop, Pot: 6, CurrentBet: 2, PlayerBet: 2, LastRaise: 2, BigBlind: 2, Stack: 98, Raises: 1, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Check, Raise}, MinAmount: 4, MaxAmount: 100},
+		},
+		{
+			name:      "Pot limit - raise from under the gun",
+			structure: PotLimit{},
+			state:     BettingState{Street: Preflop, Pot: 3, CurrentBet: 2, LastRaise: 2, BigBlind: 2, Stack: 200, Raises: 1, ActivePlayers: 6},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 2, MinAmount: 4, MaxAmount: 7},
+		},
+		{
+			name:      "Pot limit - small blind after a limp",
+			structure: PotLimit{},
+			state:     BettingState{Street: Preflop, Pot: 5, CurrentBet: 2, PlayerBet: 1, LastRaise: 2, BigBlind: 2, Stack: 199, Raises: 1, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 1, MinAmount: 4, MaxAmount: 8},
+		},
+		{
+			name:      "Pot limit - bet the pot",
+			structure: PotLimit{},
+			state:     BettingState{Street: Flop, Pot: 100, BigBlind: 2, Stack: 1000, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Check, Bet}, MinAmount: 2, MaxAmount: 100},
+		},
+		{
+			name:      "Pot limit - raise facing a bet",
+			structure: PotLimit{},
+			state:     BettingState{Street: Turn, Pot: 150, CurrentBet: 50, LastRaise: 50, BigBlind: 2, Stack: 1000, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 50, MinAmount: 100, MaxAmount: 250},
+		},
+		{
+			name:      "Pot limit - pot raise capped by stack",
+			structure: PotLimit{},
+			state:     BettingState{Street: Turn, Pot: 150, CurrentBet: 50, LastRaise: 50, BigBlind: 2, Stack: 120, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 50, MinAmount: 100, MaxAmount: 120},
+		},
+		{
+			name:      "Fixed limit - small bet on the flop",
+			structure: limit,
+			state:     BettingState{Street: Flop, Pot: 12, BigBlind: 2, Stack: 100, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Check, Bet}, MinAmount: 2, MaxAmount: 2},
+		},
+		{
+			name:      "Fixed limit - big bet raise on the turn",
+			structure: limit,
+			state:     BettingState{Street: Turn, Pot: 20, CurrentBet: 4, LastRaise: 4, BigBlind: 2, Stack: 100, Raises: 1, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 4, MinAmount: 8, MaxAmount: 8},
+		},
+		{
+			name:      "Fixed limit - capped multiway",
+			structure: limit,
+			state:     BettingState{Street: Flop, Pot: 40, CurrentBet: 8, PlayerBet: 2, LastRaise: 2, BigBlind: 2, Stack: 100, Raises: 4, ActivePlayers: 3},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call}, CallAmount: 6},
+		},
+		{
+			name:      "Fixed limit - cap lifted heads-up",
+			structure: limit,
+			state:     BettingState{Street: Flop, Pot: 40, CurrentBet: 8, PlayerBet: 6, LastRaise: 2, BigBlind: 2, Stack: 100, Raises: 4, ActivePlayers: 2},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 2, MinAmount: 10, MaxAmount: 10},
+		},
+		{
+			name:      "Fixed limit - default cap of a bet and three raises",
+			structure: FixedLimit{SmallBet: 2, BigBet: 4},
+			state:     BettingState{Street: Preflop, Pot: 21, CurrentBet: 8, LastRaise: 2, BigBlind: 2, Stack: 100, Raises: 4, ActivePlayers: 4},
+			expected:  ActionOptions{Actions: []ActionType{Fold, Call}, CallAmount: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.structure.Options(tt.state)
+			if !reflect.DeepEqual(opts, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, opts)
+			}
+		})
+	}
+}
+
+func TestActionOptionsValidate(t *testing.T) {
+	opts := ActionOptions{Actions: []ActionType{Fold, Call, Raise}, CallAmount: 2, MinAmount: 4, MaxAmount: 7}
+
+	tests := []struct {
+		action      Action
+		shouldError bool
+	}{
+		{Action{Type: Fold}, false},
+		{Action{Type: Call}, false},
+		{Action{Type: Raise, Amount: 4}, false},
+		{Action{Type: Raise, Amount: 7}, false},
+		{Action{Type: Raise, Amount: 3}, true},
+		{Action{Type: Raise, Amount: 8}, true},
+		{Action{Type: Check}, true},
+		{Action{Type: Bet, Amount: 4}, true},
+	}
+
+	for _, tt := range tests {
+		err := opts.Validate(tt.action)
+		if tt.shouldError && err == nil {
+			t.Errorf("Expected error for %+v", tt.action)
+		}
+		if !tt.shouldError && err != nil {
+			t.Errorf("Unexpected error for %+v: %v", tt.action, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType identifies what happened at a table
+type EventType string
+
+const (
+	EventSeat       EventType = "seat"
+	EventLeave      EventType = "leave"
+	EventHandStart  EventType = "handStart"
+	EventSmallBlind EventType = "smallBlind"
+	EventBigBlind   EventType = "bigBlind"
+	EventHoleCards  EventType = "holeCards"
+	EventAction     EventType = "action"
+	EventStreet     EventType = "street"
+	EventUncalled   EventType = "uncalled"
+	EventShowdown   EventType = "showdown"
+	EventPotAwarded EventType = "potAwarded"
+	EventHandEnd    EventType = "handEnd"
+)
+
+// Event is a change in table state. Private events (hole cards) must only be
+// delivered to the player in Seat; every other event is public.
+type Event struct {
+	Type            EventType   `json:"type"`
+	Hand            int         `json:"hand"`
+	Seat            int         `json:"seat"`
+	Name            string      `json:"name,omitempty"`
+	Street          Street      `json:"street"`
+	Action          ActionType  `json:"action,omitempty"`
+	Amount          int         `json:"amount,omitempty"`
+	AllIn           bool        `json:"allIn,omitempty"`
+	Cards           []string    `json:"cards,omitempty"`
+	Board           []string    `json:"board,omitempty"`
+	PotIndex        int         `json:"potIndex,omitempty"`
+	HandDescription string      `json:"handDescription,omitempty"`
+	Seats           []SeatState `json:"seats,omitempty"`
+	Time            time.Time   `json:"time,omitempty"`
+	Private         bool        `json:"-"`
+}
+
+// SeatState is the public view of a seated player. Cards are only filled in
+// for the viewing player or after the player has shown at showdown.
+type SeatState struct {
+	Seat   int      `json:"seat"`
+	Name   string   `json:"name"`
+	Stack  int      `json:"stack"`
+	Bet    int      `json:"bet"`
+	InHand bool     `json:"inHand"`
+	Folded bool     `json:"folded"`
+	AllIn  bool     `json:"allIn"`
+	Cards  []string `json:"cards,omitempty"`
+}
+
+// TableState is a snapshot of a table as seen by one viewer
+type TableState struct {
+	TableID    string         `json:"tableId"`
+	Structure  string         `json:"structure"`
+	SmallBlind int            `json:"smallBlind"`
+	BigBlind   int            `json:"bigBlind"`
+	Hand       int            `json:"hand"`
+	InHand     bool           `json:"inHand"`
+	Street     Street         `json:"street"`
+	Button     int            `json:"button"`
+	Board      []string       `json:"board"`
+	Pot        int            `json:"pot"`
+	CurrentBet int            `json:"currentBet"`
+	ToAct      int            `json:"toAct"`
+	Seats      []SeatState    `json:"seats"`
+	YourSeat   int            `json:"yourSeat"`
+	Options    *ActionOptions `json:"options,omitempty"`
+}
+
+// MarshalText encodes a street by name in JSON
+func (s Street) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a street from its name
+func (s *Street) UnmarshalText(text []byte) error {
+	for st := Preflop; st <= Showdown; st++ {
+		if st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown street: %s", text)
+}
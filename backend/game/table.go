@@ -0,0 +1,741 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"texas-holdem-backend/poker"
+)
+
+var (
+	ErrSeatTaken     = errors.New("seat is already taken")
+	ErrInvalidSeat   = errors.New("invalid seat")
+	ErrAlreadySeated = errors.New("player is already seated")
+	ErrNameTaken     = errors.New("name is already taken at this table")
+	ErrNotSeated     = errors.New("player is not seated")
+	ErrHandRunning   = errors.New("a hand is already in progress")
+	ErrNoHand        = errors.New("no hand in progress")
+	ErrNotYourTurn   = errors.New("it is not this player's turn")
+	ErrNotEnough     = errors.New("at least two players with chips are needed")
+)
+
+// TableConfig holds the stakes and rules of a table
+type TableConfig struct {
+	MaxSeats   int
+	SmallBlind int
+	BigBlind   int
+	Structure  BettingStructure
+	Rand       *rand.Rand // Deck shuffling source, seeded from the clock when nil
+}
+
+// Player is a player sitting at a table
+type Player struct {
+	ID        string
+	Name      string
+	Seat      int
+	Stack     int
+	Hole      []string
+	Bet       int // Chips bet on the current street
+	Committed int // Chips put in the pot during the current hand
+	InHand    bool
+	Folded    bool
+	AllIn     bool
+	Acted     bool // Has acted since the last full raise
+	Shown     bool
+	Leaving   bool
+}
+
+// Table runs hands of Texas Hold'em between the seated players. It reports
+// everything that happens through the listeners registered with Subscribe.
+// A Table is not safe for concurrent use.
+type Table struct {
+	ID  string
+	cfg TableConfig
+	rng *rand.Rand
+
+	seats      []*Player
+	button     int
+	handNumber int
+	inHand     bool
+	street     Street
+	board      []string
+	deck       []string
+	toAct      int
+	currentBet int
+	lastRaise  int
+	raises     int
+
+	listeners []func(Event)
+}
+
+// NewTable creates an empty table
+func NewTable(id string, cfg TableConfig) *Table {
+	if cfg.MaxSeats < 2 || cfg.MaxSeats > 10 {
+		cfg.MaxSeats = 9
+	}
+	if cfg.Structure == nil {
+		cfg.Structure = NoLimit{}
+	}
+	rng := cfg.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &Table{
+		ID:     id,
+		cfg:    cfg,
+		rng:    rng,
+		seats:  make([]*Player, cfg.MaxSeats),
+		button: -1,
+		toAct:  -1,
+	}
+}
+
+// Config returns the table configuration
+func (t *Table) Config() TableConfig {
+	return t.cfg
+}
+
+// Subscribe registers a listener that receives every event, including
+// private ones
+func (t *Table) Subscribe(fn func(Event)) {
+	t.listeners = append(t.listeners, fn)
+}
+
+func (t *Table) emit(e Event) {
+	e.Hand = t.handNumber
+	for _, fn := range t.listeners {
+		fn(e)
+	}
+}
+
+// Sit places a player in a free seat with the given stack. Names are unique
+// at a table, because its hand histories refer to players by name alone.
+func (t *Table) Sit(id, name string, seat, stack int) error {
+	if seat < 0 || seat >= len(t.seats) {
+		return ErrInvalidSeat
+	}
+	if t.seats[seat] != nil {
+		return ErrSeatTaken
+	}
+	if t.Player(id) != nil {
+		return ErrAlreadySeated
+	}
+	for _, p := range t.seats {
+		if p != nil && p.Name == name {
+			return ErrNameTaken
+		}
+	}
+	if stack <= 0 {
+		return fmt.Errorf("stack must be positive, got %d", stack)
+	}
+
+	t.seats[seat] = &Player{ID: id, Name: name, Seat: seat, Stack: stack}
+	t.emit(Event{Type: EventSeat, Seat: seat, Name: name, Amount: stack})
+	return nil
+}
+
+// Leave removes a player from the table. A player leaving during a hand is
+// folded and keeps the seat until the hand is over.
+func (t *Table) Leave(id string) error {
+	p := t.Player(id)
+	if p == nil {
+		return ErrNotSeated
+	}
+
+	if t.inHand && p.InHand {
+		p.Leaving = true
+		if t.toAct == p.Seat {
+			t.foldOut(p)
+		}
+		return nil
+	}
+
+	t.seats[p.Seat] = nil
+	t.emit(Event{Type: EventLeave, Seat: p.Seat, Name: p.Name})
+	return nil
+}
+
+// AddChips tops up a seated player's stack between hands
+func (t *Table) AddChips(id string, amount int) error {
+	p := t.Player(id)
+	if p == nil {
+		return ErrNotSeated
+	}
+	if t.inHand && p.InHand {
+		return ErrHandRunning
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive, got %d", amount)
+	}
+	p.Stack += amount
+	return nil
+}
+
+// Player returns the seated player with the given ID, or nil
+func (t *Table) Player(id string) *Player {
+	for _, p := range t.seats {
+		if p != nil && p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// InHand reports whether a hand is being played
+func (t *Table) InHand() bool {
+	return t.inHand
+}
+
+// HandNumber returns the number of the current or last hand
+func (t *Table) HandNumber() int {
+	return t.handNumber
+}
+
+// SetHandNumber sets the number of the last hand played, so that a table
+// restored from storage carries on numbering where it left off
+func (t *Table) SetHandNumber(n int) {
+	t.handNumber = n
+}
+
+// ToAct returns the player whose turn it is, or nil
+func (t *Table) ToAct() *Player {
+	if !t.inHand || t.toAct < 0 {
+		return nil
+	}
+	return t.seats[t.toAct]
+}
+
+// CanStart reports whether enough players have chips to deal a hand
+func (t *Table) CanStart() bool {
+	n := 0
+	for _, p := range t.seats {
+		if p != nil && p.Stack > 0 && !p.Leaving {
+			n++
+		}
+	}
+	return n >= 2
+}
+
+// StartHand moves the button, posts the blinds and deals hole cards
+func (t *Table) StartHand() error {
+	if t.inHand {
+		return ErrHandRunning
+	}
+	if !t.CanStart() {
+		return ErrNotEnough
+	}
+
+	t.handNumber++
+	t.inHand = true
+	t.street = Preflop
+	t.board = nil
+	t.currentBet = 0
+	t.lastRaise = 0
+	t.raises = 0
+
+	for _, p := range t.seats {
+		if p == nil {
+			continue
+		}
+		*p = Player{ID: p.ID, Name: p.Name, Seat: p.Seat, Stack: p.Stack, InHand: p.Stack > 0}
+	}
+
+	t.button = t.nextSeat(t.button, isDealt)
+	t.shuffle()
+
+	t.emit(Event{Type: EventHandStart, Seat: t.button, Seats: t.seatStates(-1), Time: time.Now()})
+
+	sb := t.nextSeat(t.button, isDealt)
+	if t.dealtCount() == 2 {
+		sb = t.button
+	}
+	bb := t.nextSeat(sb, isDealt)
+
+	t.postBlind(t.seats[sb], t.cfg.SmallBlind, EventSmallBlind)
+	t.postBlind(t.seats[bb], t.cfg.BigBlind, EventBigBlind)
+	t.currentBet = t.seats[bb].Bet
+	if t.seats[sb].Bet > t.currentBet {
+		t.currentBet = t.seats[sb].Bet
+	}
+	t.lastRaise = t.cfg.BigBlind
+	t.raises = 1
+
+	for i := 0; i < len(t.seats); i++ {
+		p := t.seats[(t.button+1+i)%len(t.seats)]
+		if p == nil || !p.InHand {
+			continue
+		}
+		p.Hole = []string{t.draw(), t.draw()}
+		t.emit(Event{Type: EventHoleCards, Seat: p.Seat, Name: p.Name, Cards: p.Hole, Private: true})
+	}
+
+	t.toAct = bb
+	if t.roundComplete() {
+		t.nextStreet()
+		return nil
+	}
+	t.toAct = t.nextSeat(bb, canAct)
+	t.foldLeaving()
+	return nil
+}
+
+func (t *Table) postBlind(p *Player, amount int, typ EventType) {
+	t.commit(p, amount)
+	t.emit(Event{Type: typ, Seat: p.Seat, Name: p.Name, Amount: p.Bet, AllIn: p.AllIn})
+}
+
+// Options returns the legal actions for a player whose turn it is
+func (t *Table) Options(id string) (ActionOptions, error) {
+	p := t.Player(id)
+	if p == nil {
+		return ActionOptions{}, ErrNotSeated
+	}
+	if !t.inHand {
+		return ActionOptions{}, ErrNoHand
+	}
+	if t.toAct != p.Seat {
+		return ActionOptions{}, ErrNotYourTurn
+	}
+	return t.options(p), nil
+}
+
+func (t *Table) options(p *Player) ActionOptions {
+	opts := t.cfg.Structure.Options(BettingState{
+		Street:        t.street,
+		Pot:           t.pot(),
+		CurrentBet:    t.currentBet,
+		PlayerBet:     p.Bet,
+		Stack:         p.Stack,
+		LastRaise:     t.lastRaise,
+		BigBlind:      t.cfg.BigBlind,
+		Raises:        t.raises,
+		ActivePlayers: t.liveCount(),
+	})
+
+	// An all-in for less than a full raise does not reopen the betting
+	// for players who have already acted, and there is nobody left to
+	// raise when everyone else is all-in
+	opponents := 0
+	for _, other := range t.seats {
+		if other != p && canAct(other) {
+			opponents++
+		}
+	}
+	if p.Acted || opponents == 0 {
+		var actions []ActionType
+		for _, a := range opts.Actions {
+			if a != Bet && a != Raise {
+				actions = append(actions, a)
+			}
+		}
+		opts.Actions = actions
+		opts.MinAmount, opts.MaxAmount = 0, 0
+	}
+	return opts
+}
+
+// TimeoutAction is the action taken for a player who runs out of time:
+// a check when it is free, otherwise a fold
+func (t *Table) TimeoutAction(id string) Action {
+	opts, err := t.Options(id)
+	if err == nil && opts.Allows(Check) {
+		return Action{Type: Check}
+	}
+	return Action{Type: Fold}
+}
+
+// Act applies a player's action and advances the hand
+func (t *Table) Act(id string, a Action) error {
+	if !t.inHand {
+		return ErrNoHand
+	}
+	p := t.Player(id)
+	if p == nil {
+		return ErrNotSeated
+	}
+	if t.toAct != p.Seat {
+		return ErrNotYourTurn
+	}
+
+	opts := t.options(p)
+	if err := opts.Validate(a); err != nil {
+		return err
+	}
+
+	if a.Type == Fold {
+		t.foldOut(p)
+		return nil
+	}
+
+	event := Event{Type: EventAction, Seat: p.Seat, Name: p.Name, Street: t.street, Action: a.Type}
+	switch a.Type {
+	case Call:
+		t.commit(p, opts.CallAmount)
+		event.Amount = opts.CallAmount
+	case Bet, Raise:
+		increment := t.lastRaise
+		if increment < t.cfg.BigBlind {
+			increment = t.cfg.BigBlind
+		}
+		raiseSize := a.Amount - t.currentBet
+		t.commit(p, a.Amount-p.Bet)
+		if raiseSize >= increment {
+			t.lastRaise = raiseSize
+			for _, other := range t.seats {
+				if other != nil {
+					other.Acted = false
+				}
+			}
+		}
+		t.currentBet = a.Amount
+		t.raises++
+		event.Amount = a.Amount
+	}
+	p.Acted = true
+	event.AllIn = p.AllIn
+	t.emit(event)
+
+	t.advance()
+	return nil
+}
+
+func (t *Table) commit(p *Player, amount int) {
+	if amount >= p.Stack {
+		amount = p.Stack
+		p.AllIn = true
+	}
+	p.Stack -= amount
+	p.Bet += amount
+	p.Committed += amount
+}
+
+func (t *Table) advance() {
+	if t.liveCount() == 1 {
+		t.finishUncontested()
+		return
+	}
+	if t.roundComplete() {
+		t.nextStreet()
+		return
+	}
+	t.toAct = t.nextSeat(t.toAct, canAct)
+	t.foldLeaving()
+}
+
+// foldOut folds the player whose turn it is and advances the hand. A player
+// leaving the table is folded this way even when a check is free.
+func (t *Table) foldOut(p *Player) {
+	p.Folded = true
+	p.Acted = true
+	t.emit(Event{Type: EventAction, Seat: p.Seat, Name: p.Name, Street: t.street, Action: Fold})
+	t.advance()
+}
+
+// foldLeaving folds a player who left the table as soon as it is their turn
+func (t *Table) foldLeaving() {
+	if p := t.ToAct(); p != nil && p.Leaving {
+		t.foldOut(p)
+	}
+}
+
+func (t *Table) roundComplete() bool {
+	var active []*Player
+	for _, p := range t.seats {
+		if canAct(p) {
+			active = append(active, p)
+		}
+	}
+	if len(active) == 0 {
+		return true
+	}
+	if len(active) == 1 && active[0].Bet >= t.currentBet {
+		return true
+	}
+	for _, p := range active {
+		if !p.Acted || p.Bet != t.currentBet {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Table) nextStreet() {
+	t.returnUncalled()
+	for _, p := range t.seats {
+		if p != nil {
+			p.Bet = 0
+			p.Acted = false
+		}
+	}
+	t.currentBet = 0
+	t.lastRaise = 0
+	t.raises = 0
+
+	if t.street == River {
+		t.showdown()
+		return
+	}
+
+	t.street++
+	t.draw() // Burn
+	n := 1
+	if t.street == Flop {
+		n = 3
+	}
+	var cards []string
+	for i := 0; i < n; i++ {
+		cards = append(cards, t.draw())
+	}
+	t.board = append(t.board, cards...)
+	t.emit(Event{Type: EventStreet, Seat: -1, Street: t.street, Cards: cards, Board: append([]string(nil), t.board...)})
+
+	actors := 0
+	for _, p := range t.seats {
+		if canAct(p) {
+			actors++
+		}
+	}
+	if actors < 2 {
+		t.nextStreet()
+		return
+	}
+	t.toAct = t.nextSeat(t.button, canAct)
+	t.foldLeaving()
+}
+
+// returnUncalled gives back the part of a bet that nobody matched
+func (t *Table) returnUncalled() {
+	var top *Player
+	second := 0
+	for _, p := range t.seats {
+		if p == nil || !p.InHand {
+			continue
+		}
+		if top == nil || p.Committed > top.Committed {
+			if top != nil {
+				second = top.Committed
+			}
+			top = p
+		} else if p.Committed > second {
+			second = p.Committed
+		}
+	}
+	if top == nil || top.Committed <= second {
+		return
+	}
+
+	excess := top.Committed - second
+	top.Committed -= excess
+	top.Stack += excess
+	if top.AllIn && top.Stack > 0 {
+		top.AllIn = false
+	}
+	t.emit(Event{Type: EventUncalled, Seat: top.Seat, Name: top.Name, Street: t.street, Amount: excess})
+}
+
+func (t *Table) finishUncontested() {
+	t.returnUncalled()
+
+	var winner *Player
+	for _, p := range t.seats {
+		if isLive(p) {
+			winner = p
+		}
+	}
+	pot := t.pot()
+	winner.Stack += pot
+	t.emit(Event{Type: EventPotAwarded, Seat: winner.Seat, Name: winner.Name, Street: t.street, Amount: pot})
+	t.endHand()
+}
+
+func (t *Table) showdown() {
+	t.returnUncalled()
+	t.street = Showdown
+
+	// Players in position order, starting left of the button
+	var order []*Player
+	for i := 1; i <= len(t.seats); i++ {
+		p := t.seats[(t.button+i)%len(t.seats)]
+		if p != nil && p.InHand {
+			order = append(order, p)
+		}
+	}
+
+	potPlayers := make([]poker.PotPlayer, len(order))
+	for i, p := range order {
+		potPlayers[i] = poker.PotPlayer{Contribution: p.Committed, Folded: p.Folded}
+		if p.Folded {
+			continue
+		}
+		cards := append(append([]string(nil), p.Hole...), t.board...)
+		parsed, _ := poker.ParseCards(cards)
+		potPlayers[i].Score = poker.EvaluateBestHand(parsed)
+		_, desc, _ := poker.EvaluateHand(cards)
+		p.Shown = true
+		t.emit(Event{Type: EventShowdown, Seat: p.Seat, Name: p.Name, Street: Showdown, Cards: p.Hole, HandDescription: desc})
+	}
+
+	for potIndex, pot := range poker.BuildPots(potPlayers) {
+		for i, share := range poker.SplitPot(potPlayers, pot) {
+			if share == 0 {
+				continue
+			}
+			order[i].Stack += share
+			t.emit(Event{Type: EventPotAwarded, Seat: order[i].Seat, Name: order[i].Name, Street: Showdown, Amount: share, PotIndex: potIndex})
+		}
+	}
+	t.endHand()
+}
+
+func (t *Table) endHand() {
+	t.inHand = false
+	t.toAct = -1
+	for _, p := range t.seats {
+		if p != nil {
+			p.Bet = 0
+			p.Committed = 0
+		}
+	}
+	t.emit(Event{Type: EventHandEnd, Seat: -1, Street: t.street, Board: t.board, Seats: t.seatStates(-1)})
+
+	for i, p := range t.seats {
+		if p != nil && p.Leaving {
+			t.seats[i] = nil
+			t.emit(Event{Type: EventLeave, Seat: p.Seat, Name: p.Name})
+		}
+	}
+}
+
+// State returns the table as seen by the given player. Only that player's
+// hole cards are included, plus any hands shown down.
+func (t *Table) State(viewerID string) TableState {
+	viewer := t.Player(viewerID)
+	yourSeat := -1
+	if viewer != nil {
+		yourSeat = viewer.Seat
+	}
+
+	state := TableState{
+		TableID:    t.ID,
+		Structure:  t.cfg.Structure.Name(),
+		SmallBlind: t.cfg.SmallBlind,
+		BigBlind:   t.cfg.BigBlind,
+		Hand:       t.handNumber,
+		InHand:     t.inHand,
+		Street:     t.street,
+		Button:     t.button,
+		Board:      append([]string{}, t.board...),
+		Pot:        t.pot(),
+		CurrentBet: t.currentBet,
+		ToAct:      -1,
+		Seats:      t.seatStates(yourSeat),
+		YourSeat:   yourSeat,
+	}
+	if t.inHand {
+		state.ToAct = t.toAct
+		if viewer != nil && t.toAct == viewer.Seat {
+			opts := t.options(viewer)
+			state.Options = &opts
+		}
+	}
+	return state
+}
+
+func (t *Table) seatStates(viewerSeat int) []SeatState {
+	var states []SeatState
+	for _, p := range t.seats {
+		if p == nil {
+			continue
+		}
+		s := SeatState{
+			Seat:   p.Seat,
+			Name:   p.Name,
+			Stack:  p.Stack,
+			Bet:    p.Bet,
+			InHand: p.InHand,
+			Folded: p.Folded,
+			AllIn:  p.AllIn,
+		}
+		if p.Seat == viewerSeat || (p.Shown && !p.Folded) {
+			s.Cards = append([]string(nil), p.Hole...)
+		}
+		states = append(states, s)
+	}
+	return states
+}
+
+func (t *Table) pot() int {
+	total := 0
+	for _, p := range t.seats {
+		if p != nil {
+			total += p.Committed
+		}
+	}
+	return total
+}
+
+func (t *Table) liveCount() int {
+	n := 0
+	for _, p := range t.seats {
+		if isLive(p) {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *Table) dealtCount() int {
+	n := 0
+	for _, p := range t.seats {
+		if isDealt(p) {
+			n++
+		}
+	}
+	return n
+}
+
+// nextSeat returns the first seat after from whose player matches
+func (t *Table) nextSeat(from int, match func(*Player) bool) int {
+	for i := 1; i <= len(t.seats); i++ {
+		seat := (from + i + len(t.seats)) % len(t.seats)
+		if match(t.seats[seat]) {
+			return seat
+		}
+	}
+	return -1
+}
+
+func isDealt(p *Player) bool {
+	return p != nil && p.InHand
+}
+
+func isLive(p *Player) bool {
+	return p != nil && p.InHand && !p.Folded
+}
+
+func canAct(p *Player) bool {
+	return isLive(p) && !p.AllIn
+}
+
+func (t *Table) shuffle() {
+	suits := []string{"H", "D", "C", "S"}
+	ranks := []string{"2", "3", "4", "5", "6", "7", "8", "9", "T", "J", "Q", "K", "A"}
+
+	t.deck = t.deck[:0]
+	for _, s := range suits {
+		for _, r := range ranks {
+			t.deck = append(t.deck, s+r)
+		}
+	}
+	t.rng.Shuffle(len(t.deck), func(i, j int) {
+		t.deck[i], t.deck[j] = t.deck[j], t.deck[i]
+	})
+}
+
+func (t *Table) draw() string {
+	card := t.deck[len(t.deck)-1]
+	t.deck = t.deck[:len(t.deck)-1]
+	return card
+}
@@ -0,0 +1,257 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newTestTable(t *testing.T, seed int64, stacks ...int) (*Table, *[]Event) {
+	t.Helper()
+	table := NewTable("test", TableConfig{
+		MaxSeats:   6,
+		SmallBlind: 1,
+		BigBlind:   2,
+		Rand:       rand.New(rand.NewSource(seed)),
+	})
+	events := &[]Event{}
+	table.Subscribe(func(e Event) { *events = append(*events, e) })
+
+	for i, stack := range stacks {
+		if err := table.Sit(string(rune('a'+i)), string(rune('A'+i)), i, stack); err != nil {
+			t.Fatalf("Failed to seat player %d: %v", i, err)
+		}
+	}
+	return table, events
+}
+
+func totalChips(table *Table) int {
+	total := table.pot()
+	for _, p := range table.seats {
+		if p != nil {
+			total += p.Stack
+		}
+	}
+	return total
+}
+
+func TestHeadsUpBlindsAndFold(t *testing.T) {
+	table, events := newTestTable(t, 1, 100, 100)
+
+	if err := table.StartHand(); err != nil {
+		t.Fatalf("Failed to start hand: %v", err)
+	}
+
+	// Heads-up the button posts the small blind and acts first preflop
+	if table.button != 0 {
+		t.Fatalf("Expected button on seat 0, got %d", table.button)
+	}
+	if table.seats[0].Bet != 1 || table.seats[1].Bet != 2 {
+		t.Errorf("Expected blinds 1/2, got %d/%d", table.seats[0].Bet, table.seats[1].Bet)
+	}
+	if p := table.ToAct(); p == nil || p.Seat != 0 {
+		t.Fatalf("Expected seat 0 to act first")
+	}
+
+	if err := table.Act("b", Action{Type: Call}); err != ErrNotYourTurn {
+		t.Errorf("Expected ErrNotYourTurn, got %v", err)
+	}
+	if err := table.Act("a", Action{Type: Fold}); err != nil {
+		t.Fatalf("Failed to fold: %v", err)
+	}
+
+	if table.InHand() {
+		t.Fatalf("Expected hand to be over")
+	}
+	if table.seats[0].Stack != 99 || table.seats[1].Stack != 101 {
+		t.Errorf("Expected stacks 99/101, got %d/%d", table.seats[0].Stack, table.seats[1].Stack)
+	}
+
+	var uncalled, awarded int
+	for _, e := range *events {
+		switch e.Type {
+		case EventUncalled:
+			uncalled = e.Amount
+		case EventPotAwarded:
+			awarded = e.Amount
+		}
+	}
+	if uncalled != 1 || awarded != 2 {
+		t.Errorf("Expected 1 uncalled and 2 awarded, got %d and %d", uncalled, awarded)
+	}
+}
+
+func TestCheckDownToShowdown(t *testing.T) {
+	table, events := newTestTable(t, 2, 100, 100, 100)
+	table.StartHand()
+
+	for table.InHand() {
+		p := table.ToAct()
+		action := table.TimeoutAction(p.ID)
+		if action.Type == Fold {
+			action = Action{Type: Call}
+		}
+		if err := table.Act(p.ID, action); err != nil {
+			t.Fatalf("Failed to act: %v", err)
+		}
+	}
+
+	if len(table.board) != 5 {
+		t.Errorf("Expected a full board, got %v", table.board)
+	}
+	showdowns := 0
+	for _, e := range *events {
+		if e.Type == EventShowdown {
+			showdowns++
+		}
+	}
+	if showdowns != 3 {
+		t.Errorf("Expected 3 hands shown, got %d", showdowns)
+	}
+	if totalChips(table) != 300 {
+		t.Errorf("Expected 300 chips in play, got %d", totalChips(table))
+	}
+}
+
+func TestStateHidesOtherHoleCards(t *testing.T) {
+	table, _ := newTestTable(t, 3, 100, 100, 100)
+	table.StartHand()
+
+	state := table.State("b")
+	if state.YourSeat != 1 {
+		t.Fatalf("Expected viewer seat 1, got %d", state.YourSeat)
+	}
+	for _, s := range state.Seats {
+		if s.Seat == 1 && len(s.Cards) != 2 {
+			t.Errorf("Expected own hole cards, got %v", s.Cards)
+		}
+		if s.Seat != 1 && len(s.Cards) != 0 {
+			t.Errorf("Seat %d cards leaked to seat 1: %v", s.Seat, s.Cards)
+		}
+	}
+
+	observer := table.State("")
+	for _, s := range observer.Seats {
+		if len(s.Cards) != 0 {
+			t.Errorf("Seat %d cards leaked to observer: %v", s.Seat, s.Cards)
+		}
+	}
+
+	toAct := table.ToAct()
+	if s := table.State(toAct.ID); s.Options == nil {
+		t.Errorf("Expected options for the player to act")
+	}
+}
+
+func TestShortAllInDoesNotReopenBetting(t *testing.T) {
+	table, _ := newTestTable(t, 4, 200, 200, 15)
+	table.StartHand()
+
+	// Button is seat 0, blinds on seats 1 and 2, seat 0 acts first
+	if err := table.Act("a", Action{Type: Raise, Amount: 10}); err != nil {
+		t.Fatalf("Failed to raise: %v", err)
+	}
+	if err := table.Act("b", Action{Type: Call}); err != nil {
+		t.Fatalf("Failed to call: %v", err)
+	}
+	// Big blind shoves 15, a raise of only 5
+	if err := table.Act("c", Action{Type: Raise, Amount: 15}); err != nil {
+		t.Fatalf("Failed to shove: %v", err)
+	}
+
+	opts, err := table.Options("a")
+	if err != nil {
+		t.Fatalf("Failed to get options: %v", err)
+	}
+	if opts.Allows(Raise) {
+		t.Errorf("Expected no raise option after an incomplete raise, got %+v", opts)
+	}
+	if opts.CallAmount != 5 {
+		t.Errorf("Expected to call 5, got %d", opts.CallAmount)
+	}
+}
+
+func TestRandomHandsConserveChips(t *testing.T) {
+	structures := []BettingStructure{NoLimit{}, PotLimit{}, FixedLimit{SmallBet: 2, BigBet: 4}}
+	rng := rand.New(rand.NewSource(5))
+
+	for _, structure := range structures {
+		t.Run(structure.Name(), func(t *testing.T) {
+			table, _ := newTestTable(t, 6, 50, 120, 80, 200, 30)
+			table.cfg.Structure = structure
+
+			for hand := 0; hand < 200 && table.CanStart(); hand++ {
+				if err := table.StartHand(); err != nil {
+					t.Fatalf("Failed to start hand: %v", err)
+				}
+				for table.InHand() {
+					p := table.ToAct()
+					opts := table.options(p)
+					action := Action{Type: opts.Actions[rng.Intn(len(opts.Actions))]}
+					if action.Type == Bet || action.Type == Raise {
+						action.Amount = opts.MinAmount + rng.Intn(opts.MaxAmount-opts.MinAmount+1)
+					}
+					if err := table.Act(p.ID, action); err != nil {
+						t.Fatalf("Legal action %+v rejected: %v", action, err)
+					}
+				}
+				if total := totalChips(table); total != 480 {
+					t.Fatalf("Hand %d: expected 480 chips in play, got %d", hand, total)
+				}
+			}
+		})
+	}
+}
+
+func TestLeaveDuringHand(t *testing.T) {
+	table, events := newTestTable(t, 7, 100, 100, 100)
+	table.StartHand()
+
+	if err := table.Leave("c"); err != nil {
+		t.Fatalf("Failed to leave: %v", err)
+	}
+	for table.InHand() {
+		p := table.ToAct()
+		table.Act(p.ID, table.TimeoutAction(p.ID))
+	}
+
+	if table.Player("c") != nil {
+		t.Errorf("Expected player to be removed after the hand")
+	}
+	last := (*events)[len(*events)-1]
+	if last.Type != EventLeave || last.Seat != 2 {
+		t.Errorf("Expected a leave event for seat 2, got %+v", last)
+	}
+}
+
+func TestLeaveWhenCheckIsFree(t *testing.T) {
+	table, _ := newTestTable(t, 8, 100, 100, 100)
+	table.StartHand()
+	table.Act("a", Action{Type: Call})
+	table.Act("b", Action{Type: Call})
+
+	// The big blind may check, and leaves instead
+	if opts, _ := table.Options("c"); opts.Allows(Fold) {
+		t.Fatalf("Expected a free check, got %v", opts.Actions)
+	}
+	if err := table.Leave("c"); err != nil {
+		t.Fatalf("Failed to leave: %v", err)
+	}
+	if !table.Player("c").Folded || table.street != Flop {
+		t.Fatalf("Expected the big blind folded and the flop dealt, got street %v", table.street)
+	}
+
+	// A player who left before their turn is folded when it comes, checked
+	// to or not
+	if err := table.Leave("a"); err != nil {
+		t.Fatalf("Failed to leave: %v", err)
+	}
+	if err := table.Act("b", Action{Type: Check}); err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	if table.InHand() {
+		t.Fatalf("Expected the hand to be over, %s to act", table.ToAct().Name)
+	}
+	if table.Player("a") != nil || table.Player("c") != nil || table.Player("b").Stack != 104 {
+		t.Errorf("Expected b alone with 104 chips, got %+v", table.seatStates(-1))
+	}
+}
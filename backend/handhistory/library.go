@@ -0,0 +1,61 @@
+package handhistory
+
+import "sync"
+
+// Library keeps imported hands in memory, keyed by site and hand ID. It is
+// safe for concurrent use.
+type Library struct {
+	mu    sync.RWMutex
+	hands map[string]*HandHistory
+	order []string
+}
+
+// NewLibrary creates an empty library
+func NewLibrary() *Library {
+	return &Library{hands: make(map[string]*HandHistory)}
+}
+
+func libraryKey(site, handID string) string {
+	return site + "#" + handID
+}
+
+// Add stores a hand and reports whether it was new. Hands already in the
+// library are replaced.
+func (l *Library) Add(hh *HandHistory) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := libraryKey(hh.Site, hh.HandID)
+	_, exists := l.hands[key]
+	if !exists {
+		l.order = append(l.order, key)
+	}
+	l.hands[key] = hh
+	return !exists
+}
+
+// Get looks up a hand by site and hand ID
+func (l *Library) Get(site, handID string) (*HandHistory, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	hh, ok := l.hands[libraryKey(site, handID)]
+	return hh, ok
+}
+
+// All returns every hand in the order it was first added
+func (l *Library) All() []*HandHistory {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]*HandHistory, len(l.order))
+	for i, key := range l.order {
+		out[i] = l.hands[key]
+	}
+	return out
+}
+
+// Len returns the number of hands in the library
+func (l *Library) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.order)
+}
@@ -0,0 +1,131 @@
+package handhistory
+
+import (
+	"time"
+
+	"texas-holdem-backend/game"
+)
+
+// HandHistory is one complete hand as recorded by a game engine or read from
+// a hand history file. Amounts are in the smallest unit of the currency
+// (cents) for cash games and in chips otherwise. Cards use the backend's
+// suit-first notation, e.g. "HA".
+type HandHistory struct {
+	HandID     string
+	Site       string
+	Tournament *Tournament
+	Game       string // "Hold'em"
+	Limit      string // "No Limit", "Pot Limit" or "Limit"
+	Currency   string // ISO code such as "USD"; empty for play chips
+	SmallBlind int64
+	BigBlind   int64
+	Ante       int64
+	Time       time.Time
+	TableName  string
+	MaxSeats   int
+	ButtonSeat int
+	Seats      []Seat
+	Hero       string // Player whose hole cards were dealt face up to the viewer
+	Actions    []Action
+	Board      []string
+	TotalPot   int64
+	Rake       int64
+}
+
+// Tournament identifies the tournament a hand was played in
+type Tournament struct {
+	ID    string
+	BuyIn string // As printed, e.g. "$10+$1 USD"
+	Level string // Roman numeral level, e.g. "IV"
+}
+
+// Seat is a player at the table when the hand started. Seat numbers start
+// at 1.
+type Seat struct {
+	Number     int
+	Name       string
+	Stack      int64
+	HoleCards  []string
+	SittingOut bool
+}
+
+// ActionType is anything a player does or is credited with during a hand
+type ActionType string
+
+const (
+	PostSmallBlind ActionType = "posts small blind"
+	PostBigBlind   ActionType = "posts big blind"
+	PostAnte       ActionType = "posts the ante"
+	Fold           ActionType = "folds"
+	Check          ActionType = "checks"
+	Call           ActionType = "calls"
+	Bet            ActionType = "bets"
+	Raise          ActionType = "raises"
+	Uncalled       ActionType = "uncalled"
+	Show           ActionType = "shows"
+	Muck           ActionType = "mucks"
+	Collect        ActionType = "collected"
+)
+
+// Action is one line of play. For raises Amount is the increase over the
+// previous bet and To is the new total; for every other type Amount is the
+// number of chips moved.
+type Action struct {
+	Street      game.Street
+	Player      string
+	Type        ActionType
+	Amount      int64
+	To          int64
+	AllIn       bool
+	Cards       []string // Cards shown
+	Description string   // Shown hand, e.g. "Pair of Kings"
+	Pot         int      // Pot collected from: 0 is the main pot, 1 the first side pot
+}
+
+// SeatOf returns the seat of the named player, or nil
+func (hh *HandHistory) SeatOf(name string) *Seat {
+	for i := range hh.Seats {
+		if hh.Seats[i].Name == name {
+			return &hh.Seats[i]
+		}
+	}
+	return nil
+}
+
+// Redact returns a copy of the hand as the given player saw it: every hole
+// card is removed except the hero's own, which the hand is then written
+// from. Cards shown at showdown stay in the Show actions. An empty hero
+// keeps no hole cards at all.
+func (hh *HandHistory) Redact(hero string) *HandHistory {
+	view := *hh
+	view.Hero = hero
+	view.Seats = make([]Seat, len(hh.Seats))
+	for i, s := range hh.Seats {
+		if s.Name != hero {
+			s.HoleCards = nil
+		}
+		view.Seats[i] = s
+	}
+	return &view
+}
+
+// PlayerID identifies a player across hands. A screen name is unique at
+// its site, but anyone can sit at the engine's tables under any name not
+// taken at that table, so there a name only identifies a player at one
+// table: "PokerStars:Hero", "@main:Alice".
+func (hh *HandHistory) PlayerID(name string) string {
+	if hh.Site == "" {
+		return "@" + hh.TableName + ":" + name
+	}
+	return hh.Site + ":" + name
+}
+
+// IsVoluntary reports whether an action put chips in or passed on doing so
+// by the player's own choice
+func (a Action) IsVoluntary() bool {
+	switch a.Type {
+	case Fold, Check, Call, Bet, Raise:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,608 @@
+package handhistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/poker"
+)
+
+// OHHSpecVersion is the Open Hand History specification version written
+const OHHSpecVersion = "1.4.7"
+
+// Currency codes the Open Hand History format uses for chips
+const (
+	ohhTournamentChips = "T$"
+	ohhPlayMoney       = "PM"
+)
+
+// OHHFile is the top-level object of an Open Hand History document
+type OHHFile struct {
+	OHH OHH `json:"ohh"`
+}
+
+// OHH is one hand in the Open Hand History format. Amounts are decimal
+// currency units (or chips).
+type OHH struct {
+	SpecVersion      string             `json:"spec_version"`
+	SiteName         string             `json:"site_name"`
+	NetworkName      string             `json:"network_name"`
+	InternalVersion  string             `json:"internal_version"`
+	Tournament       bool               `json:"tournament"`
+	TournamentInfo   *OHHTournamentInfo `json:"tournament_info,omitempty"`
+	GameNumber       string             `json:"game_number"`
+	StartDateUTC     string             `json:"start_date_utc"`
+	TableName        string             `json:"table_name"`
+	TableSize        int                `json:"table_size"`
+	GameType         string             `json:"game_type"`
+	BetLimit         OHHBetLimit        `json:"bet_limit"`
+	Currency         string             `json:"currency"`
+	DealerSeat       int                `json:"dealer_seat"`
+	SmallBlindAmount float64            `json:"small_blind_amount"`
+	BigBlindAmount   float64            `json:"big_blind_amount"`
+	AnteAmount       float64            `json:"ante_amount"`
+	HeroPlayerID     int                `json:"hero_player_id,omitempty"`
+	Flags            []string           `json:"flags"`
+	Players          []OHHPlayer        `json:"players"`
+	Rounds           []OHHRound         `json:"rounds"`
+	Pots             []OHHPot           `json:"pots"`
+}
+
+// OHHTournamentInfo describes the tournament a hand was played in
+type OHHTournamentInfo struct {
+	TournamentNumber string  `json:"tournament_number"`
+	Name             string  `json:"name,omitempty"`
+	Currency         string  `json:"currency,omitempty"`
+	BuyinAmount      float64 `json:"buyin_amount"`
+	FeeAmount        float64 `json:"fee_amount"`
+}
+
+// OHHBetLimit is the betting structure; BetType is "NL", "PL" or "FL"
+type OHHBetLimit struct {
+	BetType string  `json:"bet_type"`
+	BetCap  float64 `json:"bet_cap"`
+}
+
+// OHHPlayer is a seated player
+type OHHPlayer struct {
+	ID            int     `json:"id"`
+	Seat          int     `json:"seat"`
+	Name          string  `json:"name"`
+	Display       string  `json:"display,omitempty"`
+	StartingStack float64 `json:"starting_stack"`
+	IsSittingOut  bool    `json:"is_sitting_out,omitempty"`
+}
+
+// OHHRound is one street and the actions taken on it
+type OHHRound struct {
+	ID      int         `json:"id"`
+	Street  string      `json:"street"`
+	Cards   []string    `json:"cards,omitempty"`
+	Actions []OHHAction `json:"actions"`
+}
+
+// OHHAction is a single action. For raises Amount is the total raised to.
+type OHHAction struct {
+	ActionNumber int      `json:"action_number"`
+	PlayerID     int      `json:"player_id"`
+	Action       string   `json:"action"`
+	Amount       float64  `json:"amount,omitempty"`
+	IsAllIn      bool     `json:"is_allin,omitempty"`
+	Cards        []string `json:"cards,omitempty"`
+}
+
+// OHHPot is a pot and the players who won it
+type OHHPot struct {
+	Number     int            `json:"number"`
+	Amount     float64        `json:"amount"`
+	Rake       float64        `json:"rake"`
+	Jackpot    float64        `json:"jackpot"`
+	PlayerWins []OHHPlayerWin `json:"player_wins"`
+}
+
+// OHHPlayerWin is a player's share of a pot
+type OHHPlayerWin struct {
+	PlayerID  int     `json:"player_id"`
+	WinAmount float64 `json:"win_amount"`
+}
+
+// Open Hand History action names
+const (
+	ohhDealtCards = "Dealt Cards"
+	ohhMucksCards = "Mucks Cards"
+	ohhShowsCards = "Shows Cards"
+	ohhPostAnte   = "Post Ante"
+	ohhPostSB     = "Post SB"
+	ohhPostBB     = "Post BB"
+	ohhFold       = "Fold"
+	ohhCheck      = "Check"
+	ohhBet        = "Bet"
+	ohhRaise      = "Raise"
+	ohhCall       = "Call"
+)
+
+var ohhActions = map[ActionType]string{
+	PostSmallBlind: ohhPostSB,
+	PostBigBlind:   ohhPostBB,
+	PostAnte:       ohhPostAnte,
+	Fold:           ohhFold,
+	Check:          ohhCheck,
+	Call:           ohhCall,
+	Bet:            ohhBet,
+	Raise:          ohhRaise,
+	Show:           ohhShowsCards,
+	Muck:           ohhMucksCards,
+}
+
+var ohhStreets = map[game.Street]string{
+	game.Preflop:  "Preflop",
+	game.Flop:     "Flop",
+	game.Turn:     "Turn",
+	game.River:    "River",
+	game.Showdown: "Showdown",
+}
+
+var ohhBetTypes = map[string]string{
+	"No Limit":  "NL",
+	"Pot Limit": "PL",
+	"Limit":     "FL",
+}
+
+var buyInRe = regexp.MustCompile(`^\D?([\d.]+)\+\D?([\d.]+)(?: ([A-Z]{3}))?$`)
+
+// ToOHH converts a hand to the Open Hand History format
+func ToOHH(hh *HandHistory) OHH {
+	site := hh.Site
+	if site == "" {
+		site = "PokerStars"
+	}
+	o := OHH{
+		SpecVersion:      OHHSpecVersion,
+		SiteName:         site,
+		NetworkName:      site,
+		InternalVersion:  OHHSpecVersion,
+		Tournament:       hh.Tournament != nil,
+		GameNumber:       hh.HandID,
+		StartDateUTC:     hh.Time.UTC().Format(time.RFC3339),
+		TableName:        hh.TableName,
+		TableSize:        hh.MaxSeats,
+		GameType:         "Holdem",
+		BetLimit:         OHHBetLimit{BetType: ohhBetTypes[hh.Limit]},
+		Currency:         hh.Currency,
+		DealerSeat:       hh.ButtonSeat,
+		SmallBlindAmount: hh.units(hh.SmallBlind),
+		BigBlindAmount:   hh.units(hh.BigBlind),
+		AnteAmount:       hh.units(hh.Ante),
+		Flags:            []string{},
+		Players:          []OHHPlayer{},
+		Rounds:           []OHHRound{},
+		Pots:             []OHHPot{},
+	}
+	if o.Currency == "" {
+		o.Currency = ohhPlayMoney
+		if hh.Tournament != nil {
+			o.Currency = ohhTournamentChips
+		}
+	}
+	if t := hh.Tournament; t != nil {
+		o.TournamentInfo = &OHHTournamentInfo{TournamentNumber: t.ID}
+		if m := buyInRe.FindStringSubmatch(t.BuyIn); m != nil {
+			o.TournamentInfo.BuyinAmount, _ = strconv.ParseFloat(m[1], 64)
+			o.TournamentInfo.FeeAmount, _ = strconv.ParseFloat(m[2], 64)
+			o.TournamentInfo.Currency = m[3]
+		}
+		if t.Level != "" {
+			o.TournamentInfo.Name = "Level " + t.Level
+		}
+	}
+
+	ids := make(map[string]int)
+	for i, s := range hh.Seats {
+		ids[s.Name] = i + 1
+		o.Players = append(o.Players, OHHPlayer{
+			ID:            i + 1,
+			Seat:          s.Number,
+			Name:          s.Name,
+			StartingStack: hh.units(s.Stack),
+			IsSittingOut:  s.SittingOut,
+		})
+	}
+	o.HeroPlayerID = ids[hh.Hero]
+
+	number := 0
+	round := func(street game.Street) *OHHRound {
+		if n := len(o.Rounds); n > 0 && o.Rounds[n-1].Street == ohhStreets[street] {
+			return &o.Rounds[n-1]
+		}
+		r := OHHRound{ID: len(o.Rounds), Street: ohhStreets[street], Actions: []OHHAction{}}
+		switch street {
+		case game.Flop:
+			r.Cards = siteCards(hh.Board, 0, 3)
+		case game.Turn:
+			r.Cards = siteCards(hh.Board, 3, 4)
+		case game.River:
+			r.Cards = siteCards(hh.Board, 4, 5)
+		}
+		o.Rounds = append(o.Rounds, r)
+		return &o.Rounds[len(o.Rounds)-1]
+	}
+	add := func(street game.Street, a OHHAction) {
+		r := round(street)
+		number++
+		a.ActionNumber = number
+		r.Actions = append(r.Actions, a)
+	}
+
+	// Blinds and antes are posted before the cards are dealt
+	i := 0
+	for ; i < len(hh.Actions); i++ {
+		a := hh.Actions[i]
+		if a.Type != PostSmallBlind && a.Type != PostBigBlind && a.Type != PostAnte {
+			break
+		}
+		add(game.Preflop, OHHAction{PlayerID: ids[a.Player], Action: ohhActions[a.Type], Amount: hh.units(a.Amount), IsAllIn: a.AllIn})
+	}
+	round(game.Preflop)
+	for _, s := range hh.Seats {
+		if len(s.HoleCards) == 0 || (hh.Hero != "" && s.Name != hh.Hero) {
+			continue
+		}
+		add(game.Preflop, OHHAction{PlayerID: ids[s.Name], Action: ohhDealtCards, Cards: siteCards(s.HoleCards, 0, len(s.HoleCards))})
+	}
+
+	street := game.Preflop
+	pots := make(map[int]*OHHPot)
+	var potOrder []int
+	for _, a := range hh.Actions[i:] {
+		// Streets dealt without action (everyone all-in) still get a round
+		for street < a.Street && street < game.Showdown {
+			street++
+			if street < game.Showdown && len(hh.Board) < boardSize(street) {
+				break
+			}
+			round(street)
+		}
+
+		switch a.Type {
+		case Uncalled:
+			// Implied by the difference between the bets and the pots
+		case Collect:
+			pot, ok := pots[a.Pot]
+			if !ok {
+				pot = &OHHPot{Number: a.Pot, PlayerWins: []OHHPlayerWin{}}
+				pots[a.Pot] = pot
+				potOrder = append(potOrder, a.Pot)
+			}
+			pot.Amount += hh.units(a.Amount)
+			pot.PlayerWins = append(pot.PlayerWins, OHHPlayerWin{PlayerID: ids[a.Player], WinAmount: hh.units(a.Amount)})
+		default:
+			oa := OHHAction{PlayerID: ids[a.Player], Action: ohhActions[a.Type], IsAllIn: a.AllIn}
+			switch a.Type {
+			case Raise:
+				oa.Amount = hh.units(a.To)
+			case Show, Muck:
+				oa.Cards = siteCards(a.Cards, 0, len(a.Cards))
+				if a.Type == Muck && len(oa.Cards) == 0 {
+					if s := hh.SeatOf(a.Player); s != nil {
+						oa.Cards = siteCards(s.HoleCards, 0, len(s.HoleCards))
+					}
+				}
+			default:
+				oa.Amount = hh.units(a.Amount)
+			}
+			add(a.Street, oa)
+		}
+	}
+	for street < game.River && len(hh.Board) >= boardSize(street+1) {
+		street++
+		round(street)
+	}
+
+	// Pots stay in the order they were awarded, side pots usually first
+	for _, n := range potOrder {
+		pot := pots[n]
+		if n == 0 {
+			pot.Rake = hh.units(hh.Rake)
+			pot.Amount += pot.Rake
+		}
+		o.Pots = append(o.Pots, *pot)
+	}
+	return o
+}
+
+func boardSize(street game.Street) int {
+	switch street {
+	case game.Flop:
+		return 3
+	case game.Turn:
+		return 4
+	case game.River:
+		return 5
+	}
+	return 0
+}
+
+func siteCards(cards []string, from, to int) []string {
+	if to > len(cards) {
+		return nil
+	}
+	out := make([]string, 0, to-from)
+	for _, c := range cards[from:to] {
+		out = append(out, ToSiteCard(c))
+	}
+	return out
+}
+
+// units converts an amount in the smallest unit to decimal currency units
+func (hh *HandHistory) units(v int64) float64 {
+	if hh.Currency == "" {
+		return float64(v)
+	}
+	return float64(v) / 100
+}
+
+// fromUnits converts a decimal amount back to the smallest unit
+func (hh *HandHistory) fromUnits(v float64) int64 {
+	if hh.Currency == "" {
+		return int64(math.Round(v))
+	}
+	return int64(math.Round(v * 100))
+}
+
+// FromOHH converts an Open Hand History hand to the internal model. The hand
+// should have been validated first.
+func FromOHH(o OHH) (*HandHistory, error) {
+	hh := &HandHistory{
+		HandID:    o.GameNumber,
+		Site:      o.SiteName,
+		Game:      "Hold'em",
+		TableName: o.TableName,
+		MaxSeats:  o.TableSize,
+	}
+	if _, ok := currencySymbols[o.Currency]; ok {
+		hh.Currency = o.Currency
+	}
+	for limit, betType := range ohhBetTypes {
+		if betType == o.BetLimit.BetType {
+			hh.Limit = limit
+		}
+	}
+	if o.Tournament {
+		hh.Tournament = &Tournament{}
+		if t := o.TournamentInfo; t != nil {
+			hh.Tournament.ID = t.TournamentNumber
+			hh.Tournament.Level = strings.TrimPrefix(t.Name, "Level ")
+			if t.BuyinAmount > 0 || t.FeeAmount > 0 {
+				symbol := currencySymbols[t.Currency]
+				hh.Tournament.BuyIn = fmt.Sprintf("%s%s+%s%s", symbol, decimal(t.BuyinAmount), symbol, decimal(t.FeeAmount))
+				if t.Currency != "" {
+					hh.Tournament.BuyIn += " " + t.Currency
+				}
+			}
+		}
+	}
+	t, err := time.Parse(time.RFC3339, o.StartDateUTC)
+	if err != nil {
+		return nil, fmt.Errorf("start_date_utc: %v", err)
+	}
+	hh.Time = t
+	hh.SmallBlind = hh.fromUnits(o.SmallBlindAmount)
+	hh.BigBlind = hh.fromUnits(o.BigBlindAmount)
+	hh.Ante = hh.fromUnits(o.AnteAmount)
+	hh.ButtonSeat = o.DealerSeat
+
+	names := make(map[int]string)
+	for _, p := range o.Players {
+		names[p.ID] = p.Name
+		hh.Seats = append(hh.Seats, Seat{Number: p.Seat, Name: p.Name, Stack: hh.fromUnits(p.StartingStack), SittingOut: p.IsSittingOut})
+	}
+	hh.Hero = names[o.HeroPlayerID]
+
+	showdown := false
+	for _, r := range o.Rounds {
+		street, ok := ohhStreet(r.Street)
+		if !ok {
+			return nil, fmt.Errorf("unknown street %q", r.Street)
+		}
+		if len(r.Cards) > 0 {
+			cards, err := fromSiteCards(r.Cards)
+			if err != nil {
+				return nil, err
+			}
+			hh.Board = append(hh.Board, cards...)
+		}
+
+		streetBet := make(map[string]int64)
+		var currentBet int64
+		for _, oa := range r.Actions {
+			name := names[oa.PlayerID]
+			a := Action{Street: street, Player: name, AllIn: oa.IsAllIn, Amount: hh.fromUnits(oa.Amount)}
+			cards, err := fromSiteCards(oa.Cards)
+			if err != nil {
+				return nil, err
+			}
+
+			switch oa.Action {
+			case ohhDealtCards:
+				if s := hh.SeatOf(name); s != nil {
+					s.HoleCards = cards
+				}
+				continue
+			case ohhPostSB, ohhPostBB, ohhPostAnte, ohhCall, ohhBet:
+				a.Type = map[string]ActionType{ohhPostSB: PostSmallBlind, ohhPostBB: PostBigBlind, ohhPostAnte: PostAnte, ohhCall: Call, ohhBet: Bet}[oa.Action]
+				if a.Type != PostAnte {
+					streetBet[name] += a.Amount
+				}
+			case ohhRaise:
+				a.Type = Raise
+				a.To = a.Amount
+				a.Amount = a.To - currentBet
+				streetBet[name] = a.To
+			case ohhFold:
+				a.Type = Fold
+			case ohhCheck:
+				a.Type = Check
+			case ohhShowsCards, ohhMucksCards:
+				a.Type, a.Amount = Show, 0
+				if oa.Action == ohhMucksCards {
+					a.Type = Muck
+				}
+				a.Cards = cards
+				if s := hh.SeatOf(name); s != nil && len(s.HoleCards) == 0 {
+					s.HoleCards = cards
+				}
+				showdown = true
+			default:
+				// Seating changes and other non-betting actions
+				continue
+			}
+			if streetBet[name] > currentBet {
+				currentBet = streetBet[name]
+			}
+			hh.Actions = append(hh.Actions, a)
+		}
+	}
+
+	// Show actions carry the hand description the text format prints
+	for i, a := range hh.Actions {
+		if a.Type == Show && len(hh.Board) == 5 {
+			_, hh.Actions[i].Description, _ = poker.EvaluateHand(append(append([]string(nil), a.Cards...), hh.Board...))
+		}
+	}
+
+	hh.addUncalled()
+
+	collectStreet := game.Showdown
+	if !showdown && len(hh.Actions) > 0 {
+		collectStreet = hh.Actions[len(hh.Actions)-1].Street
+	}
+	for _, pot := range o.Pots {
+		hh.TotalPot += hh.fromUnits(pot.Amount)
+		hh.Rake += hh.fromUnits(pot.Rake)
+		for _, w := range pot.PlayerWins {
+			hh.Actions = append(hh.Actions, Action{Street: collectStreet, Player: names[w.PlayerID], Type: Collect, Amount: hh.fromUnits(w.WinAmount), Pot: pot.Number})
+		}
+	}
+	return hh, nil
+}
+
+// addUncalled restores the uncalled bet the Open Hand History format leaves
+// implicit: whatever the biggest contributor put in beyond everyone else is
+// returned to them after the last betting action
+func (hh *HandHistory) addUncalled() {
+	committed := hh.Committed()
+	var top, second int64
+	topName := ""
+	for _, s := range hh.Seats {
+		c := committed[s.Name]
+		if c > top {
+			top, second, topName = c, top, s.Name
+		} else if c > second {
+			second = c
+		}
+	}
+	if topName == "" || top == second {
+		return
+	}
+
+	last := -1
+	for i, a := range hh.Actions {
+		if a.Type != Show && a.Type != Muck {
+			last = i
+		}
+	}
+	if last < 0 {
+		return
+	}
+	uncalled := Action{Street: hh.Actions[last].Street, Player: topName, Type: Uncalled, Amount: top - second}
+	hh.Actions = append(hh.Actions[:last+1], append([]Action{uncalled}, hh.Actions[last+1:]...)...)
+}
+
+func ohhStreet(name string) (game.Street, bool) {
+	for street, n := range ohhStreets {
+		if n == name {
+			return street, true
+		}
+	}
+	return 0, false
+}
+
+func fromSiteCards(cards []string) ([]string, error) {
+	var out []string
+	for _, c := range cards {
+		card, err := FromSiteCard(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, card)
+	}
+	return out, nil
+}
+
+func decimal(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// MarshalOHH encodes a hand as an Open Hand History document
+func MarshalOHH(hh *HandHistory) ([]byte, error) {
+	return json.Marshal(OHHFile{OHH: ToOHH(hh)})
+}
+
+// UnmarshalOHH validates and decodes an Open Hand History document
+func UnmarshalOHH(data []byte) (*HandHistory, error) {
+	if errs := ValidateOHH(data); len(errs) > 0 {
+		return nil, errs
+	}
+	var f OHHFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return FromOHH(f.OHH)
+}
+
+// WriteOHH writes hands as an .ohh file: one document per line, separated
+// by blank lines
+func WriteOHH(w io.Writer, hands []*HandHistory) error {
+	for _, hh := range hands {
+		data, err := MarshalOHH(hh)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(data, '\n', '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadOHH reads every document of an .ohh file. Documents that fail
+// validation are reported by their position in the file, starting at 1.
+func ReadOHH(r io.Reader) ([]*HandHistory, map[int]error, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var hands []*HandHistory
+	errs := make(map[int]error)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return hands, errs, err
+		}
+		hh, err := UnmarshalOHH(bytes.TrimSpace(raw))
+		if err != nil {
+			errs[n] = err
+			continue
+		}
+		hands = append(hands, hh)
+	}
+	return hands, errs, nil
+}
@@ -0,0 +1,151 @@
+package handhistory
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOHHRoundTrip(t *testing.T) {
+	hands, errs := ParseString(cashHand + "\n\n" + tournamentHand)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no parse errors, got %v", errs)
+	}
+	hands = append(hands, sampleHand())
+
+	for _, hh := range hands {
+		t.Run(hh.HandID, func(t *testing.T) {
+			data, err := MarshalOHH(hh)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if errs := ValidateOHH(data); len(errs) > 0 {
+				t.Fatalf("Expected a valid document, got %v", errs)
+			}
+
+			decoded, err := UnmarshalOHH(data)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if decoded.Site == "PokerStars" && hh.Site == "" {
+				decoded.Site = ""
+			}
+			// Hand descriptions are not part of the format and are regenerated
+			for _, h := range []*HandHistory{hh, decoded} {
+				for i := range h.Actions {
+					h.Actions[i].Description = ""
+				}
+			}
+			if got, expected := Format(decoded), Format(hh); got != expected {
+				t.Errorf("Round trip changed the hand:\n%s\nExpected:\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestToOHH(t *testing.T) {
+	hands, _ := ParseString(tournamentHand)
+	o := ToOHH(hands[0])
+
+	if !o.Tournament || o.TournamentInfo.TournamentNumber != "3512345678" || o.TournamentInfo.BuyinAmount != 10 || o.TournamentInfo.FeeAmount != 1 {
+		t.Errorf("Unexpected tournament info: %+v", o.TournamentInfo)
+	}
+	if o.Currency != "T$" || o.BetLimit.BetType != "NL" || o.StartDateUTC != "2023-03-06T01:00:00Z" {
+		t.Errorf("Unexpected header: %s %s %s", o.Currency, o.BetLimit.BetType, o.StartDateUTC)
+	}
+	if o.HeroPlayerID != 1 || o.DealerSeat != 2 || len(o.Players) != 3 {
+		t.Errorf("Unexpected players: hero %d, dealer seat %d, %d players", o.HeroPlayerID, o.DealerSeat, len(o.Players))
+	}
+
+	var streets []string
+	for _, r := range o.Rounds {
+		streets = append(streets, r.Street)
+	}
+	if got := strings.Join(streets, ","); got != "Preflop,Flop,Turn,River,Showdown" {
+		t.Errorf("Expected every street, got %s", got)
+	}
+	raise := o.Rounds[0].Actions[7]
+	if raise.Action != "Raise" || raise.Amount != 495 || !raise.IsAllIn {
+		t.Errorf("Expected Cid to raise to 495 all-in, got %+v", raise)
+	}
+	if len(o.Pots) != 2 || o.Pots[0].Number != 1 || o.Pots[0].Amount != 1950 || o.Pots[0].PlayerWins[0].PlayerID != 2 {
+		t.Errorf("Unexpected pots: %+v", o.Pots)
+	}
+}
+
+func TestValidateOHH(t *testing.T) {
+	hands, _ := ParseString(cashHand)
+	valid, _ := MarshalOHH(hands[0])
+
+	edit := func(fn func(doc map[string]interface{})) []byte {
+		var doc map[string]interface{}
+		json.Unmarshal(valid, &doc)
+		fn(doc["ohh"].(map[string]interface{}))
+		data, _ := json.Marshal(doc)
+		return data
+	}
+	round := func(doc map[string]interface{}, i int) map[string]interface{} {
+		return doc["rounds"].([]interface{})[i].(map[string]interface{})
+	}
+	action := func(doc map[string]interface{}, i, j int) map[string]interface{} {
+		return round(doc, i)["actions"].([]interface{})[j].(map[string]interface{})
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		path string
+	}{
+		{"Invalid JSON", []byte(`{"ohh": `), ""},
+		{"Missing field", edit(func(doc map[string]interface{}) { delete(doc, "game_number") }), "ohh.game_number"},
+		{"Wrong type", edit(func(doc map[string]interface{}) { doc["table_size"] = "six" }), "ohh.table_size"},
+		{"Not an integer", edit(func(doc map[string]interface{}) { doc["dealer_seat"] = 1.5 }), "ohh.dealer_seat"},
+		{"Negative amount", edit(func(doc map[string]interface{}) { action(doc, 0, 0)["amount"] = -1 }), "ohh.rounds[0].actions[0].amount"},
+		{"Unknown action", edit(func(doc map[string]interface{}) { action(doc, 1, 1)["action"] = "Jam" }), "ohh.rounds[1].actions[1].action"},
+		{"Unknown player", edit(func(doc map[string]interface{}) { action(doc, 2, 0)["player_id"] = 9 }), "ohh.rounds[2].actions[0].player_id"},
+		{"Bad card", edit(func(doc map[string]interface{}) { round(doc, 1)["cards"] = []string{"Qh", "7c", "1x"} }), "ohh.rounds[1].cards[2]"},
+		{"Short flop", edit(func(doc map[string]interface{}) { round(doc, 1)["cards"] = []string{"Qh", "7c"} }), "ohh.rounds[1].cards"},
+		{"Bad date", edit(func(doc map[string]interface{}) { doc["start_date_utc"] = "yesterday" }), "ohh.start_date_utc"},
+		{"Empty dealer seat", edit(func(doc map[string]interface{}) { doc["dealer_seat"] = 2 }), "ohh.dealer_seat"},
+		{"Overpaid pot", edit(func(doc map[string]interface{}) {
+			doc["pots"].([]interface{})[0].(map[string]interface{})["amount"] = 50
+		}), "ohh.pots[0].player_wins"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateOHH(tt.data)
+			if len(errs) != 1 {
+				t.Fatalf("Expected 1 error, got %v", errs)
+			}
+			if errs[0].Path != tt.path {
+				t.Errorf("Expected error at %q, got %v", tt.path, errs[0])
+			}
+			if _, err := UnmarshalOHH(tt.data); err == nil {
+				t.Errorf("Expected UnmarshalOHH to fail")
+			}
+		})
+	}
+}
+
+func TestReadWriteOHH(t *testing.T) {
+	hands, _ := ParseString(cashHand + "\n\n" + tournamentHand)
+
+	var buf bytes.Buffer
+	if err := WriteOHH(&buf, hands); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	buf.WriteString(`{"ohh": {"spec_version": "1.4.7"}}`)
+
+	read, errs, err := ReadOHH(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(read) != 3 {
+		t.Errorf("Expected 3 hands, got %d", len(read))
+	}
+	if len(errs) != 1 || errs[4] == nil {
+		t.Errorf("Expected an error for document 4, got %v", errs)
+	}
+}
@@ -0,0 +1,395 @@
+package handhistory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError is a problem with one field of an Open Hand History
+// document. Path locates the field, e.g. "ohh.rounds[1].actions[0].amount".
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors is every problem found in a document
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type jsonKind int
+
+const (
+	kindString jsonKind = iota
+	kindNumber
+	kindInteger
+	kindBoolean
+	kindObject
+	kindArray
+)
+
+func (k jsonKind) String() string {
+	return [...]string{"string", "number", "integer", "boolean", "object", "array"}[k]
+}
+
+// schema describes the expected shape of a JSON value
+type schema struct {
+	kind     jsonKind
+	fields   map[string]field // Objects
+	items    *schema          // Arrays
+	enum     []string         // Strings
+	minimum  *float64         // Numbers
+	minItems int              // Arrays
+}
+
+type field struct {
+	schema   *schema
+	required bool
+}
+
+var zero = 0.0
+
+func str(enum ...string) *schema  { return &schema{kind: kindString, enum: enum} }
+func num() *schema                { return &schema{kind: kindNumber, minimum: &zero} }
+func integer() *schema            { return &schema{kind: kindInteger, minimum: &zero} }
+func boolean() *schema            { return &schema{kind: kindBoolean} }
+func array(items *schema) *schema { return &schema{kind: kindArray, items: items} }
+func object(fields map[string]field) *schema {
+	return &schema{kind: kindObject, fields: fields}
+}
+func required(s *schema) field { return field{schema: s, required: true} }
+func optional(s *schema) field { return field{schema: s} }
+
+var ohhActionNames = []string{
+	ohhDealtCards, ohhMucksCards, ohhShowsCards, ohhPostAnte, ohhPostSB, ohhPostBB,
+	"Straddle", "Post Dead", "Post Extra Blind", ohhFold, ohhCheck, ohhBet, ohhRaise, ohhCall,
+	"Added Chips", "Sits Down", "Stands Up", "Added To Pot",
+}
+
+var ohhSchema = object(map[string]field{
+	"ohh": required(object(map[string]field{
+		"spec_version":     required(str()),
+		"site_name":        required(str()),
+		"network_name":     optional(str()),
+		"internal_version": optional(str()),
+		"tournament":       optional(boolean()),
+		"tournament_info": optional(object(map[string]field{
+			"tournament_number": required(str()),
+			"name":              optional(str()),
+			"currency":          optional(str()),
+			"buyin_amount":      optional(num()),
+			"fee_amount":        optional(num()),
+			"bounty_fee_amount": optional(num()),
+			"start_date_utc":    optional(str()),
+			"tournament_type":   optional(str()),
+		})),
+		"game_number":    required(str()),
+		"start_date_utc": required(str()),
+		"table_name":     required(str()),
+		"table_handle":   optional(str()),
+		"table_skin":     optional(str()),
+		"table_size":     required(integer()),
+		"game_type":      required(str("Holdem")),
+		"bet_limit": required(object(map[string]field{
+			"bet_type": required(str("NL", "PL", "FL")),
+			"bet_cap":  optional(num()),
+		})),
+		"currency":           required(str()),
+		"dealer_seat":        required(integer()),
+		"small_blind_amount": required(num()),
+		"big_blind_amount":   required(num()),
+		"ante_amount":        optional(num()),
+		"hero_player_id":     optional(integer()),
+		"flags":              optional(array(str())),
+		"players": required(&schema{kind: kindArray, minItems: 2, items: object(map[string]field{
+			"id":             required(integer()),
+			"seat":           required(integer()),
+			"name":           required(str()),
+			"display":        optional(str()),
+			"starting_stack": required(num()),
+			"player_bounty":  optional(num()),
+			"is_sitting_out": optional(boolean()),
+		})}),
+		"rounds": required(array(object(map[string]field{
+			"id":     required(integer()),
+			"street": required(str("Preflop", "Flop", "Turn", "River", "Showdown")),
+			"cards":  optional(array(str())),
+			"actions": required(array(object(map[string]field{
+				"action_number": required(integer()),
+				"player_id":     required(integer()),
+				"action":        required(str(ohhActionNames...)),
+				"amount":        optional(num()),
+				"is_allin":      optional(boolean()),
+				"cards":         optional(array(str())),
+			}))),
+		}))),
+		"pots": required(array(object(map[string]field{
+			"number":  required(integer()),
+			"amount":  required(num()),
+			"rake":    optional(num()),
+			"jackpot": optional(num()),
+			"player_wins": required(array(object(map[string]field{
+				"player_id":        required(integer()),
+				"win_amount":       required(num()),
+				"cashout_amount":   optional(num()),
+				"cashout_fee":      optional(num()),
+				"bonus_amount":     optional(num()),
+				"contributed_rake": optional(num()),
+			}))),
+		}))),
+	})),
+})
+
+// ValidateOHH checks an Open Hand History document against the schema and
+// for internal consistency: players referenced by actions and pots exist,
+// cards are valid and streets come in order.
+func ValidateOHH(data []byte) ValidationErrors {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return ValidationErrors{{Message: "invalid JSON: " + err.Error()}}
+	}
+
+	var errs ValidationErrors
+	ohhSchema.validate("", doc, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	var f OHHFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return ValidationErrors{{Message: err.Error()}}
+	}
+	return f.OHH.check()
+}
+
+func (s *schema) validate(path string, v interface{}, errs *ValidationErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.kind {
+	case kindObject:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("expected %s, got %s", s.kind, describe(v))
+			return
+		}
+		for _, name := range sortedKeys(s.fields) {
+			f := s.fields[name]
+			value, present := obj[name]
+			if !present {
+				if f.required {
+					*errs = append(*errs, ValidationError{Path: join(path, name), Message: "is required"})
+				}
+				continue
+			}
+			f.schema.validate(join(path, name), value, errs)
+		}
+	case kindArray:
+		arr, ok := v.([]interface{})
+		if !ok {
+			fail("expected %s, got %s", s.kind, describe(v))
+			return
+		}
+		if len(arr) < s.minItems {
+			fail("expected at least %d items, got %d", s.minItems, len(arr))
+		}
+		for i, item := range arr {
+			s.items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case kindString:
+		str, ok := v.(string)
+		if !ok {
+			fail("expected %s, got %s", s.kind, describe(v))
+			return
+		}
+		if len(s.enum) > 0 && !contains(s.enum, str) {
+			fail("%q is not one of %s", str, strings.Join(s.enum, ", "))
+		}
+	case kindNumber, kindInteger:
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("expected %s, got %s", s.kind, describe(v))
+			return
+		}
+		if s.kind == kindInteger {
+			if _, err := n.Int64(); err != nil {
+				fail("expected %s, got %s", s.kind, n)
+				return
+			}
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail("invalid number %s", n)
+			return
+		}
+		if s.minimum != nil && f < *s.minimum {
+			fail("must be at least %v, got %s", *s.minimum, n)
+		}
+	case kindBoolean:
+		if _, ok := v.(bool); !ok {
+			fail("expected %s, got %s", s.kind, describe(v))
+		}
+	}
+}
+
+// check validates the parts of a hand the schema cannot express
+func (o OHH) check() ValidationErrors {
+	var errs ValidationErrors
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if _, err := time.Parse(time.RFC3339, o.StartDateUTC); err != nil {
+		fail("ohh.start_date_utc", "expected an RFC 3339 time, got %q", o.StartDateUTC)
+	}
+	if o.Tournament && o.TournamentInfo == nil {
+		fail("ohh.tournament_info", "is required for tournament hands")
+	}
+
+	ids := make(map[int]bool)
+	seats := make(map[int]bool)
+	for i, p := range o.Players {
+		path := fmt.Sprintf("ohh.players[%d]", i)
+		if ids[p.ID] {
+			fail(path+".id", "duplicate player id %d", p.ID)
+		}
+		if seats[p.Seat] {
+			fail(path+".seat", "seat %d is taken twice", p.Seat)
+		}
+		if o.TableSize > 0 && p.Seat > o.TableSize {
+			fail(path+".seat", "seat %d is beyond the table size of %d", p.Seat, o.TableSize)
+		}
+		ids[p.ID] = true
+		seats[p.Seat] = true
+	}
+	if !seats[o.DealerSeat] && !o.Tournament {
+		fail("ohh.dealer_seat", "no player in seat %d", o.DealerSeat)
+	}
+	if o.HeroPlayerID != 0 && !ids[o.HeroPlayerID] {
+		fail("ohh.hero_player_id", "unknown player %d", o.HeroPlayerID)
+	}
+
+	lastStreet := -1
+	lastNumber := 0
+	boardCards := map[string]int{"Flop": 3, "Turn": 1, "River": 1}
+	for i, r := range o.Rounds {
+		path := fmt.Sprintf("ohh.rounds[%d]", i)
+		street, _ := ohhStreet(r.Street)
+		if int(street) <= lastStreet {
+			fail(path+".street", "%s cannot follow an earlier round of the same or a later street", r.Street)
+		}
+		lastStreet = int(street)
+		if want, ok := boardCards[r.Street]; ok && len(r.Cards) != want {
+			fail(path+".cards", "expected %d cards on the %s, got %d", want, strings.ToLower(r.Street), len(r.Cards))
+		}
+		checkCards(path+".cards", r.Cards, fail)
+
+		for j, a := range r.Actions {
+			apath := fmt.Sprintf("%s.actions[%d]", path, j)
+			if a.ActionNumber <= lastNumber {
+				fail(apath+".action_number", "expected a number above %d, got %d", lastNumber, a.ActionNumber)
+			}
+			lastNumber = a.ActionNumber
+			if !ids[a.PlayerID] {
+				fail(apath+".player_id", "unknown player %d", a.PlayerID)
+			}
+			switch a.Action {
+			case ohhBet, ohhRaise, ohhCall, ohhPostSB, ohhPostBB, ohhPostAnte:
+				if a.Amount <= 0 {
+					fail(apath+".amount", "%s needs a positive amount", a.Action)
+				}
+			case ohhDealtCards, ohhShowsCards:
+				if len(a.Cards) == 0 {
+					fail(apath+".cards", "%s needs cards", a.Action)
+				}
+			}
+			checkCards(apath+".cards", a.Cards, fail)
+		}
+	}
+
+	for i, pot := range o.Pots {
+		path := fmt.Sprintf("ohh.pots[%d]", i)
+		var won float64
+		for j, w := range pot.PlayerWins {
+			if !ids[w.PlayerID] {
+				fail(fmt.Sprintf("%s.player_wins[%d].player_id", path, j), "unknown player %d", w.PlayerID)
+			}
+			won += w.WinAmount
+		}
+		if won > pot.Amount-pot.Rake-pot.Jackpot+0.005 {
+			fail(path+".player_wins", "winnings of %s exceed the pot of %s after rake", decimal(won), decimal(pot.Amount-pot.Rake-pot.Jackpot))
+		}
+	}
+	return errs
+}
+
+func checkCards(path string, cards []string, fail func(path, format string, args ...interface{})) {
+	for i, c := range cards {
+		if _, err := FromSiteCard(c); err != nil {
+			fail(fmt.Sprintf("%s[%d]", path, i), "invalid card %q", c)
+		}
+	}
+}
+
+func describe(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(fields map[string]field) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,532 @@
+package handhistory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"texas-holdem-backend/game"
+)
+
+// ParseError reports a malformed hand and the line it was found on
+type ParseError struct {
+	Line    int    `json:"line"`
+	HandID  string `json:"handId,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ParseError) Error() string {
+	if e.HandID != "" {
+		return fmt.Sprintf("line %d (hand #%s): %s", e.Line, e.HandID, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+var (
+	handStartRe  = regexp.MustCompile(`^(.+?) (?:Hand|Game) #(\d+):\s+(.*)$`)
+	tournamentRe = regexp.MustCompile(`^Tournament #(\d+), (.+?) (Hold'em) (No Limit|Pot Limit|Limit) - (?:.*?)(?:Level ([IVXLCDM]+) )?\((\S+)/(\S+)\) - (.+)$`)
+	cashRe       = regexp.MustCompile(`^(Hold'em) (No Limit|Pot Limit|Limit) \((\S+)/(\S+?)(?: (USD|EUR|GBP))?\) - (.+)$`)
+	tableRe      = regexp.MustCompile(`^Table '(.+)' (\d+)-max (?:\(Play Money\) )?Seat #(\d+) is the button$`)
+	seatRe       = regexp.MustCompile(`^Seat (\d+): (.+) \((\S+) in chips(?:, .*)?\)( is sitting out| out of hand.*)?$`)
+	dealtRe      = regexp.MustCompile(`^Dealt to (.+?) \[([^\]]+)\]$`)
+	streetRe     = regexp.MustCompile(`^\*\*\* (HOLE CARDS|FLOP|TURN|RIVER|SHOW DOWN|SUMMARY) \*\*\*(.*)$`)
+	uncalledRe   = regexp.MustCompile(`^Uncalled bet \((\S+)\) returned to (.+)$`)
+	collectRe    = regexp.MustCompile(`^(.+?) collected (\S+) from (pot|main pot|side pot(?:-(\d+))?)$`)
+	totalPotRe   = regexp.MustCompile(`^Total pot (\S+).*\| Rake (\S+)`)
+	boardRe      = regexp.MustCompile(`^Board \[([^\]]+)\]$`)
+	summaryCards = regexp.MustCompile(`^Seat \d+: (.+?)(?: \((?:button|small blind|big blind)\))* (?:mucked|showed) \[([^\]]+)\]`)
+	cardsRe      = regexp.MustCompile(`\[([^\]]+)\]`)
+	etTimeRe     = regexp.MustCompile(`(\d{4}/\d{2}/\d{2} \d{1,2}:\d{2}:\d{2}) ET`)
+	anyTimeRe    = regexp.MustCompile(`(\d{4}/\d{2}/\d{2} \d{1,2}:\d{2}:\d{2})`)
+)
+
+// Lines that carry no information about the play of the hand
+var ignoredLines = []*regexp.Regexp{
+	regexp.MustCompile(`^.+ said, ".*"$`),
+	regexp.MustCompile(`^.+ (?:is disconnected|is connected|has timed out.*|has returned|joins the table at seat #\d+|leaves the table|will be allowed to play after the button|was removed from the table.*|is sitting out|sits out)\s*$`),
+	regexp.MustCompile(`^.+: (?:is sitting out|sits out|doesn't show hand|is disconnected|is connected|has timed out)\s*$`),
+	regexp.MustCompile(`^.+ (?:finished the tournament.*|wins the tournament.*|re-buys.*|adds .* chips.*)$`),
+	regexp.MustCompile(`^Seat \d+: `), // Summary seat lines, handled separately for mucked cards
+}
+
+// Parse reads PokerStars-format hand histories. A file may hold any number
+// of hands separated by blank lines. Hands that cannot be parsed are left
+// out of the result and reported as errors with their line numbers.
+func Parse(r io.Reader) ([]*HandHistory, []ParseError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var hands []*HandHistory
+	var errs []ParseError
+	var p *handParser
+
+	finish := func() {
+		if p == nil {
+			return
+		}
+		if err := p.finish(); err != nil {
+			errs = append(errs, *err)
+		} else {
+			hands = append(hands, p.hh)
+		}
+		p = nil
+	}
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF"))
+
+		if m := handStartRe.FindStringSubmatch(line); m != nil {
+			finish()
+			p = &handParser{hh: &HandHistory{Site: m[1], HandID: m[2]}, start: lineNo}
+			p.header(lineNo, m[3])
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if p == nil {
+			errs = append(errs, ParseError{Line: lineNo, Message: "text outside of a hand: " + line})
+			continue
+		}
+		if p.err == nil {
+			p.line(lineNo, line)
+		}
+	}
+	finish()
+
+	if err := scanner.Err(); err != nil {
+		return hands, errs, err
+	}
+	return hands, errs, nil
+}
+
+// ParseString is a convenience wrapper around Parse
+func ParseString(text string) ([]*HandHistory, []ParseError) {
+	hands, errs, _ := Parse(strings.NewReader(text))
+	return hands, errs
+}
+
+type handParser struct {
+	hh       *HandHistory
+	start    int
+	street   game.Street
+	section  string
+	err      *ParseError
+	sawTable bool
+	dealt    int
+}
+
+func (p *handParser) fail(line int, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &ParseError{Line: line, HandID: p.hh.HandID, Message: fmt.Sprintf(format, args...)}
+	}
+}
+
+func (p *handParser) header(lineNo int, rest string) {
+	hh := p.hh
+	var stamp string
+
+	if m := tournamentRe.FindStringSubmatch(rest); m != nil {
+		hh.Tournament = &Tournament{ID: m[1], BuyIn: m[2], Level: m[5]}
+		hh.Game, hh.Limit = m[3], m[4]
+		stamp = m[8]
+		p.blinds(lineNo, m[6], m[7])
+	} else if m := cashRe.FindStringSubmatch(rest); m != nil {
+		hh.Game, hh.Limit = m[1], m[2]
+		hh.Currency = m[5]
+		if hh.Currency == "" {
+			hh.Currency = currencyOf(m[3])
+		}
+		stamp = m[6]
+		p.blinds(lineNo, m[3], m[4])
+	} else {
+		p.fail(lineNo, "unsupported game header: %s", rest)
+		return
+	}
+
+	t, err := parseTime(stamp)
+	if err != nil {
+		p.fail(lineNo, "invalid time: %s", stamp)
+		return
+	}
+	hh.Time = t
+}
+
+func (p *handParser) blinds(lineNo int, sb, bb string) {
+	var err error
+	if p.hh.SmallBlind, err = p.amount(sb); err != nil {
+		p.fail(lineNo, "%v", err)
+	}
+	if p.hh.BigBlind, err = p.amount(bb); err != nil {
+		p.fail(lineNo, "%v", err)
+	}
+}
+
+func currencyOf(amount string) string {
+	for code, symbol := range currencySymbols {
+		if strings.HasPrefix(amount, symbol) {
+			return code
+		}
+	}
+	return ""
+}
+
+func parseTime(stamp string) (time.Time, error) {
+	if m := etTimeRe.FindStringSubmatch(stamp); m != nil {
+		return time.ParseInLocation(timeLayout, m[1], eastern)
+	}
+	if m := anyTimeRe.FindStringSubmatch(stamp); m != nil {
+		return time.ParseInLocation(timeLayout, m[1], time.UTC)
+	}
+	return time.Time{}, fmt.Errorf("no time found")
+}
+
+// amount parses "$1.25", "1,500" or "0.5" into the smallest unit
+func (p *handParser) amount(s string) (int64, error) {
+	clean := strings.ReplaceAll(s, ",", "")
+	for _, symbol := range currencySymbols {
+		clean = strings.TrimPrefix(clean, symbol)
+	}
+	if p.hh.Currency == "" {
+		if v, err := strconv.ParseInt(clean, 10, 64); err == nil {
+			return v, nil
+		}
+	}
+	whole, frac, hasFrac := strings.Cut(clean, ".")
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	if p.hh.Currency == "" && hasFrac && f != 0 {
+		return 0, fmt.Errorf("fractional chip amount: %s", s)
+	}
+	if p.hh.Currency == "" {
+		return w, nil
+	}
+	return w*100 + f, nil
+}
+
+func (p *handParser) line(lineNo int, line string) {
+	hh := p.hh
+
+	if m := streetRe.FindStringSubmatch(line); m != nil {
+		p.section = m[1]
+		switch m[1] {
+		case "FLOP", "TURN", "RIVER":
+			p.street++
+			all := cardsRe.FindAllStringSubmatch(m[2], -1)
+			if len(all) == 0 {
+				p.fail(lineNo, "missing board cards")
+				return
+			}
+			board, err := parseCards(strings.Join(flatten(all), " "))
+			if err != nil {
+				p.fail(lineNo, "%v", err)
+				return
+			}
+			expected := map[string]int{"FLOP": 3, "TURN": 4, "RIVER": 5}[m[1]]
+			if len(board) != expected {
+				p.fail(lineNo, "expected %d board cards on the %s, got %d", expected, strings.ToLower(m[1]), len(board))
+				return
+			}
+			hh.Board = board
+		case "SHOW DOWN":
+			p.street = game.Showdown
+		}
+		return
+	}
+
+	if p.section == "SUMMARY" {
+		p.summaryLine(lineNo, line)
+		return
+	}
+
+	if !p.sawTable {
+		if m := tableRe.FindStringSubmatch(line); m != nil {
+			hh.TableName = m[1]
+			hh.MaxSeats, _ = strconv.Atoi(m[2])
+			hh.ButtonSeat, _ = strconv.Atoi(m[3])
+			p.sawTable = true
+			return
+		}
+		p.fail(lineNo, "expected table line, got: %s", line)
+		return
+	}
+
+	if p.section == "" {
+		if m := seatRe.FindStringSubmatch(line); m != nil {
+			number, _ := strconv.Atoi(m[1])
+			stack, err := p.amount(m[3])
+			if err != nil {
+				p.fail(lineNo, "%v", err)
+				return
+			}
+			hh.Seats = append(hh.Seats, Seat{Number: number, Name: m[2], Stack: stack, SittingOut: m[4] != ""})
+			return
+		}
+	}
+
+	if m := dealtRe.FindStringSubmatch(line); m != nil {
+		seat := hh.SeatOf(m[1])
+		if seat == nil {
+			p.fail(lineNo, "cards dealt to unknown player %s", m[1])
+			return
+		}
+		cards, err := parseCards(m[2])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		seat.HoleCards = cards
+		p.dealt++
+		if hh.Hero == "" {
+			hh.Hero = m[1]
+		}
+		return
+	}
+
+	if m := uncalledRe.FindStringSubmatch(line); m != nil {
+		amount, err := p.amount(m[1])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		p.add(lineNo, Action{Street: p.street, Player: m[2], Type: Uncalled, Amount: amount})
+		return
+	}
+
+	if m := collectRe.FindStringSubmatch(line); m != nil && hh.SeatOf(m[1]) != nil {
+		amount, err := p.amount(m[2])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		pot := 0
+		if strings.HasPrefix(m[3], "side pot") {
+			pot = 1
+			if m[4] != "" {
+				pot, _ = strconv.Atoi(m[4])
+			}
+		}
+		p.add(lineNo, Action{Street: p.street, Player: m[1], Type: Collect, Amount: amount, Pot: pot})
+		return
+	}
+
+	if name, rest, ok := p.splitPlayer(line); ok {
+		if p.playerAction(lineNo, name, rest) {
+			return
+		}
+	}
+
+	for _, re := range ignoredLines {
+		if re.MatchString(line) {
+			return
+		}
+	}
+	p.fail(lineNo, "unrecognised line: %s", line)
+}
+
+// splitPlayer finds the seated player a "Name: action" line belongs to,
+// preferring the longest matching name
+func (p *handParser) splitPlayer(line string) (string, string, bool) {
+	best := ""
+	for _, s := range p.hh.Seats {
+		if strings.HasPrefix(line, s.Name+": ") && len(s.Name) > len(best) {
+			best = s.Name
+		}
+	}
+	if best == "" {
+		return "", "", false
+	}
+	return best, line[len(best)+2:], true
+}
+
+// playerAction parses the part of an action line after "Name: "
+func (p *handParser) playerAction(lineNo int, name, rest string) bool {
+	allIn := strings.HasSuffix(rest, " and is all-in")
+	rest = strings.TrimSuffix(rest, " and is all-in")
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return false
+	}
+
+	a := Action{Street: p.street, Player: name, AllIn: allIn}
+	parse := func(s string) int64 {
+		v, err := p.amount(s)
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+		}
+		return v
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "posts small & big blinds "):
+		a.Type = PostBigBlind
+		a.Amount = parse(fields[len(fields)-1])
+	case strings.HasPrefix(rest, "posts small blind "):
+		a.Type = PostSmallBlind
+		a.Amount = parse(fields[3])
+	case strings.HasPrefix(rest, "posts big blind "):
+		a.Type = PostBigBlind
+		a.Amount = parse(fields[3])
+	case strings.HasPrefix(rest, "posts the ante "):
+		a.Type = PostAnte
+		a.Amount = parse(fields[3])
+		if p.hh.Ante == 0 {
+			p.hh.Ante = a.Amount
+		}
+	case rest == "folds" || strings.HasPrefix(rest, "folds ["):
+		a.Type = Fold
+	case rest == "checks":
+		a.Type = Check
+	case fields[0] == "calls" && len(fields) == 2:
+		a.Type = Call
+		a.Amount = parse(fields[1])
+	case fields[0] == "bets" && len(fields) == 2:
+		a.Type = Bet
+		a.Amount = parse(fields[1])
+	case fields[0] == "raises" && len(fields) == 4 && fields[2] == "to":
+		a.Type = Raise
+		a.Amount = parse(fields[1])
+		a.To = parse(fields[3])
+	case fields[0] == "shows":
+		m := cardsRe.FindStringSubmatch(rest)
+		if m == nil {
+			return false
+		}
+		cards, err := parseCards(m[1])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return true
+		}
+		a.Type = Show
+		a.Street = game.Showdown
+		a.Cards = cards
+		if i := strings.Index(rest, "("); i >= 0 && strings.HasSuffix(rest, ")") {
+			a.Description = rest[i+1 : len(rest)-1]
+		}
+		if s := p.hh.SeatOf(name); s != nil && len(s.HoleCards) == 0 {
+			s.HoleCards = cards
+		}
+	case strings.HasPrefix(rest, "mucks hand"):
+		a.Type = Muck
+		a.Street = game.Showdown
+	default:
+		return false
+	}
+
+	p.add(lineNo, a)
+	return true
+}
+
+func (p *handParser) add(lineNo int, a Action) {
+	if p.hh.SeatOf(a.Player) == nil {
+		p.fail(lineNo, "action by unknown player %s", a.Player)
+		return
+	}
+	p.hh.Actions = append(p.hh.Actions, a)
+}
+
+func (p *handParser) summaryLine(lineNo int, line string) {
+	hh := p.hh
+	if m := totalPotRe.FindStringSubmatch(line); m != nil {
+		total, err := p.amount(m[1])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		rake, err := p.amount(m[2])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		hh.TotalPot, hh.Rake = total, rake
+		return
+	}
+	if m := boardRe.FindStringSubmatch(line); m != nil {
+		board, err := parseCards(m[1])
+		if err != nil {
+			p.fail(lineNo, "%v", err)
+			return
+		}
+		hh.Board = board
+		return
+	}
+	if m := summaryCards.FindStringSubmatch(line); m != nil {
+		if s := hh.SeatOf(m[1]); s != nil && len(s.HoleCards) == 0 {
+			if cards, err := parseCards(m[2]); err == nil {
+				s.HoleCards = cards
+			}
+		}
+	}
+}
+
+// finish checks the hand as a whole once all its lines have been read
+func (p *handParser) finish() *ParseError {
+	if p.err != nil {
+		return p.err
+	}
+	hh := p.hh
+	fail := func(msg string) *ParseError {
+		return &ParseError{Line: p.start, HandID: hh.HandID, Message: msg}
+	}
+	if !p.sawTable {
+		return fail("missing table line")
+	}
+	if len(hh.Seats) < 2 {
+		return fail("fewer than two seated players")
+	}
+	if p.section != "SUMMARY" {
+		return fail("hand is incomplete: missing summary")
+	}
+	found := false
+	for _, s := range hh.Seats {
+		if s.Number == hh.ButtonSeat {
+			found = true
+		}
+	}
+	if !found && hh.Tournament == nil {
+		return fail(fmt.Sprintf("button seat %d is empty", hh.ButtonSeat))
+	}
+	// Files written with every player's cards have no single hero
+	if p.dealt > 1 {
+		hh.Hero = ""
+	}
+	return nil
+}
+
+func parseCards(s string) ([]string, error) {
+	var cards []string
+	for _, c := range strings.Fields(s) {
+		card, err := FromSiteCard(c)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+func flatten(matches [][]string) []string {
+	var out []string
+	for _, m := range matches {
+		out = append(out, m[1])
+	}
+	return out
+}
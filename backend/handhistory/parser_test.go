@@ -0,0 +1,244 @@
+package handhistory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/game"
+)
+
+const cashHand = `PokerStars Hand #245830112233:  Hold'em No Limit ($0.25/$0.50 USD) - 2023/03/04 21:15:42 CET [2023/03/04 15:15:42 ET]
+Table 'Andromeda V' 6-max Seat #3 is the button
+Seat 1: Villain1 ($48.75 in chips)
+Seat 3: Hero ($50 in chips)
+Seat 5: big fish ($61.20 in chips)
+big fish: posts small blind $0.25
+Villain1: posts big blind $0.50
+*** HOLE CARDS ***
+Dealt to Hero [Qs Qd]
+Hero: raises $1 to $1.50
+big fish: calls $1.25
+Villain1: folds
+*** FLOP *** [Qh 7c 2d]
+big fish: checks
+Hero: bets $2
+big fish: raises $4 to $6
+Hero: calls $4
+*** TURN *** [Qh 7c 2d] [9s]
+big fish: bets $10
+Hero: raises $32.50 to $42.50 and is all-in
+big fish: calls $32.50
+*** RIVER *** [Qh 7c 2d 9s] [3h]
+*** SHOW DOWN ***
+big fish: shows [7d 7s] (three of a kind, Sevens)
+Hero: shows [Qs Qd] (three of a kind, Queens)
+Hero collected $97.50 from pot
+*** SUMMARY ***
+Total pot $100.50 | Rake $3
+Board [Qh 7c 2d 9s 3h]
+Seat 1: Villain1 (big blind) folded before Flop
+Seat 3: Hero (button) showed [Qs Qd] and won ($97.50) with three of a kind, Queens
+Seat 5: big fish (small blind) showed [7d 7s] and lost with three of a kind, Sevens
+
+
+
+PokerStars Hand #245830112234:  Hold'em Pot Limit (€0.10/€0.25 EUR) - 2023/03/04 15:17:01 ET
+Table 'Andromeda V' 6-max Seat #5 is the button
+Seat 1: Villain1 (€25 in chips)
+Seat 3: Hero (€50.25 in chips)
+Seat 5: big fish (€26.70 in chips)
+Villain1: posts small blind €0.10
+Hero: posts big blind €0.25
+*** HOLE CARDS ***
+Dealt to Hero [8c 9c]
+big fish: raises €0.50 to €0.75
+big fish said, "gl"
+Villain1: folds
+Hero: folds
+Uncalled bet (€0.50) returned to big fish
+big fish collected €0.60 from pot
+big fish: doesn't show hand
+*** SUMMARY ***
+Total pot €0.60 | Rake €0
+Seat 1: Villain1 (small blind) folded before Flop
+Seat 3: Hero (big blind) folded before Flop
+Seat 5: big fish (button) collected (€0.60)
+`
+
+const tournamentHand = `PokerStars Hand #245830200001: Tournament #3512345678, $10+$1 USD Hold'em No Limit - Level III (25/50) - 2023/03/05 20:00:00 ET
+Table '3512345678 12' 9-max Seat #2 is the button
+Seat 1: Ann (1,475 in chips)
+Seat 2: Ben (3,000 in chips)
+Seat 4: Cid (500 in chips)
+Ann: posts the ante 5
+Ben: posts the ante 5
+Cid: posts the ante 5
+Cid: posts small blind 25
+Ann: posts big blind 50
+*** HOLE CARDS ***
+Dealt to Ann [Ac Kc]
+Ben: raises 100 to 150
+Cid: raises 345 to 495 and is all-in
+Ann: raises 975 to 1470 and is all-in
+Ben: calls 1320
+*** FLOP *** [Kd 8h 4s]
+*** TURN *** [Kd 8h 4s] [2c]
+*** RIVER *** [Kd 8h 4s 2c] [Js]
+*** SHOW DOWN ***
+Ann: shows [Ac Kc] (a pair of Kings)
+Ben: shows [Jh Jd] (three of a kind, Jacks)
+Cid: shows [As Ad] (a pair of Aces)
+Ben collected 1950 from side pot
+Ben collected 1500 from main pot
+*** SUMMARY ***
+Total pot 3450 Main pot 1500. Side pot 1950. | Rake 0
+Board [Kd 8h 4s 2c Js]
+Seat 1: Ann (big blind) showed [Ac Kc] and lost with a pair of Kings
+Seat 2: Ben (button) showed [Jh Jd] and won (3450) with three of a kind, Jacks
+Seat 4: Cid (small blind) showed [As Ad] and lost with a pair of Aces
+`
+
+func TestParseCashHands(t *testing.T) {
+	hands, errs := ParseString(cashHand)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if len(hands) != 2 {
+		t.Fatalf("Expected 2 hands, got %d", len(hands))
+	}
+
+	hh := hands[0]
+	if hh.Site != "PokerStars" || hh.HandID != "245830112233" || hh.Currency != "USD" || hh.Limit != "No Limit" {
+		t.Errorf("Unexpected header: %s #%s %s %s", hh.Site, hh.HandID, hh.Currency, hh.Limit)
+	}
+	if hh.SmallBlind != 25 || hh.BigBlind != 50 {
+		t.Errorf("Expected blinds 25/50 cents, got %d/%d", hh.SmallBlind, hh.BigBlind)
+	}
+	if expected := time.Date(2023, 3, 4, 20, 15, 42, 0, time.UTC); !hh.Time.Equal(expected) {
+		t.Errorf("Expected time %v, got %v", expected, hh.Time.UTC())
+	}
+	if hh.TableName != "Andromeda V" || hh.MaxSeats != 6 || hh.ButtonSeat != 3 || len(hh.Seats) != 3 {
+		t.Errorf("Unexpected table: %q %d-max button %d, %d seats", hh.TableName, hh.MaxSeats, hh.ButtonSeat, len(hh.Seats))
+	}
+	if s := hh.SeatOf("big fish"); s == nil || s.Stack != 6120 || strings.Join(s.HoleCards, " ") != "D7 S7" {
+		t.Errorf("Unexpected seat for big fish: %+v", s)
+	}
+	if hh.Hero != "Hero" || strings.Join(hh.Board, " ") != "HQ C7 D2 S9 H3" {
+		t.Errorf("Unexpected hero %q or board %v", hh.Hero, hh.Board)
+	}
+	if hh.TotalPot != 10050 || hh.Rake != 300 {
+		t.Errorf("Expected total pot 10050 and rake 300, got %d and %d", hh.TotalPot, hh.Rake)
+	}
+
+	raise := hh.Actions[10]
+	if raise.Street != game.Turn || raise.Type != Raise || raise.Amount != 3250 || raise.To != 4250 || !raise.AllIn {
+		t.Errorf("Unexpected turn raise: %+v", raise)
+	}
+
+	second := hands[1]
+	if second.Currency != "EUR" || second.Limit != "Pot Limit" || second.BigBlind != 25 {
+		t.Errorf("Unexpected second header: %s %s %d", second.Currency, second.Limit, second.BigBlind)
+	}
+	if last := second.Actions[len(second.Actions)-1]; last.Type != Collect || last.Amount != 60 {
+		t.Errorf("Expected big fish to collect 60, got %+v", last)
+	}
+}
+
+func TestParseTournamentHand(t *testing.T) {
+	hands, errs := ParseString(tournamentHand)
+	if len(errs) != 0 || len(hands) != 1 {
+		t.Fatalf("Expected 1 hand and no errors, got %d hands and %v", len(hands), errs)
+	}
+
+	hh := hands[0]
+	if hh.Tournament == nil || hh.Tournament.ID != "3512345678" || hh.Tournament.BuyIn != "$10+$1 USD" || hh.Tournament.Level != "III" {
+		t.Fatalf("Unexpected tournament: %+v", hh.Tournament)
+	}
+	if hh.Currency != "" || hh.SmallBlind != 25 || hh.BigBlind != 50 || hh.Ante != 5 {
+		t.Errorf("Unexpected stakes: %q %d/%d ante %d", hh.Currency, hh.SmallBlind, hh.BigBlind, hh.Ante)
+	}
+	if s := hh.SeatOf("Ann"); s == nil || s.Stack != 1475 {
+		t.Errorf("Expected Ann with 1475 chips, got %+v", s)
+	}
+
+	pots := map[int]int64{}
+	for _, a := range hh.Actions {
+		if a.Type == Collect {
+			pots[a.Pot] += a.Amount
+		}
+	}
+	if pots[0] != 1500 || pots[1] != 1950 {
+		t.Errorf("Expected main pot 1500 and side pot 1950, got %v", pots)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	malformed := strings.Replace(cashHand, "big fish: calls $1.25", "big fish: calls a lot", 1)
+	hands, errs := ParseString(malformed + "\n\n" + tournamentHand)
+
+	if len(hands) != 2 {
+		t.Errorf("Expected the 2 valid hands to be kept, got %d", len(hands))
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if errs[0].Line != 11 || errs[0].HandID != "245830112233" {
+		t.Errorf("Expected error on line 11 of hand 245830112233, got %v", errs[0])
+	}
+
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"Truncated hand", strings.Split(cashHand, "*** SUMMARY ***")[0], 1},
+		{"Bad card", strings.Replace(tournamentHand, "[Kd 8h 4s]", "[Kd 8h 4x]", 1), 17},
+		{"Unknown game", "PokerStars Hand #1:  Omaha Pot Limit ($0.05/$0.10 USD) - 2023/03/04 15:15:42 ET\n", 1},
+		{"Text before hand", "hello\n" + tournamentHand, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := ParseString(tt.text)
+			if len(errs) == 0 {
+				t.Fatalf("Expected an error")
+			}
+			if errs[0].Line != tt.line {
+				t.Errorf("Expected error on line %d, got %v", tt.line, errs[0])
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	original := sampleHand()
+	hands, errs := ParseString(Format(original))
+	if len(errs) != 0 || len(hands) != 1 {
+		t.Fatalf("Expected 1 hand and no errors, got %d hands and %v", len(hands), errs)
+	}
+
+	hh := hands[0]
+	hh.Site = ""
+	if got, expected := Format(hh), Format(original); got != expected {
+		t.Errorf("Round trip changed the hand:\n%s\nExpected:\n%s", got, expected)
+	}
+}
+
+func TestVerifyShowdown(t *testing.T) {
+	hands, _ := ParseString(cashHand + "\n\n" + tournamentHand)
+	for _, hh := range hands {
+		if err := VerifyShowdown(hh); err != nil {
+			t.Errorf("Hand #%s: unexpected error: %v", hh.HandID, err)
+		}
+	}
+
+	wrong := strings.Replace(tournamentHand, "Ben collected 1500 from main pot", "Cid collected 1500 from main pot", 1)
+	hands, errs := ParseString(wrong)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no parse errors, got %v", errs)
+	}
+	err := VerifyShowdown(hands[0])
+	if err == nil || !strings.Contains(err.Error(), "main pot collected by Cid") {
+		t.Errorf("Expected main pot error, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package handhistory
+
+import (
+	"strconv"
+
+	"texas-holdem-backend/game"
+)
+
+// Recorder turns the events of a game table into hand histories. Every
+// finished hand is passed to the callback.
+type Recorder struct {
+	table  *game.Table
+	onHand func(*HandHistory)
+
+	hh         *HandHistory
+	names      map[int]string
+	currentBet int64
+}
+
+// NewRecorder subscribes a recorder to the table's events
+func NewRecorder(table *game.Table, onHand func(*HandHistory)) *Recorder {
+	r := &Recorder{table: table, onHand: onHand}
+	table.Subscribe(r.handle)
+	return r
+}
+
+func (r *Recorder) handle(e game.Event) {
+	if e.Type == game.EventHandStart {
+		r.start(e)
+		return
+	}
+	hh := r.hh
+	if hh == nil {
+		return
+	}
+
+	name := r.names[e.Seat]
+	amount := int64(e.Amount)
+
+	switch e.Type {
+	case game.EventSmallBlind:
+		hh.Actions = append(hh.Actions, Action{Street: game.Preflop, Player: name, Type: PostSmallBlind, Amount: amount, AllIn: e.AllIn})
+		r.raiseTo(amount)
+	case game.EventBigBlind:
+		hh.Actions = append(hh.Actions, Action{Street: game.Preflop, Player: name, Type: PostBigBlind, Amount: amount, AllIn: e.AllIn})
+		r.raiseTo(amount)
+
+	case game.EventHoleCards:
+		if s := hh.SeatOf(name); s != nil {
+			s.HoleCards = append([]string(nil), e.Cards...)
+		}
+
+	case game.EventAction:
+		a := Action{Street: e.Street, Player: name, AllIn: e.AllIn}
+		switch e.Action {
+		case game.Fold:
+			a.Type = Fold
+		case game.Check:
+			a.Type = Check
+		case game.Call:
+			a.Type = Call
+			a.Amount = amount
+		case game.Bet:
+			a.Type = Bet
+			a.Amount = amount
+			r.currentBet = amount
+		case game.Raise:
+			a.Type = Raise
+			a.Amount = amount - r.currentBet
+			a.To = amount
+			r.raiseTo(amount)
+		}
+		hh.Actions = append(hh.Actions, a)
+
+	case game.EventStreet:
+		hh.Board = append([]string(nil), e.Board...)
+		r.currentBet = 0
+
+	case game.EventUncalled:
+		hh.Actions = append(hh.Actions, Action{Street: e.Street, Player: name, Type: Uncalled, Amount: amount})
+
+	case game.EventShowdown:
+		hh.Actions = append(hh.Actions, Action{
+			Street:      game.Showdown,
+			Player:      name,
+			Type:        Show,
+			Cards:       append([]string(nil), e.Cards...),
+			Description: e.HandDescription,
+		})
+
+	case game.EventPotAwarded:
+		hh.Actions = append(hh.Actions, Action{Street: e.Street, Player: name, Type: Collect, Amount: amount, Pot: e.PotIndex})
+		hh.TotalPot += amount
+
+	case game.EventHandEnd:
+		r.hh = nil
+		if r.onHand != nil {
+			r.onHand(hh)
+		}
+	}
+}
+
+func (r *Recorder) raiseTo(amount int64) {
+	if amount > r.currentBet {
+		r.currentBet = amount
+	}
+}
+
+func (r *Recorder) start(e game.Event) {
+	cfg := r.table.Config()
+	hh := &HandHistory{
+		HandID:     strconv.Itoa(e.Hand),
+		Game:       "Hold'em",
+		Limit:      cfg.Structure.Name(),
+		SmallBlind: int64(cfg.SmallBlind),
+		BigBlind:   int64(cfg.BigBlind),
+		Time:       e.Time,
+		TableName:  r.table.ID,
+		MaxSeats:   cfg.MaxSeats,
+		ButtonSeat: e.Seat + 1,
+	}
+
+	r.names = make(map[int]string)
+	for _, s := range e.Seats {
+		r.names[s.Seat] = s.Name
+		hh.Seats = append(hh.Seats, Seat{
+			Number:     s.Seat + 1,
+			Name:       s.Name,
+			Stack:      int64(s.Stack),
+			SittingOut: !s.InHand,
+		})
+	}
+
+	r.hh = hh
+	r.currentBet = 0
+}
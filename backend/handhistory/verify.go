@@ -0,0 +1,128 @@
+package handhistory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/poker"
+)
+
+// VerifyShowdown re-evaluates the hands shown at showdown and checks that
+// every contested pot was collected by the players holding the best hand.
+// Hands that ended without a showdown verify trivially.
+func VerifyShowdown(hh *HandHistory) error {
+	shown := make(map[string][]string)
+	for _, a := range hh.Actions {
+		if a.Type == Show {
+			shown[a.Player] = a.Cards
+		}
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+	if len(hh.Board) != 5 {
+		return fmt.Errorf("showdown with %d board cards", len(hh.Board))
+	}
+
+	committed := hh.Committed()
+	players := make([]poker.PotPlayer, len(hh.Seats))
+	for i, s := range hh.Seats {
+		players[i] = poker.PotPlayer{Contribution: int(committed[s.Name]), Folded: true}
+		cards, ok := shown[s.Name]
+		if !ok {
+			continue
+		}
+		parsed, err := poker.ParseCards(append(append([]string(nil), cards...), hh.Board...))
+		if err != nil {
+			return fmt.Errorf("%s: %v", s.Name, err)
+		}
+		players[i].Folded = false
+		players[i].Score = poker.EvaluateBestHand(parsed)
+	}
+
+	collectors := make(map[int][]string)
+	for _, a := range hh.Actions {
+		if a.Type == Collect {
+			collectors[a.Pot] = append(collectors[a.Pot], a.Player)
+		}
+	}
+
+	for i, pot := range poker.BuildPots(players) {
+		if len(pot.Eligible) < 2 {
+			continue
+		}
+		var winners []string
+		for j, share := range poker.SplitPot(players, pot) {
+			if share > 0 {
+				winners = append(winners, hh.Seats[j].Name)
+			}
+		}
+		if got := uniqueSorted(collectors[i]); !equalNames(got, uniqueSorted(winners)) {
+			return fmt.Errorf("%s collected by %s, but the best hand was held by %s",
+				hh.potName(i), nameList(got), nameList(winners))
+		}
+	}
+	return nil
+}
+
+// Committed returns the chips each player put into the pot, less any
+// uncalled bets returned to them
+func (hh *HandHistory) Committed() map[string]int64 {
+	total := make(map[string]int64)
+	street := make(map[string]int64)
+	current := game.Preflop
+	for _, a := range hh.Actions {
+		if a.Street != current {
+			current = a.Street
+			street = make(map[string]int64)
+		}
+		switch a.Type {
+		case PostAnte:
+			total[a.Player] += a.Amount
+		case PostSmallBlind, PostBigBlind, Call, Bet:
+			total[a.Player] += a.Amount
+			street[a.Player] += a.Amount
+		case Raise:
+			total[a.Player] += a.To - street[a.Player]
+			street[a.Player] = a.To
+		case Uncalled:
+			total[a.Player] -= a.Amount
+			street[a.Player] -= a.Amount
+		}
+	}
+	return total
+}
+
+func uniqueSorted(names []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func nameList(names []string) string {
+	if len(names) == 0 {
+		return "nobody"
+	}
+	return strings.Join(names, " and ")
+}
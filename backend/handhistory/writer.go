@@ -0,0 +1,350 @@
+package handhistory
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Hand times are written in Eastern Time on every platform
+
+	"texas-holdem-backend/game"
+)
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+}
+
+var eastern = func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}()
+
+const timeLayout = "2006/01/02 15:04:05"
+
+// Write writes a hand in the PokerStars text format
+func Write(w io.Writer, hh *HandHistory) error {
+	_, err := io.WriteString(w, Format(hh))
+	return err
+}
+
+// WriteSession writes several hands, separated by blank lines, as a single
+// hand history file
+func WriteSession(w io.Writer, hands []*HandHistory) error {
+	for i, hh := range hands {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n\n"); err != nil {
+				return err
+			}
+		}
+		if err := Write(w, hh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format renders a hand in the PokerStars text format
+func Format(hh *HandHistory) string {
+	var b strings.Builder
+	amt := hh.FormatAmount
+
+	site := hh.Site
+	if site == "" {
+		site = "PokerStars"
+	}
+	gameName := hh.Game
+	if gameName == "" {
+		gameName = "Hold'em"
+	}
+	currency := ""
+	if hh.Currency != "" {
+		currency = " " + hh.Currency
+	}
+	stamp := hh.Time.In(eastern).Format(timeLayout) + " ET"
+
+	if t := hh.Tournament; t != nil {
+		level := ""
+		if t.Level != "" {
+			level = "Level " + t.Level + " "
+		}
+		fmt.Fprintf(&b, "%s Hand #%s: Tournament #%s, %s %s %s - %s(%s/%s) - %s\n",
+			site, hh.HandID, t.ID, t.BuyIn, gameName, hh.Limit, level, amt(hh.SmallBlind), amt(hh.BigBlind), stamp)
+	} else {
+		fmt.Fprintf(&b, "%s Hand #%s:  %s %s (%s/%s%s) - %s\n",
+			site, hh.HandID, gameName, hh.Limit, amt(hh.SmallBlind), amt(hh.BigBlind), currency, stamp)
+	}
+	fmt.Fprintf(&b, "Table '%s' %d-max Seat #%d is the button\n", hh.TableName, hh.MaxSeats, hh.ButtonSeat)
+
+	for _, s := range hh.Seats {
+		fmt.Fprintf(&b, "Seat %d: %s (%s in chips)", s.Number, s.Name, amt(s.Stack))
+		if s.SittingOut {
+			b.WriteString(" is sitting out")
+		}
+		b.WriteString("\n")
+	}
+
+	street := game.Preflop
+	wroteHoleCards := false
+	board := 0
+	for _, a := range hh.Actions {
+		if !wroteHoleCards && a.Type != PostSmallBlind && a.Type != PostBigBlind && a.Type != PostAnte {
+			hh.writeHoleCards(&b)
+			wroteHoleCards = true
+		}
+		for street < a.Street && street < game.Showdown {
+			street++
+			board = hh.writeStreet(&b, street, board)
+		}
+		hh.writeAction(&b, a)
+	}
+	if !wroteHoleCards {
+		hh.writeHoleCards(&b)
+	}
+	// Boards run out after everyone was all-in are dealt without actions
+	for street < game.River && board < len(hh.Board) {
+		street++
+		board = hh.writeStreet(&b, street, board)
+	}
+
+	hh.writeSummary(&b)
+	return b.String()
+}
+
+func (hh *HandHistory) writeHoleCards(b *strings.Builder) {
+	b.WriteString("*** HOLE CARDS ***\n")
+	for _, s := range hh.Seats {
+		if len(s.HoleCards) == 0 || (hh.Hero != "" && s.Name != hh.Hero) {
+			continue
+		}
+		fmt.Fprintf(b, "Dealt to %s %s\n", s.Name, formatCards(s.HoleCards))
+	}
+}
+
+// writeStreet writes a street header and returns how many board cards have
+// been dealt so far
+func (hh *HandHistory) writeStreet(b *strings.Builder, street game.Street, dealt int) int {
+	switch street {
+	case game.Flop:
+		if len(hh.Board) >= 3 {
+			fmt.Fprintf(b, "*** FLOP *** %s\n", formatCards(hh.Board[:3]))
+			return 3
+		}
+	case game.Turn:
+		if len(hh.Board) >= 4 {
+			fmt.Fprintf(b, "*** TURN *** %s %s\n", formatCards(hh.Board[:3]), formatCards(hh.Board[3:4]))
+			return 4
+		}
+	case game.River:
+		if len(hh.Board) >= 5 {
+			fmt.Fprintf(b, "*** RIVER *** %s %s\n", formatCards(hh.Board[:4]), formatCards(hh.Board[4:5]))
+			return 5
+		}
+	case game.Showdown:
+		b.WriteString("*** SHOW DOWN ***\n")
+	}
+	return dealt
+}
+
+// ActionLine renders a single action the way it appears in the hand history
+func (hh *HandHistory) ActionLine(a Action) string {
+	var b strings.Builder
+	hh.writeAction(&b, a)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (hh *HandHistory) writeAction(b *strings.Builder, a Action) {
+	amt := hh.FormatAmount
+	allIn := ""
+	if a.AllIn {
+		allIn = " and is all-in"
+	}
+
+	switch a.Type {
+	case PostSmallBlind, PostBigBlind, PostAnte, Call, Bet:
+		fmt.Fprintf(b, "%s: %s %s%s\n", a.Player, a.Type, amt(a.Amount), allIn)
+	case Raise:
+		fmt.Fprintf(b, "%s: raises %s to %s%s\n", a.Player, amt(a.Amount), amt(a.To), allIn)
+	case Fold, Check:
+		fmt.Fprintf(b, "%s: %s\n", a.Player, a.Type)
+	case Uncalled:
+		fmt.Fprintf(b, "Uncalled bet (%s) returned to %s\n", amt(a.Amount), a.Player)
+	case Show:
+		fmt.Fprintf(b, "%s: shows %s", a.Player, formatCards(a.Cards))
+		if a.Description != "" {
+			fmt.Fprintf(b, " (%s)", a.Description)
+		}
+		b.WriteString("\n")
+	case Muck:
+		b.WriteString(a.Player + ": mucks hand\n")
+	case Collect:
+		fmt.Fprintf(b, "%s collected %s from %s\n", a.Player, amt(a.Amount), hh.potName(a.Pot))
+	}
+}
+
+func (hh *HandHistory) hasSidePots() bool {
+	for _, a := range hh.Actions {
+		if a.Type == Collect && a.Pot > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (hh *HandHistory) potName(pot int) string {
+	if !hh.hasSidePots() {
+		return "pot"
+	}
+	if pot == 0 {
+		return "main pot"
+	}
+	return fmt.Sprintf("side pot-%d", pot)
+}
+
+func (hh *HandHistory) writeSummary(b *strings.Builder) {
+	amt := hh.FormatAmount
+	b.WriteString("*** SUMMARY ***\n")
+
+	fmt.Fprintf(b, "Total pot %s", amt(hh.TotalPot))
+	if hh.hasSidePots() {
+		var pots []int64
+		for _, a := range hh.Actions {
+			if a.Type != Collect {
+				continue
+			}
+			for len(pots) <= a.Pot {
+				pots = append(pots, 0)
+			}
+			pots[a.Pot] += a.Amount
+		}
+		for i, p := range pots {
+			if i == 0 {
+				fmt.Fprintf(b, " Main pot %s.", amt(p))
+			} else {
+				fmt.Fprintf(b, " Side pot-%d %s.", i, amt(p))
+			}
+		}
+	}
+	fmt.Fprintf(b, " | Rake %s\n", amt(hh.Rake))
+
+	if len(hh.Board) > 0 {
+		fmt.Fprintf(b, "Board %s\n", formatCards(hh.Board))
+	}
+
+	for _, s := range hh.Seats {
+		fmt.Fprintf(b, "Seat %d: %s", s.Number, s.Name)
+		if s.Number == hh.ButtonSeat {
+			b.WriteString(" (button)")
+		}
+		for _, a := range hh.Actions {
+			if a.Player != s.Name {
+				continue
+			}
+			if a.Type == PostSmallBlind {
+				b.WriteString(" (small blind)")
+			} else if a.Type == PostBigBlind {
+				b.WriteString(" (big blind)")
+			}
+		}
+		if outcome := hh.outcome(s.Name); outcome != "" {
+			b.WriteString(" " + outcome)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// outcome describes how a player's hand ended for the summary
+func (hh *HandHistory) outcome(name string) string {
+	var won int64
+	var shown, folded *Action
+	mucked := false
+	invested := false
+	for i, a := range hh.Actions {
+		if a.Player != name {
+			continue
+		}
+		switch a.Type {
+		case Collect:
+			won += a.Amount
+		case Show:
+			shown = &hh.Actions[i]
+		case Fold:
+			folded = &hh.Actions[i]
+		case Muck:
+			mucked = true
+		case Call, Bet, Raise, PostSmallBlind, PostBigBlind:
+			invested = true
+		}
+	}
+
+	switch {
+	case folded != nil && folded.Street == game.Preflop && !invested:
+		return "folded before Flop (didn't bet)"
+	case folded != nil && folded.Street == game.Preflop:
+		return "folded before Flop"
+	case folded != nil:
+		return "folded on the " + folded.Street.String()
+	case shown != nil && won > 0:
+		return fmt.Sprintf("showed %s and won (%s) with %s", formatCards(shown.Cards), hh.FormatAmount(won), shown.Description)
+	case shown != nil:
+		return fmt.Sprintf("showed %s and lost with %s", formatCards(shown.Cards), shown.Description)
+	case mucked:
+		return "mucked"
+	case won > 0:
+		return fmt.Sprintf("collected (%s)", hh.FormatAmount(won))
+	}
+	return ""
+}
+
+// FormatAmount prints an amount the way the site does: whole currency units
+// without decimals, everything else with cents
+func (hh *HandHistory) FormatAmount(v int64) string {
+	if hh.Currency == "" {
+		return strconv.FormatInt(v, 10)
+	}
+	symbol := currencySymbols[hh.Currency]
+	if v%100 == 0 {
+		return fmt.Sprintf("%s%d", symbol, v/100)
+	}
+	return fmt.Sprintf("%s%d.%02d", symbol, v/100, v%100)
+}
+
+// formatCards converts backend cards ("HA") to the bracketed site notation
+// ("[Ah Kd]")
+func formatCards(cards []string) string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = ToSiteCard(c)
+	}
+	return "[" + strings.Join(out, " ") + "]"
+}
+
+// ToSiteCard converts a backend card such as "HA" to site notation "Ah"
+func ToSiteCard(card string) string {
+	if len(card) != 2 {
+		return card
+	}
+	return strings.ToUpper(card[1:2]) + strings.ToLower(card[0:1])
+}
+
+// FromSiteCard converts a site card such as "Ah" (or "10h") to backend
+// notation "HA"
+func FromSiteCard(card string) (string, error) {
+	if len(card) == 3 && card[:2] == "10" {
+		card = "T" + card[2:]
+	}
+	if len(card) != 2 {
+		return "", fmt.Errorf("invalid card: %s", card)
+	}
+	rank := strings.ToUpper(card[0:1])
+	suit := strings.ToUpper(card[1:2])
+	if !strings.Contains("23456789TJQKA", rank) || !strings.Contains("HDCS", suit) {
+		return "", fmt.Errorf("invalid card: %s", card)
+	}
+	return suit + rank, nil
+}
@@ -0,0 +1,201 @@
+package handhistory
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/game"
+)
+
+func sampleHand() *HandHistory {
+	return &HandHistory{
+		HandID:     "230984710924",
+		Game:       "Hold'em",
+		Limit:      "No Limit",
+		Currency:   "USD",
+		SmallBlind: 5,
+		BigBlind:   10,
+		Time:       time.Date(2024, 1, 15, 20, 30, 0, 0, time.UTC),
+		TableName:  "Alpha II",
+		MaxSeats:   6,
+		ButtonSeat: 1,
+		Hero:       "Bob",
+		Seats: []Seat{
+			{Number: 1, Name: "Alice", Stack: 1000, HoleCards: []string{"HA", "DA"}},
+			{Number: 2, Name: "Bob", Stack: 250, HoleCards: []string{"SK", "CK"}},
+			{Number: 4, Name: "Carol", Stack: 1000, HoleCards: []string{"H7", "H2"}},
+		},
+		Actions: []Action{
+			{Street: game.Preflop, Player: "Bob", Type: PostSmallBlind, Amount: 5},
+			{Street: game.Preflop, Player: "Carol", Type: PostBigBlind, Amount: 10},
+			{Street: game.Preflop, Player: "Alice", Type: Raise, Amount: 20, To: 30},
+			{Street: game.Preflop, Player: "Bob", Type: Raise, Amount: 220, To: 250, AllIn: true},
+			{Street: game.Preflop, Player: "Carol", Type: Fold},
+			{Street: game.Preflop, Player: "Alice", Type: Call, Amount: 220},
+			{Street: game.Showdown, Player: "Bob", Type: Show, Cards: []string{"SK", "CK"}, Description: "Pair of Kings"},
+			{Street: game.Showdown, Player: "Alice", Type: Show, Cards: []string{"HA", "DA"}, Description: "Pair of Aces"},
+			{Street: game.Showdown, Player: "Alice", Type: Collect, Amount: 510},
+		},
+		Board:    []string{"C2", "D7", "S9", "HJ", "C3"},
+		TotalPot: 510,
+	}
+}
+
+func TestFormat(t *testing.T) {
+	expected := `PokerStars Hand #230984710924:  Hold'em No Limit ($0.05/$0.10 USD) - 2024/01/15 15:30:00 ET
+Table 'Alpha II' 6-max Seat #1 is the button
+Seat 1: Alice ($10 in chips)
+Seat 2: Bob ($2.50 in chips)
+Seat 4: Carol ($10 in chips)
+Bob: posts small blind $0.05
+Carol: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to Bob [Ks Kc]
+Alice: raises $0.20 to $0.30
+Bob: raises $2.20 to $2.50 and is all-in
+Carol: folds
+Alice: calls $2.20
+*** FLOP *** [2c 7d 9s]
+*** TURN *** [2c 7d 9s] [Jh]
+*** RIVER *** [2c 7d 9s Jh] [3c]
+*** SHOW DOWN ***
+Bob: shows [Ks Kc] (Pair of Kings)
+Alice: shows [Ah Ad] (Pair of Aces)
+Alice collected $5.10 from pot
+*** SUMMARY ***
+Total pot $5.10 | Rake $0
+Board [2c 7d 9s Jh 3c]
+Seat 1: Alice (button) showed [Ah Ad] and won ($5.10) with Pair of Aces
+Seat 2: Bob (small blind) showed [Ks Kc] and lost with Pair of Kings
+Seat 4: Carol (big blind) folded before Flop
+`
+
+	if got := Format(sampleHand()); got != expected {
+		t.Errorf("Unexpected hand history:\n%s\nExpected:\n%s", got, expected)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	hh := sampleHand()
+	hh.Hero = ""
+
+	// Carol folded, so nobody sees her cards; the others showed down
+	hidden := Format(hh.Redact(""))
+	if strings.Contains(hidden, "Dealt to") || strings.Contains(hidden, "7h 2h") {
+		t.Errorf("Expected no hole cards dealt, got:\n%s", hidden)
+	}
+	if !strings.Contains(hidden, "Bob: shows [Ks Kc]") || !strings.Contains(hidden, "Alice: shows [Ah Ad]") {
+		t.Errorf("Expected the cards shown down, got:\n%s", hidden)
+	}
+
+	carol := Format(hh.Redact("Carol"))
+	if !strings.Contains(carol, "Dealt to Carol [7h 2h]\nAlice: raises") {
+		t.Errorf("Expected only Carol's own cards dealt, got:\n%s", carol)
+	}
+	if len(hh.Seats[2].HoleCards) != 2 {
+		t.Error("Expected the hand itself to keep every hole card")
+	}
+}
+
+func TestFormatTournamentAndSidePots(t *testing.T) {
+	hh := sampleHand()
+	hh.Currency = ""
+	hh.Tournament = &Tournament{ID: "3712345678", BuyIn: "$10+$1 USD", Level: "IV"}
+	hh.Actions[len(hh.Actions)-1] = Action{Street: game.Showdown, Player: "Alice", Type: Collect, Amount: 400}
+	hh.Actions = append(hh.Actions, Action{Street: game.Showdown, Player: "Alice", Type: Collect, Amount: 110, Pot: 1})
+
+	text := Format(hh)
+	for _, line := range []string{
+		"PokerStars Hand #230984710924: Tournament #3712345678, $10+$1 USD Hold'em No Limit - Level IV (5/10) - 2024/01/15 15:30:00 ET",
+		"Alice collected 400 from main pot",
+		"Alice collected 110 from side pot-1",
+		"Total pot 510 Main pot 400. Side pot-1 110. | Rake 0",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	table := game.NewTable("Beta", game.TableConfig{MaxSeats: 6, SmallBlind: 1, BigBlind: 2, Rand: rand.New(rand.NewSource(3))})
+	var hands []*HandHistory
+	NewRecorder(table, func(hh *HandHistory) { hands = append(hands, hh) })
+
+	table.Sit("a", "Alice", 0, 100)
+	table.Sit("b", "Bob", 2, 50)
+	table.Sit("c", "Carol", 4, 200)
+
+	table.StartHand()
+	// Button on Alice, blinds on Bob and Carol
+	table.Act("a", game.Action{Type: game.Raise, Amount: 6})
+	table.Act("b", game.Action{Type: game.Raise, Amount: 50})
+	table.Act("c", game.Action{Type: game.Fold})
+	table.Act("a", game.Action{Type: game.Call})
+
+	if len(hands) != 1 {
+		t.Fatalf("Expected 1 recorded hand, got %d", len(hands))
+	}
+	hh := hands[0]
+	if hh.ButtonSeat != 1 || len(hh.Seats) != 3 || len(hh.Board) != 5 {
+		t.Fatalf("Unexpected hand: button %d, %d seats, board %v", hh.ButtonSeat, len(hh.Seats), hh.Board)
+	}
+
+	text := Format(hh)
+	for _, line := range []string{
+		"Table 'Beta' 6-max Seat #1 is the button",
+		"Bob: posts small blind 1",
+		"Carol: posts big blind 2",
+		"Alice: raises 4 to 6",
+		"Bob: raises 44 to 50 and is all-in",
+		"Alice: calls 44",
+		"Total pot 102 | Rake 0",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, text)
+		}
+	}
+	for _, s := range hh.Seats {
+		if !strings.Contains(text, "Dealt to "+s.Name+" "+formatCards(s.HoleCards)) {
+			t.Errorf("Expected hole cards for %s in:\n%s", s.Name, text)
+		}
+	}
+
+	var collected int64
+	for _, a := range hh.Actions {
+		if a.Type == Collect {
+			collected += a.Amount
+		}
+	}
+	if collected != 102 || hh.TotalPot != 102 {
+		t.Errorf("Expected 102 collected, got %d (total pot %d)", collected, hh.TotalPot)
+	}
+}
+
+func TestSiteCards(t *testing.T) {
+	tests := []struct {
+		site    string
+		backend string
+	}{
+		{"Ah", "HA"},
+		{"Td", "DT"},
+		{"10c", "CT"},
+		{"2s", "S2"},
+	}
+
+	for _, tt := range tests {
+		got, err := FromSiteCard(tt.site)
+		if err != nil || got != tt.backend {
+			t.Errorf("FromSiteCard(%q) = %q, %v; expected %q", tt.site, got, err, tt.backend)
+		}
+		if tt.site != "10c" && ToSiteCard(tt.backend) != tt.site {
+			t.Errorf("ToSiteCard(%q) = %q; expected %q", tt.backend, ToSiteCard(tt.backend), tt.site)
+		}
+	}
+
+	if _, err := FromSiteCard("Xx"); err == nil {
+		t.Errorf("Expected error for invalid card")
+	}
+}
@@ -0,0 +1,88 @@
+// Package health answers the liveness and readiness probes. The server is
+// ready once its self-test has passed, until it starts draining. A server
+// running as many simulations as it has capacity for stays ready, and turns
+// further simulations away one request at a time.
+package health
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"texas-holdem-backend/api"
+)
+
+// Checker holds the state the readiness probe reports
+type Checker struct {
+	selfTest func() error
+	capacity int
+	inFlight func() int
+
+	once     sync.Once
+	mu       sync.Mutex
+	tested   bool
+	testErr  error
+	draining atomic.Bool
+}
+
+// NewChecker returns a checker running selfTest, admitting simulations
+// while inFlight is under capacity
+func NewChecker(selfTest func() error, capacity int, inFlight func() int) *Checker {
+	return &Checker{selfTest: selfTest, capacity: capacity, inFlight: inFlight}
+}
+
+// Run runs the self-test, once; its result is kept for every later probe
+func (c *Checker) Run() {
+	c.once.Do(func() {
+		err := c.selfTest()
+		c.mu.Lock()
+		c.tested, c.testErr = true, err
+		c.mu.Unlock()
+	})
+}
+
+// Drain reports the server as not ready from now on, while it finishes the
+// requests it has
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Readiness reports whether the server should be sent traffic, and if not
+// why
+func (c *Checker) Readiness() api.ReadinessResponse {
+	c.mu.Lock()
+	tested, testErr := c.tested, c.testErr
+	c.mu.Unlock()
+
+	r := api.ReadinessResponse{
+		SelfTest:           "passed",
+		Draining:           c.draining.Load(),
+		Simulations:        c.inFlight(),
+		SimulationCapacity: c.capacity,
+	}
+	switch {
+	case !tested:
+		r.SelfTest = "running"
+		r.Reasons = append(r.Reasons, "self-test still running")
+	case testErr != nil:
+		r.SelfTest = "failed"
+		r.Reasons = append(r.Reasons, "self-test failed: "+testErr.Error())
+	}
+	if r.Draining {
+		r.Reasons = append(r.Reasons, "draining")
+	}
+	r.Ready = len(r.Reasons) == 0
+	return r
+}
+
+// Admit returns an unavailable error if the server already runs as many
+// simulations as it has capacity for, and nil if another may start
+func (c *Checker) Admit() error {
+	if n := c.inFlight(); n >= c.capacity {
+		return &api.Error{
+			Code:    api.CodeUnavailable,
+			Message: fmt.Sprintf("Server busy: %d simulations running, at the capacity of %d", n, c.capacity),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"texas-holdem-backend/api"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestHandCasesMatchSpreadsheet checks that the embedded cases are the ones
+// kept at the root of the repository
+func TestHandCasesMatchSpreadsheet(t *testing.T) {
+	data, err := os.ReadFile("../../Texas HoldEm Hand comparison test cases.xlsx - Sheet1.csv")
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	if string(data) != handCases {
+		t.Error("Expected hand_cases.csv to be a copy of the spreadsheet at the root of the repository")
+	}
+}
+
+func TestCheckHandCasesFails(t *testing.T) {
+	saved := handCases
+	defer func() { handCases = saved }()
+
+	handCases = strings.Replace(saved, "hand 1 > hand 2", "hand 2 > hand 1", 1)
+	if err := checkHandCases(); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Expected the first case to fail, got %v", err)
+	}
+	handCases = "no,cases\n"
+	if err := checkHandCases(); err == nil {
+		t.Error("Expected an error without cases")
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		selfTest func() error
+		run      bool
+		drain    bool
+		inFlight int
+		status   string
+		reasons  []string
+	}{
+		{"ready", func() error { return nil }, true, false, 4, "passed", nil},
+		{"self-test running", func() error { return nil }, false, false, 0, "running", []string{"self-test still running"}},
+		{"self-test failed", func() error { return errors.New("broken") }, true, false, 0, "failed", []string{"self-test failed: broken"}},
+		{"draining", func() error { return nil }, true, true, 0, "passed", []string{"draining"}},
+		{"over capacity", func() error { return nil }, true, false, 5, "passed", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(tt.selfTest, 4, func() int { return tt.inFlight })
+			if tt.run {
+				c.Run()
+			}
+			if tt.drain {
+				c.Drain()
+			}
+			r := c.Readiness()
+			if r.Ready != (len(tt.reasons) == 0) {
+				t.Errorf("Expected ready %v, got %v", len(tt.reasons) == 0, r.Ready)
+			}
+			if r.SelfTest != tt.status {
+				t.Errorf("Expected self-test %s, got %s", tt.status, r.SelfTest)
+			}
+			if strings.Join(r.Reasons, "; ") != strings.Join(tt.reasons, "; ") {
+				t.Errorf("Expected reasons %q, got %q", tt.reasons, r.Reasons)
+			}
+		})
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	inFlight := 3
+	c := NewChecker(func() error { return nil }, 4, func() int { return inFlight })
+	if err := c.Admit(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	inFlight = 4
+	var e *api.Error
+	if err := c.Admit(); !errors.As(err, &e) || e.Code != api.CodeUnavailable {
+		t.Errorf("Expected an unavailable error at capacity, got %v", err)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	runs := 0
+	c := NewChecker(func() error { runs++; return nil }, 1, func() int { return 0 })
+	c.Run()
+	c.Run()
+	if runs != 1 {
+		t.Errorf("Expected the self-test to run once, got %d", runs)
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"texas-holdem-backend/poker"
+)
+
+// handCases is the hand comparison spreadsheet kept at the root of the
+// repository, which the poker tests also check against
+//
+//go:embed hand_cases.csv
+var handCases string
+
+// The seeded simulation plays aces against one random hand, preflop, and
+// must come within simulationTolerance of the embedded preflop table
+const (
+	simulationSeed      = 1
+	simulationHands     = 1000
+	simulationTolerance = 0.05
+)
+
+// SelfTest checks the evaluator against the hand comparison cases and a
+// seeded Monte Carlo simulation against the preflop table
+func SelfTest() error {
+	if err := checkHandCases(); err != nil {
+		return fmt.Errorf("hand cases: %w", err)
+	}
+	if err := checkSimulation(); err != nil {
+		return fmt.Errorf("simulation: %w", err)
+	}
+	return nil
+}
+
+// cards splits a spreadsheet cell into cards, "–" standing for none
+func cards(cell string) []string {
+	var out []string
+	for _, c := range strings.Fields(cell) {
+		if c != "–" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// checkHandCases compares the five-card hands of every case and, when the
+// hole cards are given, the best hands of the players' seven cards
+func checkHandCases() error {
+	records, err := csv.NewReader(strings.NewReader(handCases)).ReadAll()
+	if err != nil {
+		return err
+	}
+	checked := 0
+	for i, record := range records {
+		if len(record) < 7 {
+			continue
+		}
+		var want string
+		switch {
+		case strings.Contains(record[6], "hand 1 > hand 2"):
+			want = "Player 1"
+		case strings.Contains(record[6], "hand 2 > hand 1"):
+			want = "Player 2"
+		case strings.Contains(record[6], "hand 1 = hand 2"):
+			want = "Tie"
+		default:
+			continue
+		}
+
+		comparisons := [][2][]string{{cards(record[3]), cards(record[5])}}
+		board, hole1, hole2 := cards(record[1]), cards(record[2]), cards(record[4])
+		if len(board) == 5 && len(hole1) == 2 && len(hole2) == 2 {
+			comparisons = append(comparisons, [2][]string{append(hole1, board...), append(hole2, board...)})
+		}
+		for _, hands := range comparisons {
+			if got := poker.CompareHands(hands[0], hands[1]); got != want {
+				return fmt.Errorf("row %d, %s against %s: expected %s, got %s", i+1, hands[0], hands[1], want, got)
+			}
+		}
+		checked++
+	}
+	if checked == 0 {
+		return errors.New("no cases found")
+	}
+	return nil
+}
+
+// checkSimulation runs the seeded simulation twice, expecting the same odds
+// each time and the table's equity within the tolerance
+func checkSimulation() error {
+	hole := []string{"HA", "SA"}
+	win, tie, _ := poker.MonteCarloSeeded(hole, nil, 2, simulationHands, simulationSeed)
+	again, _, _ := poker.MonteCarloSeeded(hole, nil, 2, simulationHands, simulationSeed)
+	if win != again {
+		return fmt.Errorf("seed %d gave %v, then %v", simulationSeed, win, again)
+	}
+
+	odds, err := poker.PreflopVsRandom("AA", 1)
+	if err != nil {
+		return err
+	}
+	got, want := win+tie/2, odds.Win+odds.Tie/2
+	if math.Abs(got-want) > simulationTolerance {
+		return fmt.Errorf("aces have %.3f equity against a random hand, expected %.3f", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRecorder(t *testing.T) {
+	r := mux.NewRouter()
+	var status int
+	var bytes int64
+	var route string
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rec := NewRecorder(w)
+			next.ServeHTTP(rec, req)
+			status, bytes, route = rec.Status, rec.Bytes, Route(req)
+		})
+	})
+	r.HandleFunc("/tables/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+		http.NewResponseController(w).Flush()
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tables/main", nil))
+	if status != http.StatusTeapot || bytes != 5 || route != "/tables/{id}" {
+		t.Errorf("Expected 418, 5 bytes and the route template, got %d, %d and %q", status, bytes, route)
+	}
+	if got := Route(httptest.NewRequest("GET", "/", nil)); got != "unmatched" {
+		t.Errorf("Expected unmatched, got %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package logging sets up the server's structured JSON logs and the access
+// log middleware, which gives every request an ID and a logger carrying it.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"texas-holdem-backend/httpx"
+)
+
+// RequestIDHeader carries the request ID. An ID sent by the client, or by a
+// proxy in front of the server, is kept; otherwise one is generated. Either
+// way it is echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestID bounds the length of an ID accepted from a client
+const maxRequestID = 128
+
+// New returns a logger writing JSON lines to w at the given level: "debug",
+// "info", "warn" or "error", with "info" when it is empty
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("log level %q: %w", level, err)
+		}
+	}
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})), nil
+}
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// FromContext returns the logger of the request the context belongs to, or
+// the default logger outside a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// RequestID returns the ID of the request the context belongs to
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so that a
+// client cannot forge log lines or headers through it
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// milliseconds gives a duration in milliseconds to the microsecond
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// countingReader counts the bytes of a request body the handler reads
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// AccessLog returns middleware that logs every request once it is served:
+// its method, route template, path, status, latency, request ID and the
+// bytes read and written. Requests to the quiet routes, such as probes, are
+// logged at debug level; server errors at error level.
+func AccessLog(quiet ...string) func(http.Handler) http.Handler {
+	isQuiet := make(map[string]bool, len(quiet))
+	for _, route := range quiet {
+		isQuiet[route] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			logger := slog.Default().With("requestId", id)
+			ctx := context.WithValue(r.Context(), loggerKey, logger)
+			r = r.WithContext(context.WithValue(ctx, requestIDKey, id))
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = body
+			}
+			rec := httpx.NewRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			route := httpx.Route(r)
+			level := slog.LevelInfo
+			if rec.Status >= 500 {
+				level = slog.LevelError
+			} else if isQuiet[route] {
+				level = slog.LevelDebug
+			}
+			logger.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.Status),
+				slog.Float64("latencyMs", milliseconds(time.Since(start))),
+				slog.Int64("bytesIn", body.n),
+				slog.Int64("bytesOut", rec.Bytes),
+				slog.String("remote", r.RemoteAddr),
+			)
+		})
+	}
+}
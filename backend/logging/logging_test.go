@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		level   string
+		debug   bool
+		info    bool
+		wantErr bool
+	}{
+		{"", false, true, false},
+		{"debug", true, true, false},
+		{"WARN", false, false, false},
+		{"loud", false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			logger, err := New(io.Discard, tt.level)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := logger.Enabled(context.Background(), slog.LevelDebug); got != tt.debug {
+				t.Errorf("Expected debug enabled %v, got %v", tt.debug, got)
+			}
+			if got := logger.Enabled(context.Background(), slog.LevelInfo); got != tt.info {
+				t.Errorf("Expected info enabled %v, got %v", tt.info, got)
+			}
+		})
+	}
+}
+
+// serve sends a request through a router logging to a buffer, and returns
+// the response and the lines logged
+func serve(t *testing.T, req *http.Request) (*httptest.ResponseRecorder, []map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "info")
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logger)
+
+	r := mux.NewRouter()
+	r.Use(AccessLog("/health"))
+	r.HandleFunc("/players/{name}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		FromContext(r.Context()).Info("handled", "requestIdSeen", RequestID(r.Context()))
+		w.Write(append(body, '!'))
+	})
+	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines = append(lines, entry)
+	}
+	return rec, lines
+}
+
+func TestAccessLog(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/players/alice", strings.NewReader("hello"))
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec, lines := serve(t, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("Expected the request ID to be echoed, got %q", got)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(lines))
+	}
+	if lines[0]["requestIdSeen"] != "abc-123" || lines[0]["requestId"] != "abc-123" {
+		t.Errorf("Expected the handler's logger to carry the request ID, got %v", lines[0])
+	}
+	want := map[string]interface{}{
+		"msg":       "request",
+		"level":     "INFO",
+		"requestId": "abc-123",
+		"method":    "PUT",
+		"route":     "/players/{name}",
+		"path":      "/players/alice",
+		"status":    float64(200),
+		"bytesIn":   float64(5),
+		"bytesOut":  float64(6),
+	}
+	for k, v := range want {
+		if lines[1][k] != v {
+			t.Errorf("Expected %s %v, got %v", k, v, lines[1][k])
+		}
+	}
+	if _, ok := lines[1]["latencyMs"].(float64); !ok {
+		t.Errorf("Expected a latency, got %v", lines[1]["latencyMs"])
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"missing", ""},
+		{"with spaces", "a b"},
+		{"with a newline", "a\nb"},
+		{"too long", strings.Repeat("a", maxRequestID+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/players/bob", nil)
+			req.Header.Set(RequestIDHeader, tt.id)
+			rec, lines := serve(t, req)
+			got := rec.Header().Get(RequestIDHeader)
+			if len(got) != 16 {
+				t.Errorf("Expected a generated request ID, got %q", got)
+			}
+			if len(lines) != 2 || lines[1]["requestId"] != got {
+				t.Errorf("Expected the access log to carry %q, got %v", got, lines)
+			}
+		})
+	}
+}
+
+func TestQuietRoutes(t *testing.T) {
+	_, lines := serve(t, httptest.NewRequest("GET", "/health", nil))
+	if len(lines) != 0 {
+		t.Errorf("Expected no lines at info level, got %v", lines)
+	}
+}
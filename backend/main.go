@@ -1,58 +1,127 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"texas-holdem-backend/api"
+	"texas-holdem-backend/ev"
+	"texas-holdem-backend/handhistory"
+	"texas-holdem-backend/health"
+	"texas-holdem-backend/logging"
+	"texas-holdem-backend/metrics"
+	"texas-holdem-backend/openapi"
 	"texas-holdem-backend/poker"
+	"texas-holdem-backend/replay"
+	"texas-holdem-backend/rpc"
+	"texas-holdem-backend/service"
+	"texas-holdem-backend/stats"
+	"texas-holdem-backend/store"
+	"texas-holdem-backend/ws"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
-type EvaluateHandRequest struct {
-	HoleCards []string `json:"holeCards"`
-	BoardCards []string `json:"boardCards"`
-}
-
-type EvaluateHandResponse struct {
-	BestHand string `json:"bestHand"`
-	HandValue string `json:"handValue"`
-	Cards []string `json:"cards"`
+func enableCORS(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+logging.RequestIDHeader)
+	w.Header().Set("Access-Control-Expose-Headers", logging.RequestIDHeader)
 }
 
-type CompareHandsRequest struct {
-	Player1HoleCards []string `json:"player1HoleCards"`
-	Player2HoleCards []string `json:"player2HoleCards"`
-	CommunityCards []string `json:"communityCards"`
+// writeError sends err as a JSON api.Error. Errors that are not one already
+// take their code from the status; a body over its limit is always a 413.
+func writeError(w http.ResponseWriter, status int, err error) {
+	e := asAPIError(err, status)
+	if e.Code == api.CodeTooLarge {
+		status = http.StatusRequestEntityTooLarge
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(e)
 }
 
-type CompareHandsResponse struct {
-	Player1 EvaluateHandResponse `json:"player1"`
-	Player2 EvaluateHandResponse `json:"player2"`
-	Winner string `json:"winner"`
+func asAPIError(err error, status int) *api.Error {
+	var e *api.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	code := api.CodeInternal
+	switch status {
+	case http.StatusBadRequest:
+		code = api.CodeInvalidRequest
+	case http.StatusForbidden:
+		code = api.CodeForbidden
+	case http.StatusNotFound:
+		code = api.CodeNotFound
+	case http.StatusMethodNotAllowed:
+		code = api.CodeMethodNotAllowed
+	case http.StatusRequestEntityTooLarge:
+		code = api.CodeTooLarge
+	case http.StatusUnprocessableEntity:
+		code = api.CodeUnprocessable
+	case http.StatusServiceUnavailable:
+		code = api.CodeUnavailable
+	}
+	return &api.Error{Code: code, Message: err.Error()}
 }
 
-type MonteCarloRequest struct {
-	HoleCards []string `json:"holeCards"`
-	BoardCards []string `json:"boardCards"`
-	NumPlayers int `json:"numPlayers"`
-	NumSimulations int `json:"numSimulations"`
+// tooLargeError reports a body cut off by limitBody
+func tooLargeError(message string, err *http.MaxBytesError) *api.Error {
+	return &api.Error{
+		Code:    api.CodeTooLarge,
+		Message: message,
+		Details: map[string]interface{}{"max": err.Limit},
+	}
 }
 
-type MonteCarloResponse struct {
-	WinProbability float64 `json:"winProbability"`
-	TieProbability float64 `json:"tieProbability"`
-	LossProbability float64 `json:"lossProbability"`
-	Simulations int `json:"simulations"`
+// decodeRequest reads a JSON request body into v. A value of the wrong type
+// is reported against its field.
+func decodeRequest(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return tooLargeError(fmt.Sprintf("Invalid request: %v", err), tooLarge)
+	}
+	e := &api.Error{Code: api.CodeInvalidRequest, Message: fmt.Sprintf("Invalid request: %v", err)}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e.Field = typeErr.Field
+	}
+	return e
 }
 
-func enableCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// writeUploadError reports a hand history upload that could not be read
+func writeUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, tooLargeError("Invalid upload: "+err.Error(), tooLarge))
+		return
+	}
+	writeError(w, http.StatusBadRequest, errors.New("Invalid upload: "+err.Error()))
 }
 
 func handleEvaluateHand(w http.ResponseWriter, r *http.Request) {
@@ -62,26 +131,19 @@ func handleEvaluateHand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req EvaluateHandRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	var req api.EvaluateHandRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if len(req.HoleCards) != 2 || len(req.BoardCards) != 5 {
-		http.Error(w, "Must provide exactly 2 hole cards and 5 board cards", http.StatusBadRequest)
+	// Where the hand stands among every holding on this board
+	response, err := service.Evaluate(req, true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	allCards := append(req.HoleCards, req.BoardCards...)
-	hand, value, bestCards := poker.EvaluateHand(allCards)
-
-	response := EvaluateHandResponse{
-		BestHand: hand,
-		HandValue: value,
-		Cards: bestCards,
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -93,98 +155,932 @@ func handleCompareHands(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req CompareHandsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	var req api.CompareHandsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := service.Compare(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if len(req.Player1HoleCards) != 2 {
-		http.Error(w, "Player 1: Must provide exactly 2 hole cards", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// MaxBatchItems is the most items a JSON batch request may hold. Larger
+// inputs can be streamed as newline-delimited JSON.
+const MaxBatchItems = 5000
+
+// batchChunk is how many streamed items are processed before their
+// results are written
+const batchChunk = 256
+
+// processBatch runs every item through process, spread over parallel
+// workers, keeping the results in input order
+func processBatch(items []json.RawMessage, first, parallel int, process func(json.RawMessage) (interface{}, error)) []api.BatchResult {
+	results := make([]api.BatchResult, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i].Index = first + i
+				if v, err := process(items[i]); err != nil {
+					results[i].Error = asAPIError(err, http.StatusBadRequest)
+				} else {
+					results[i].Result = v
+				}
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// handleBatch applies process to every item of a JSON array and reports
+// each item's result or error. With ?parallel=N items are processed on up to
+// N cores. A body sent as application/x-ndjson, one item per line, is
+// answered the same way, streaming one result per line.
+func handleBatch(process func(json.RawMessage) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		parallel := 1
+		if p := r.URL.Query().Get("parallel"); p != "" {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 1 {
+				writeError(w, http.StatusBadRequest, api.InvalidField("parallel", "parallel must be a positive number"))
+				return
+			}
+			parallel = n
+		}
+		if max := runtime.GOMAXPROCS(0); parallel > max {
+			parallel = max
+		}
+
+		if strings.Contains(r.Header.Get("Content-Type"), "ndjson") {
+			streamBatch(w, r.Body, parallel, process)
+			return
+		}
+
+		var items []json.RawMessage
+		if err := decodeRequest(r, &items); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(items) > MaxBatchItems {
+			writeError(w, http.StatusRequestEntityTooLarge, &api.Error{
+				Code:    api.CodeTooLarge,
+				Message: fmt.Sprintf("At most %d items per batch, send more as application/x-ndjson", MaxBatchItems),
+				Details: map[string]interface{}{"max": MaxBatchItems, "items": len(items)},
+			})
+			return
+		}
+
+		response := api.BatchResponse{Results: processBatch(items, 0, parallel, process)}
+		for _, res := range response.Results {
+			if res.Error != nil {
+				response.Failed++
+			} else {
+				response.Succeeded++
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// streamBatch reads items one per line and writes their results as they are
+// done, a chunk at a time. Blank lines are skipped but still numbered.
+func streamBatch(w http.ResponseWriter, body io.Reader, parallel int, process func(json.RawMessage) (interface{}, error)) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	index := 0
+	var chunk []json.RawMessage
+	var indexes []int
+	flush := func() {
+		for i, res := range processBatch(chunk, 0, parallel, process) {
+			res.Index = indexes[i]
+			enc.Encode(res)
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		chunk, indexes = chunk[:0], indexes[:0]
+	}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) > 0 {
+			chunk = append(chunk, append(json.RawMessage{}, line...))
+			indexes = append(indexes, index)
+		}
+		index++
+		if len(chunk) == batchChunk {
+			flush()
+		}
+	}
+	if len(chunk) > 0 {
+		flush()
+	}
+	if err := scanner.Err(); err != nil {
+		enc.Encode(api.BatchResult{Index: index, Error: &api.Error{Code: api.CodeInvalidRequest, Message: fmt.Sprintf("Invalid request: %v", err)}})
+	}
+}
+
+func evaluateItem(raw json.RawMessage) (interface{}, error) {
+	var req api.EvaluateHandRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, &api.Error{Code: api.CodeInvalidRequest, Message: fmt.Sprintf("Invalid item: %v", err)}
+	}
+	return service.Evaluate(req, false)
+}
+
+func compareItem(raw json.RawMessage) (interface{}, error) {
+	var req api.CompareHandsRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, &api.Error{Code: api.CodeInvalidRequest, Message: fmt.Sprintf("Invalid item: %v", err)}
+	}
+	return service.Compare(req)
+}
+
+// monteCarloChunk is how many hands are simulated between checks that the
+// request is still wanted
+const monteCarloChunk = 1000
+
+// simulate runs a Monte Carlo simulation a chunk at a time, stopping early
+// with the context's error once the client goes away or the server gives up
+// draining. Every chunk draws from the request's generator, so a seed
+// reproduces the whole run.
+func simulate(ctx context.Context, req api.MonteCarloRequest) (win, tie, loss float64, err error) {
+	sim := metrics.StartSimulation("montecarlo")
+	done := 0
+	defer func() { sim.Done(done) }()
+
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for done < req.NumSimulations {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, 0, err
+		}
+		n := monteCarloChunk
+		if left := req.NumSimulations - done; left < n {
+			n = left
+		}
+		w, t, l := poker.MonteCarloRand(req.HoleCards, req.BoardCards, req.NumPlayers, n, rng)
+		win, tie, loss = win+w*float64(n), tie+t*float64(n), loss+l*float64(n)
+		done += n
+	}
+	total := float64(req.NumSimulations)
+	return win / total, tie / total, loss / total, nil
+}
+
+// simulationMargin is the widest margin of error of probabilities
+// estimated from n simulations
+func simulationMargin(n int, probs ...float64) float64 {
+	margin := 0.0
+	for _, p := range probs {
+		margin = math.Max(margin, poker.SamplingMargin(p, n))
+	}
+	return margin
+}
+
+// handleMonteCarlo runs a simulation and keeps the result as a job that can
+// be fetched again by its ID. Simulations beyond the checker's capacity are
+// turned away with 503, to be retried.
+func handleMonteCarlo(st store.Store, checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var req api.MonteCarloRequest
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// The cards are checked before the preflop table is consulted, so an
+		// invalid or repeated card is a 400 on every path
+		req, err := service.CheckMonteCarlo(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// Preflop odds against random hands come straight from the table
+		if len(req.BoardCards) == 0 {
+			if class, err := poker.HandClass(req.HoleCards); err == nil {
+				if odds, err := poker.PreflopVsRandom(class, req.NumPlayers-1); err == nil {
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(api.MonteCarloResponse{
+						WinProbability:  odds.Win,
+						TieProbability:  odds.Tie,
+						LossProbability: odds.Loss(),
+						Margin:          odds.Margin,
+						Source:          "table",
+					})
+					return
+				}
+			}
+		}
+
+		if err := checker.Admit(); err != nil {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+
+		created := time.Now()
+		winProb, tieProb, lossProb, err := simulate(r.Context(), req)
+		logging.FromContext(r.Context()).Info("simulation",
+			"kind", "montecarlo",
+			"numPlayers", req.NumPlayers,
+			"numSimulations", req.NumSimulations,
+			"boardCards", len(req.BoardCards),
+			"durationMs", time.Since(created).Milliseconds(),
+			"cancelled", err != nil,
+		)
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, fmt.Errorf("Simulation cancelled: %v", err))
+			return
+		}
+
+		response := api.MonteCarloResponse{
+			WinProbability:  winProb,
+			TieProbability:  tieProb,
+			LossProbability: lossProb,
+			Margin:          simulationMargin(req.NumSimulations, winProb, tieProb, lossProb),
+			Simulations:     req.NumSimulations,
+			Source:          "simulation",
+		}
+
+		job := store.Job{ID: store.NewID(), Kind: "montecarlo", Created: created}
+		job.Request, _ = json.Marshal(req)
+		job.Result, _ = json.Marshal(response)
+		job.Finished = time.Now()
+		if err := st.SaveJob(job); err != nil {
+			logging.FromContext(r.Context()).Error("saving simulation job", "error", err)
+		} else {
+			response.JobID = job.ID
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// tableViewer returns the name of the player seated at the room with the
+// seat token given as the token query parameter, or "" without one. Hole
+// cards dealt at the live tables are only shown to the player they were
+// dealt to.
+func tableViewer(room *ws.Room, r *http.Request) (string, error) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return "", nil
+	}
+	name, ok := room.PlayerName(token)
+	if !ok {
+		return "", &api.Error{Code: api.CodeForbidden, Field: "token", Message: "Not the token of a player seated at this table"}
+	}
+	return name, nil
+}
+
+// handleTableHands downloads the hand histories of a live table in the
+// PokerStars text format. With a {hand} route variable only that hand is
+// returned. Hole cards are left out unless they were shown down, except
+// for the player whose seat token is given.
+func handleTableHands(hub *ws.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		vars := mux.Vars(r)
+
+		room, ok := hub.Lookup(vars["id"])
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("Table not found"))
+			return
+		}
+
+		viewer, err := tableViewer(room, r)
+		if err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+
+		hands := room.Hands()
+		filename := fmt.Sprintf("%s-session.txt", vars["id"])
+		if handVar, ok := vars["hand"]; ok {
+			number, err := strconv.Atoi(handVar)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, api.InvalidField("hand", "Invalid hand number"))
+				return
+			}
+			var found *handhistory.HandHistory
+			for _, hh := range hands {
+				if hh.HandID == handVar {
+					found = hh
+				}
+			}
+			if found == nil {
+				writeError(w, http.StatusNotFound, fmt.Errorf("Hand %d not found", number))
+				return
+			}
+			hands = []*handhistory.HandHistory{found}
+			filename = fmt.Sprintf("%s-hand-%d.txt", vars["id"], number)
+		}
+
+		for i, hh := range hands {
+			hands[i] = hh.Redact(viewer)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		handhistory.WriteSession(w, hands)
+	}
+}
+
+// maxUploadMemory is how much of a multipart upload is held in memory; the
+// rest of its files are buffered on disk
+const maxUploadMemory = 32 << 20
+
+// readUploads calls fn for every uploaded file. Files are sent either as the
+// raw request body or as multipart form files named "files".
+func readUploads(r *http.Request, fn func(name string, body io.Reader) error) error {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return fn("", r.Body)
+	}
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		return err
+	}
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		return fmt.Errorf("no files uploaded")
+	}
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		err = fn(fh.Filename, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+	}
+	return nil
+}
+
+// handleImportHands parses uploaded PokerStars hand history files, verifies
+// their showdowns and adds new hands to the library, the statistics and
+// storage
+func handleImportHands(library *handhistory.Library, aggregator *stats.Aggregator, recorder *store.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		resp := api.ImportResponse{Hands: []api.ImportedHand{}, Errors: []api.ImportError{}}
+		err := readUploads(r, func(name string, body io.Reader) error {
+			hands, errs, err := handhistory.Parse(body)
+			if err != nil {
+				return err
+			}
+			for _, e := range errs {
+				resp.Errors = append(resp.Errors, importError(name, e))
+			}
+			for _, hh := range hands {
+				summary := api.ImportedHand{
+					HandID:   hh.HandID,
+					Site:     hh.Site,
+					Table:    hh.TableName,
+					Players:  len(hh.Seats),
+					TotalPot: hh.TotalPot,
+					Verified: true,
+				}
+				if err := handhistory.VerifyShowdown(hh); err != nil {
+					summary.Verified = false
+					summary.VerifyError = err.Error()
+				}
+				if library.Add(hh) {
+					aggregator.Add(hh)
+					if _, err := recorder.Record(hh); err != nil {
+						logging.FromContext(r.Context()).Error("saving hand", "hand", hh.HandID, "error", err)
+					}
+					resp.Imported++
+				} else {
+					resp.Duplicates++
+				}
+				resp.Hands = append(resp.Hands, summary)
+			}
+			return nil
+		})
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// importError reports a hand history of an uploaded file that failed to parse
+func importError(file string, e handhistory.ParseError) api.ImportError {
+	return api.ImportError{File: file, Line: e.Line, HandID: e.HandID, Message: e.Message}
+}
+
+// handleConvertOHH converts uploaded PokerStars hand histories to Open Hand
+// History JSON. By default the response is a JSON object with the converted
+// hands and any parse errors; with ?format=ohh it is an .ohh file download.
+func handleConvertOHH(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if len(req.Player2HoleCards) != 2 {
-		http.Error(w, "Player 2: Must provide exactly 2 hole cards", http.StatusBadRequest)
+	resp := api.ConvertOHHResponse{Hands: []json.RawMessage{}, Errors: []api.ImportError{}}
+	var hands []*handhistory.HandHistory
+	err := readUploads(r, func(name string, body io.Reader) error {
+		parsed, errs, err := handhistory.Parse(body)
+		if err != nil {
+			return err
+		}
+		for _, e := range errs {
+			resp.Errors = append(resp.Errors, importError(name, e))
+		}
+		hands = append(hands, parsed...)
+		return nil
+	})
+	if err != nil {
+		writeUploadError(w, err)
 		return
 	}
 
-	if len(req.CommunityCards) != 5 {
-		http.Error(w, "Must provide exactly 5 community cards", http.StatusBadRequest)
+	if r.URL.Query().Get("format") == "ohh" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="hands.ohh"`)
+		handhistory.WriteOHH(w, hands)
 		return
 	}
 
-	// Each player's 7 cards = 2 hole cards + 5 community cards
-	allCards1 := append(req.Player1HoleCards, req.CommunityCards...)
-	hand1, value1, bestCards1 := poker.EvaluateHand(allCards1)
+	for _, hh := range hands {
+		file, err := json.Marshal(handhistory.OHHFile{OHH: handhistory.ToOHH(hh)})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Hands = append(resp.Hands, file)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// maxReplaySimulations caps the Monte Carlo runs a replay request may ask for
+const maxReplaySimulations = 10000
+
+// handleReplay returns the replay timeline of a hand, either one recorded at
+// a live table ({id}), with hole cards hidden as for handleTableHands, or one
+// imported from a hand history file ({site}).
+// The simulations query parameter sets the Monte Carlo runs per equity and
+// equity=false skips equities altogether.
+func handleReplay(hub *ws.Hub, library *handhistory.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		vars := mux.Vars(r)
+
+		var hand *handhistory.HandHistory
+		if tableID, ok := vars["id"]; ok {
+			room, ok := hub.Lookup(tableID)
+			if !ok {
+				writeError(w, http.StatusNotFound, errors.New("Table not found"))
+				return
+			}
+			viewer, err := tableViewer(room, r)
+			if err != nil {
+				writeError(w, http.StatusForbidden, err)
+				return
+			}
+			for _, hh := range room.Hands() {
+				if hh.HandID == vars["hand"] {
+					hand = hh.Redact(viewer)
+				}
+			}
+		} else {
+			hand, _ = library.Get(vars["site"], vars["hand"])
+		}
+		if hand == nil {
+			writeError(w, http.StatusNotFound, errors.New("Hand not found"))
+			return
+		}
+
+		opts := replay.Options{NoEquity: r.URL.Query().Get("equity") == "false"}
+		if sims := r.URL.Query().Get("simulations"); sims != "" {
+			n, err := strconv.Atoi(sims)
+			if err != nil || n < 1 || n > maxReplaySimulations {
+				writeError(w, http.StatusBadRequest, api.InvalidField("simulations", "simulations must be between 1 and %d", maxReplaySimulations).WithLimits(1, maxReplaySimulations))
+				return
+			}
+			opts.Simulations = n
+		}
+
+		timeline, err := replay.Build(hand, opts)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(timeline)
+	}
+}
+
+// handleSessionEV reports a player's actual winnings against their all-in
+// adjusted winnings, hand by hand. The hands are those played at a table
+// or, when posted, the uploaded PokerStars hand history files. The player
+// query parameter defaults to the hero of the uploaded hands.
+func handleSessionEV(hub *ws.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var hands []*handhistory.HandHistory
+		if tableID, ok := mux.Vars(r)["id"]; ok {
+			room, ok := hub.Lookup(tableID)
+			if !ok {
+				writeError(w, http.StatusNotFound, errors.New("Table not found"))
+				return
+			}
+			hands = room.Hands()
+		} else {
+			err := readUploads(r, func(name string, body io.Reader) error {
+				parsed, _, err := handhistory.Parse(body)
+				hands = append(hands, parsed...)
+				return err
+			})
+			if err != nil {
+				writeUploadError(w, err)
+				return
+			}
+		}
+
+		player := r.URL.Query().Get("player")
+		for _, hh := range hands {
+			if player == "" {
+				player = hh.Hero
+			}
+		}
+		if player == "" {
+			writeError(w, http.StatusBadRequest, api.InvalidField("player", "player is required"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ev.Session(hands, player))
+	}
+}
 
-	allCards2 := append(req.Player2HoleCards, req.CommunityCards...)
-	hand2, value2, bestCards2 := poker.EvaluateHand(allCards2)
+// handleStats returns per-player HUD statistics. The player, stakes
+// (e.g. "$0.25/$0.50") and position query parameters narrow the hands
+// counted, as do from and to dates in YYYY-MM-DD form.
+func handleStats(aggregator *stats.Aggregator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		q := r.URL.Query()
 
-	winner := poker.CompareHands(allCards1, allCards2)
+		filter := stats.Filter{
+			Player:   q.Get("player"),
+			Stakes:   q.Get("stakes"),
+			Position: strings.ToUpper(q.Get("position")),
+		}
+		for param, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+			if v := q.Get(param); v != "" {
+				t, err := time.Parse("2006-01-02", v)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, api.InvalidField(param, "Invalid %s date, expected YYYY-MM-DD", param))
+					return
+				}
+				*dst = t
+			}
+		}
 
-	response := CompareHandsResponse{
-		Player1: EvaluateHandResponse{
-			BestHand: hand1,
-			HandValue: value1,
-			Cards: bestCards1,
-		},
-		Player2: EvaluateHandResponse{
-			BestHand: hand2,
-			HandValue: value2,
-			Cards: bestCards2,
-		},
-		Winner: winner,
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.StatsResponse{
+			TotalHands: aggregator.Hands(),
+			Players:    playerStats(aggregator.Query(filter)),
+		})
 	}
+}
+
+// playerStats copies HUD statistics into the response
+func playerStats(players []stats.PlayerStats) []api.PlayerStats {
+	out := make([]api.PlayerStats, len(players))
+	for i, p := range players {
+		out[i] = api.PlayerStats{
+			ID: p.ID, Player: p.Player, Hands: p.Hands,
+			VPIP: api.Stat(p.VPIP), PFR: api.Stat(p.PFR), ThreeBet: api.Stat(p.ThreeBet), FoldTo3Bet: api.Stat(p.FoldTo3Bet),
+			CBet: api.Stat(p.CBet), Aggression: api.Stat(p.Aggression), WTSD: api.Stat(p.WTSD), WonAtSD: api.Stat(p.WonAtSD),
+			SawFlop: api.Stat(p.SawFlop),
+		}
+	}
+	return out
+}
+
+// handlePreflop looks up the precomputed all-in odds of a starting hand,
+// given as a class such as "AKs" or as two cards, heads up against another
+// hand (vs) or against a number of random opponents (opponents, default 1)
+func handlePreflop(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	q := r.URL.Query()
+
+	class := func(s string) (string, error) {
+		if cards := strings.Split(s, ","); len(cards) == 2 {
+			return poker.HandClass(cards)
+		}
+		i, err := poker.HandClassIndex(s)
+		return poker.HandClassName(i), err
+	}
+	hand, err := class(q.Get("hand"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := api.PreflopResponse{Hand: hand}
+	var odds poker.Odds
+	if vs := q.Get("vs"); vs != "" {
+		if resp.Villain, err = class(vs); err == nil {
+			odds, err = poker.PreflopHeadsUp(resp.Hand, resp.Villain)
+		}
+	} else {
+		resp.Opponents = 1
+		if n := q.Get("opponents"); n != "" {
+			resp.Opponents, err = strconv.Atoi(n)
+		}
+		if err == nil {
+			odds, err = poker.PreflopVsRandom(resp.Hand, resp.Opponents)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp.Odds, resp.Loss = api.Odds(odds), odds.Loss()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(resp)
 }
 
-func handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
+// handleOuts lists the cards that improve a hand on the flop or turn,
+// flagging those that also help the opponents' hands when they are given
+func handleOuts(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	var req MonteCarloRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	var req api.OutsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if len(req.HoleCards) != 2 {
-		http.Error(w, "Must provide exactly 2 hole cards", http.StatusBadRequest)
+	analysis, err := poker.AnalyzeOuts(req.HoleCards, req.BoardCards, req.Opponents)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if len(req.BoardCards) > 5 {
-		http.Error(w, "Board cards cannot exceed 5 cards", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
+
+// handlePotential computes hand strength and potential (HS, PPOT, NPOT and
+// EHS) against an optional opponent range
+func handlePotential(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if req.NumPlayers < 2 || req.NumPlayers > 10 {
-		http.Error(w, "Number of players must be between 2 and 10", http.StatusBadRequest)
+	var req api.PotentialRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
+	if req.Opponents == 0 {
+		req.Opponents = 1
+	}
 
-	if req.NumSimulations < 100 || req.NumSimulations > 100000 {
-		http.Error(w, "Number of simulations must be between 100 and 100000", http.StatusBadRequest)
+	var hands *poker.HandRange
+	if req.Range != "" {
+		var err error
+		if hands, err = poker.ParseRange(req.Range); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	potential, err := poker.HandPotential(req.HoleCards, req.BoardCards, hands, req.Opponents)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	winProb, tieProb, lossProb := poker.MonteCarloSimulation(req.HoleCards, req.BoardCards, req.NumPlayers, req.NumSimulations)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(potential)
+}
 
-	response := MonteCarloResponse{
-		WinProbability: winProb,
-		TieProbability: tieProb,
-		LossProbability: lossProb,
-		Simulations: req.NumSimulations,
+// handleBoardTexture describes a board given as comma-separated cards,
+// e.g. /api/board?cards=HK,H7,D2
+func handleBoardTexture(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	texture, err := poker.ClassifyBoard(strings.Split(r.URL.Query().Get("cards"), ","))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(texture)
+}
+
+// handleSessions lists the stored sessions of every table, or of the one
+// given by the table query parameter, or returns a single session by ID
+func handleSessions(st store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		var result interface{}
+		var err error
+		if id, ok := mux.Vars(r)["id"]; ok {
+			result, err = st.Session(id)
+		} else {
+			result, err = st.Sessions(r.URL.Query().Get("table"))
+		}
+		writeStored(w, result, err)
+	}
+}
+
+// handlePlayers lists player profiles, returns one by ID or, on PUT,
+// updates its display name and notes
+func handlePlayers(st store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		id, ok := mux.Vars(r)["id"]
+		if !ok {
+			players, err := st.Players()
+			writeStored(w, players, err)
+			return
+		}
+		player, err := st.Player(id)
+		if err != nil || r.Method != "PUT" {
+			writeStored(w, player, err)
+			return
+		}
+
+		var req api.PlayerUpdateRequest
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		player.DisplayName = req.DisplayName
+		player.Notes = req.Notes
+		writeStored(w, player, st.SavePlayer(player))
+	}
+}
+
+// handleJob returns a stored simulation job
+func handleJob(st store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		job, err := st.Job(mux.Vars(r)["id"])
+		writeStored(w, job, err)
+	}
+}
+
+// writeStored encodes a record read from storage, or the error reading it
+func writeStored(w http.ResponseWriter, v interface{}, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, errors.New("Not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// openStore opens the database named by DB_PATH, poker.db by default, or
+// keeps everything in memory when DB_PATH is "memory"
+func openStore() (store.Store, error) {
+	path := os.Getenv("DB_PATH")
+	if path == "memory" {
+		slog.Info("keeping data in memory only")
+		return store.NewMemory(), nil
+	}
+	if path == "" {
+		path = "poker.db"
+	}
+	slog.Info("opening database", "path", path)
+	return store.OpenBolt(path)
+}
+
+// apiRouter registers every API route twice: under api.Prefix and, marked
+// deprecated, under the unversioned /api path it had before. Routes go on the
+// root router rather than a subrouter, which would answer a wrong method
+// with 404 instead of 405. Request bodies are limited to maxBody, or to
+// maxUpload for the routes taking hand histories or batches.
+type apiRouter struct {
+	r         *mux.Router
+	maxBody   int64
+	maxUpload int64
+}
+
+func newAPIRouter(r *mux.Router, cfg serverConfig) *apiRouter {
+	return &apiRouter{r: r, maxBody: cfg.MaxBodyBytes, maxUpload: cfg.MaxUploadBytes}
+}
+
+func (a *apiRouter) handle(path string, h http.HandlerFunc, methods ...string) {
+	a.route(path, limitBody(a.maxBody, h), methods)
+}
+
+func (a *apiRouter) upload(path string, h http.HandlerFunc, methods ...string) {
+	a.route(path, limitBody(a.maxUpload, h), methods)
+}
+
+func (a *apiRouter) route(path string, h http.Handler, methods []string) {
+	a.r.Handle(api.Prefix+path, h).Methods(methods...)
+	a.r.Handle("/api"+path, deprecatedAPI(h)).Methods(methods...)
+}
+
+// deprecatedAPI marks responses on the unversioned paths as deprecated and
+// links to the same route under api.Prefix
+func deprecatedAPI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", api.Prefix, strings.TrimPrefix(r.URL.Path, "/api")))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func handleNotFound(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	writeError(w, http.StatusNotFound, fmt.Errorf("No route for %s", r.URL.Path))
+}
+
+func handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed on %s", r.Method, r.URL.Path))
+}
+
+// handleOpenAPI serves the OpenAPI document, built once from the api types
+func handleOpenAPI() http.HandlerFunc {
+	spec, err := json.Marshal(openapi.Document())
+	if err != nil {
+		fatal("building OpenAPI document", err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -193,19 +1089,209 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
 }
 
-func main() {
+// handleLivez answers the liveness probe: the process is up and serving
+func handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "alive"})
+}
+
+// handleReadyz answers the readiness probe, with 503 when the server should
+// not be sent traffic
+func handleReadyz(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		readiness := checker.Readiness()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !readiness.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(readiness)
+	}
+}
+
+// newRouter sets up every route, rebuilding the statistics and the library
+// of imported hands from storage
+func newRouter(st store.Store, cfg serverConfig, checker *health.Checker) (*mux.Router, error) {
 	r := mux.NewRouter()
+	// Every request is logged, then counted; the logging and metrics
+	// packages know nothing of each other. Probes and scrapes are only
+	// logged at debug level.
+	accessLog := logging.AccessLog("/health", "/livez", "/readyz", "/metrics")
+	observe := func(next http.Handler) http.Handler {
+		return accessLog(metrics.Middleware(next))
+	}
+	r.Use(observe)
+	// Requests that match no route are logged and counted too, under "unmatched"
+	r.NotFoundHandler = observe(http.HandlerFunc(handleNotFound))
+	r.MethodNotAllowedHandler = observe(http.HandlerFunc(handleMethodNotAllowed))
 
 	r.HandleFunc("/health", handleHealth).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/evaluate", handleEvaluateHand).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/compare", handleCompareHands).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/montecarlo", handleMonteCarlo).Methods("POST", "OPTIONS")
+	r.HandleFunc("/livez", handleLivez).Methods("GET")
+	r.HandleFunc("/readyz", handleReadyz(checker)).Methods("GET")
+	r.HandleFunc("/openapi.json", handleOpenAPI()).Methods("GET")
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	routes := newAPIRouter(r, cfg)
+	routes.handle("/evaluate", handleEvaluateHand, "POST", "OPTIONS")
+	routes.handle("/compare", handleCompareHands, "POST", "OPTIONS")
+	routes.upload("/evaluate/batch", handleBatch(evaluateItem), "POST", "OPTIONS")
+	routes.upload("/compare/batch", handleBatch(compareItem), "POST", "OPTIONS")
+
+	recorder := store.NewRecorder(st)
+	aggregator := stats.NewAggregator()
+	library := handhistory.NewLibrary()
+	stored, err := st.Hands()
+	if err != nil {
+		return nil, fmt.Errorf("loading hands: %w", err)
+	}
+	for _, hh := range stored {
+		if hh.Site != "" {
+			library.Add(hh)
+		}
+		aggregator.Add(hh)
+	}
+	slog.Info("loaded stored hands", "hands", len(stored))
+
+	routes.handle("/montecarlo", handleMonteCarlo(st, checker), "POST", "OPTIONS")
+	routes.handle("/preflop", handlePreflop, "GET")
+	routes.handle("/outs", handleOuts, "POST", "OPTIONS")
+	routes.handle("/potential", handlePotential, "POST", "OPTIONS")
+	routes.handle("/board", handleBoardTexture, "GET")
+	routes.handle("/jobs/{id}", handleJob(st), "GET")
+	routes.handle("/sessions", handleSessions(st), "GET")
+	routes.handle("/sessions/{id}", handleSessions(st), "GET")
+	routes.handle("/players", handlePlayers(st), "GET")
+	routes.handle("/players/{id}", handlePlayers(st), "GET", "PUT", "OPTIONS")
+
+	hubConfig := ws.DefaultConfig()
+	hubConfig.OnHand = func(hh *handhistory.HandHistory) {
+		aggregator.Add(hh)
+		if _, err := recorder.Record(hh); err != nil {
+			slog.Error("saving hand", "hand", hh.HandID, "table", hh.TableName, "error", err)
+		}
+	}
+	hubConfig.LoadHands = func(tableID string) []*handhistory.HandHistory {
+		hands, err := st.TableHands(tableID)
+		if err != nil {
+			slog.Error("loading hands", "table", tableID, "error", err)
+		}
+		return hands
+	}
+	hub := ws.NewHub(hubConfig)
+	r.Handle("/ws", hub).Methods("GET")
+	routes.handle("/tables/{id}/hands", handleTableHands(hub), "GET")
+	routes.handle("/tables/{id}/hands/{hand}", handleTableHands(hub), "GET")
+
+	routes.upload("/hands/import", handleImportHands(library, aggregator, recorder), "POST", "OPTIONS")
+	routes.upload("/hands/convert/ohh", handleConvertOHH, "POST", "OPTIONS")
+	routes.handle("/hands/{site}/{hand}/replay", handleReplay(hub, library), "GET")
+	routes.handle("/tables/{id}/hands/{hand}/replay", handleReplay(hub, library), "GET")
+	routes.handle("/stats", handleStats(aggregator), "GET")
+	routes.handle("/tables/{id}/ev", handleSessionEV(hub), "GET")
+	routes.upload("/hands/ev", handleSessionEV(hub), "POST", "OPTIONS")
+	return r, nil
+}
+
+// fatal logs an error the server cannot start without and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func main() {
+	// LOG_LEVEL is debug, info, warn or error; info by default
+	logger, err := logging.New(os.Stdout, os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		fatal("setting up logging", err)
+	}
+	// Dependencies using the log package write through it at info level
+	slog.SetDefault(logger)
+
+	cfg, err := loadServerConfig(os.Getenv)
+	if err != nil {
+		fatal("reading server configuration", err)
+	}
+
+	st, err := openStore()
+	if err != nil {
+		fatal("opening storage", err)
+	}
+	defer st.Close()
+
+	// The self-test runs in the background; the server is not ready until
+	// it has passed
+	checker := health.NewChecker(health.SelfTest, cfg.SimulationCapacity, metrics.SimulationsInFlight)
+	go func() {
+		checker.Run()
+		if r := checker.Readiness(); r.SelfTest == "failed" {
+			slog.Error("self-test failed", "reasons", r.Reasons)
+		} else {
+			slog.Info("self-test passed")
+		}
+	}()
+
+	r, err := newRouter(st, cfg, checker)
+	if err != nil {
+		fatal("setting up routes", err)
+	}
+
+	// SIGTERM, sent by Kubernetes before it stops a pod, starts draining
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		checker.Drain()
+	}()
+
+	// The gRPC service runs next to the HTTP API on its own port
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	lis, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		fatal("listening for gRPC", err)
+	}
+	// Messages are protobuf; GRPC_CODEC=json serves JSON instead, for
+	// clients without the generated stubs
+	var grpcOpts []grpc.ServerOption
+	switch codec := os.Getenv("GRPC_CODEC"); codec {
+	case "", "proto":
+	case "json":
+		grpcOpts = append(grpcOpts, grpc.ForceServerCodec(rpc.JSONCodec{}))
+	default:
+		fatal("configuring gRPC", fmt.Errorf("GRPC_CODEC must be proto or json, got %q", codec))
+	}
+	slog.Info("starting gRPC server", "port", grpcPort)
+	grpcServer := rpc.NewServer(checker.Admit, grpcOpts...)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			fatal("serving gRPC", err)
+		}
+	}()
+	// gRPC calls drain with the HTTP requests, and are cancelled with them
+	// once the grace period is over
+	grpcDrained := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		time.Sleep(cfg.DrainDelay)
+		timer := time.AfterFunc(cfg.ShutdownGrace, grpcServer.Stop)
+		grpcServer.GracefulStop()
+		timer.Stop()
+		close(grpcDrained)
+	}()
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	httpLis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		fatal("listening for HTTP", err)
+	}
 
-	log.Printf("Starting server on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	slog.Info("starting server", "port", port)
+	if err := serve(ctx, newServer(cfg, r), httpLis, cfg); err != nil {
+		fatal("serving HTTP", err)
+	}
+	<-grpcDrained
 }
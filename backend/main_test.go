@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/api"
+	"texas-holdem-backend/health"
+	"texas-holdem-backend/metrics"
+	"texas-holdem-backend/openapi"
+	"texas-holdem-backend/store"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+func testRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	return configuredRouter(t, defaultServerConfig())
+}
+
+// configuredRouter sets up the routes with cfg and a checker whose
+// self-test has passed
+func configuredRouter(t *testing.T, cfg serverConfig) *mux.Router {
+	t.Helper()
+	checker := health.NewChecker(func() error { return nil }, cfg.SimulationCapacity, metrics.SimulationsInFlight)
+	checker.Run()
+	r, err := newRouter(store.NewMemory(), cfg, checker)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return r
+}
+
+// TestOpenAPIRoutes checks that the document lists exactly the routes the
+// server registers
+func TestOpenAPIRoutes(t *testing.T) {
+	routed := map[string]bool{}
+	err := testRouter(t).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		// The unversioned aliases are deprecated and the websocket is not
+		// described by OpenAPI
+		if path == "/ws" || strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, api.Prefix+"/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, m := range methods {
+			if m != "OPTIONS" {
+				routed[m+" "+path] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range openapi.Document()["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, route := range sortedKeys(routed) {
+		if !documented[route] {
+			t.Errorf("Route %s is missing from the OpenAPI document", route)
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !routed[route] {
+			t.Errorf("OpenAPI document lists %s, which is not routed", route)
+		}
+	}
+}
+
+// TestOpenAPIExamples sends each operation's example to the server and
+// checks that the response matches the documented schema
+func TestOpenAPIExamples(t *testing.T) {
+	r := testRouter(t)
+	spec := roundTrip(t, openapi.Document())
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	// Queries for the GET routes that need no stored data
+	queries := map[string]string{
+		"GET /health":                    "",
+		"GET /openapi.json":              "",
+		"GET /livez":                     "",
+		"GET /readyz":                    "",
+		"GET " + api.Prefix + "/preflop": "?hand=AKs",
+		"GET " + api.Prefix + "/board":   "?cards=HK,H7,D2",
+		"GET " + api.Prefix + "/stats":   "",
+	}
+
+	for _, op := range openapi.Operations {
+		query, listed := queries[op.Method+" "+op.Path]
+		if op.Example == nil && !listed {
+			continue
+		}
+		t.Run(op.Method+" "+op.Path, func(t *testing.T) {
+			var body []byte
+			if op.Example != nil {
+				if reflect.TypeOf(op.Example) != reflect.TypeOf(op.Request) {
+					t.Fatalf("Expected an example of type %T, got %T", op.Request, op.Example)
+				}
+				body, _ = json.Marshal(op.Example)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(op.Method, op.Path+query, bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+			}
+
+			var got interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			item := spec["paths"].(map[string]interface{})[op.Path].(map[string]interface{})
+			response := item[strings.ToLower(op.Method)].(map[string]interface{})["responses"].(map[string]interface{})["200"]
+			schema := response.(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"]
+			if err := matchSchema(got, schema.(map[string]interface{}), schemas, "response"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	// Errors come back in the envelope the document describes
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", api.Prefix+"/evaluate", strings.NewReader(`{"holeCards": ["HA"]}`)))
+	var got interface{}
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if err := matchSchema(got, schemas["Error"].(map[string]interface{}), schemas, "error"); err != nil || rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a 400 error envelope, got %d %s (%v)", rec.Code, rec.Body, err)
+	}
+}
+
+// matchSchema reports the first part of v that the schema does not allow:
+// a property it does not list or a value of the wrong type
+func matchSchema(v interface{}, schema, schemas map[string]interface{}, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{})
+	}
+	if v == nil {
+		return nil
+	}
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", at, v)
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		extra, _ := schema["additionalProperties"].(map[string]interface{})
+		for name := range obj {
+			prop, ok := props[name].(map[string]interface{})
+			if !ok {
+				prop = extra
+			}
+			if prop == nil {
+				return fmt.Errorf("%s: property %q is not documented", at, name)
+			}
+			if err := matchSchema(obj[name], prop, schemas, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", at, v)
+		}
+		for i, item := range arr {
+			if err := matchSchema(item, schema["items"].(map[string]interface{}), schemas, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %T", at, v)
+		}
+	case "number", "integer":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected a number, got %T", at, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", at, v)
+		}
+	}
+	return nil
+}
+
+// roundTrip returns the document as a client would decode it
+func roundTrip(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestMonteCarlo checks that an answer says where it came from and how
+// far it can be off, and that cards are validated on both paths
+func TestMonteCarlo(t *testing.T) {
+	r := testRouter(t)
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		source string
+	}{
+		{"preflop from the table", `{"holeCards": ["HA", "DA"], "numPlayers": 3, "numSimulations": 100}`, http.StatusOK, "table"},
+		{"simulated", `{"holeCards": ["HA", "DA"], "boardCards": ["S7", "C2", "D9"], "numPlayers": 3, "numSimulations": 100}`, http.StatusOK, "simulation"},
+		{"preflop card dealt twice", `{"holeCards": ["HA", "ha"], "numPlayers": 3, "numSimulations": 100}`, http.StatusBadRequest, ""},
+		{"preflop invalid card", `{"holeCards": ["HA", "X1"], "numPlayers": 3, "numSimulations": 100}`, http.StatusBadRequest, ""},
+		{"board card dealt twice", `{"holeCards": ["HA", "DA"], "boardCards": ["DA", "C2", "D9"], "numPlayers": 3, "numSimulations": 100}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("POST", api.Prefix+"/montecarlo", strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp api.MonteCarloResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.Source != tt.source || resp.Margin <= 0 || resp.Margin > 0.1 {
+				t.Errorf("Expected a %s answer with its margin of error, got %+v", tt.source, resp)
+			}
+		})
+	}
+}
+
+// TestMetrics checks that requests are counted by route template, without
+// the cards they carry
+func TestMetrics(t *testing.T) {
+	r := testRouter(t)
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"POST", api.Prefix + "/evaluate", `{"holeCards": ["HA", "HK"], "boardCards": ["HQ", "HJ", "HT", "D2", "C3"]}`},
+		{"GET", api.Prefix + "/preflop?hand=AKs", ""},
+		{"POST", api.Prefix + "/montecarlo", `{"holeCards": ["HA", "HK"], "boardCards": ["HQ", "HJ", "D2"], "numPlayers": 2, "numSimulations": 100}`},
+		{"GET", "/players/HA", ""},
+	}
+	for _, req := range requests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`poker_http_requests_total{method="POST",route="/api/v1/evaluate",status="200"}`,
+		`poker_http_requests_total{method="GET",route="/api/v1/preflop",status="200"}`,
+		`poker_http_requests_total{method="GET",route="unmatched",status="404"}`,
+		`poker_simulation_iterations_total{kind="montecarlo"}`,
+		`poker_evaluator_calls_total{operation="evaluate"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the metrics to contain %s", want)
+		}
+	}
+	for _, card := range []string{"AKs", "HA", "HK"} {
+		if strings.Contains(body, card) {
+			t.Errorf("Expected no label to hold %s", card)
+		}
+	}
+}
+
+// TestTableHandsHideHoleCards plays a hand at a live table and checks who
+// gets to see which hole cards in its history
+func TestTableHandsHideHoleCards(t *testing.T) {
+	server := httptest.NewServer(testRouter(t))
+	defer server.Close()
+
+	type message struct {
+		Type  string `json:"type"`
+		Token string `json:"token"`
+		Event *struct {
+			Type string `json:"type"`
+		} `json:"event"`
+	}
+	sit := func(name string, seat int) (*websocket.Conn, string) {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+		if err != nil {
+			t.Fatalf("Failed to dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		conn.WriteJSON(map[string]interface{}{"type": "join", "tableId": "hidden"})
+		conn.WriteJSON(map[string]interface{}{"type": "sit", "seat": seat, "buyIn": 100, "name": name})
+		for {
+			var msg message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("Failed waiting to sit: %v", err)
+			}
+			if msg.Type == "seated" {
+				return conn, msg.Token
+			}
+		}
+	}
+	waitFor := func(conn *websocket.Conn, event string) {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			var msg message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("Failed waiting for %s: %v", event, err)
+			}
+			if msg.Type == "event" && msg.Event.Type == event {
+				return
+			}
+		}
+	}
+	alice, token := sit("Alice", 0)
+	bob, _ := sit("Bob", 1)
+
+	// Whoever is first to act folds; the other is told it is not their turn
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		waitFor(conn, "holeCards")
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		conn.WriteJSON(map[string]interface{}{"type": "action", "action": map[string]string{"type": "fold"}})
+	}
+	waitFor(alice, "handEnd")
+
+	hands := func(query string) (int, string) {
+		resp, err := http.Get(server.URL + api.Prefix + "/tables/hidden/hands/1" + query)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, body := hands(""); code != http.StatusOK || strings.Contains(body, "Dealt to") {
+		t.Errorf("Expected no hole cards without a token, got %d:\n%s", code, body)
+	}
+	if code, body := hands("?player=Bob"); code != http.StatusOK || strings.Contains(body, "Dealt to") {
+		t.Errorf("Expected a player name to reveal nothing, got %d:\n%s", code, body)
+	}
+	if code, body := hands("?token=" + token); code != http.StatusOK || !strings.Contains(body, "Dealt to Alice") || strings.Contains(body, "Dealt to Bob") {
+		t.Errorf("Expected Alice's cards only, got %d:\n%s", code, body)
+	}
+	if code, _ := hands("?token=guess"); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a wrong token, got %d", code)
+	}
+}
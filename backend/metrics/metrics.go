@@ -0,0 +1,155 @@
+// Package metrics holds the Prometheus metrics the server exports and the
+// HTTP middleware that records requests. Labels only ever carry route
+// templates, methods, status codes, simulation kinds and operation names,
+// never cards or other request data, so the number of series stays bounded.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"texas-holdem-backend/httpx"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "poker"
+
+// Registry holds every metric of the server, with the Go runtime and
+// process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	simulationsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "simulations_in_flight",
+		Help:      "Simulations currently running, by kind.",
+	}, []string{"kind"})
+
+	simulations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "simulations_total",
+		Help:      "Simulations finished, by kind.",
+	}, []string{"kind"})
+
+	// The rate of this counter is the number of boards simulated per second
+	simulationIterations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "simulation_iterations_total",
+		Help:      "Boards simulated or enumerated, by kind.",
+	}, []string{"kind"})
+
+	iterationsPerRequest = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "simulation_iterations",
+		Help:      "Boards simulated or enumerated per simulation, by kind.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 4),
+	}, []string{"kind"})
+
+	simulationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "simulation_duration_seconds",
+		Help:      "Time taken by simulations, by kind.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	}, []string{"kind"})
+
+	evaluations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evaluator_calls_total",
+		Help:      "Hands scored by the evaluator, by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests, requestDuration,
+		simulationsInFlight, simulations, simulationIterations, iterationsPerRequest, simulationDuration,
+		evaluations,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// running counts the simulations in flight, of every kind
+var running atomic.Int64
+
+// SimulationsInFlight returns the number of simulations running
+func SimulationsInFlight() int {
+	return int(running.Load())
+}
+
+// Simulation is a running simulation, counted as in flight until Done
+type Simulation struct {
+	kind  string
+	start time.Time
+}
+
+// StartSimulation records the start of a simulation of the given kind, such
+// as "montecarlo" or "equity"
+func StartSimulation(kind string) *Simulation {
+	running.Add(1)
+	simulationsInFlight.WithLabelValues(kind).Inc()
+	return &Simulation{kind: kind, start: time.Now()}
+}
+
+// Done records the end of the simulation and the number of boards it ran
+func (s *Simulation) Done(iterations int) {
+	running.Add(-1)
+	simulationsInFlight.WithLabelValues(s.kind).Dec()
+	simulations.WithLabelValues(s.kind).Inc()
+	simulationIterations.WithLabelValues(s.kind).Add(float64(iterations))
+	iterationsPerRequest.WithLabelValues(s.kind).Observe(float64(iterations))
+	simulationDuration.WithLabelValues(s.kind).Observe(time.Since(s.start).Seconds())
+}
+
+// Evaluated counts hands scored for an operation such as "evaluate"
+func Evaluated(operation string, hands int) {
+	evaluations.WithLabelValues(operation).Add(float64(hands))
+}
+
+// methods are the request methods given their own label value; anything
+// else a client sends is counted as "other"
+var methods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// Middleware counts and times the requests it serves, labelled with the
+// template of the mux route that matched, or "unmatched"
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := httpx.NewRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := httpx.Route(r)
+		method := r.Method
+		if !methods[method] {
+			method = "other"
+		}
+		status := strconv.Itoa(rec.Status)
+		requests.WithLabelValues(route, method, status).Inc()
+		requestDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+	})
+}
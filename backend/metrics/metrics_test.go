@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.NotFoundHandler = Middleware(http.NotFoundHandler())
+	r.MethodNotAllowedHandler = Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	r.HandleFunc("/cards/{card}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods("GET")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		status string
+	}{
+		{"route template", "GET", "/cards/HA", "/cards/{card}", "418"},
+		{"another card", "GET", "/cards/SK", "/cards/{card}", "418"},
+		{"unmatched", "GET", "/HA/KD", "unmatched", "404"},
+		{"wrong method", "POST", "/cards/HA", "unmatched", "405"},
+		{"unknown method", "BREW", "/cards/HA", "unmatched", "405"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if !methods[method] {
+				method = "other"
+			}
+			counter := requests.WithLabelValues(tt.route, method, tt.status)
+			before := testutil.ToFloat64(counter)
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("Expected 1 request counted, got %v", got)
+			}
+		})
+	}
+
+	// Cards never become label values
+	got, err := testutil.GatherAndCount(Registry, "poker_http_requests_total")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != 4 {
+		t.Errorf("Expected 4 series, got %d", got)
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	sim := StartSimulation("test")
+	if got := testutil.ToFloat64(simulationsInFlight.WithLabelValues("test")); got != 1 {
+		t.Errorf("Expected 1 simulation in flight, got %v", got)
+	}
+	if got := SimulationsInFlight(); got != 1 {
+		t.Errorf("Expected 1 simulation running, got %d", got)
+	}
+	sim.Done(2500)
+	StartSimulation("test").Done(500)
+
+	if got := testutil.ToFloat64(simulationsInFlight.WithLabelValues("test")); got != 0 {
+		t.Errorf("Expected no simulation in flight, got %v", got)
+	}
+	if got := testutil.ToFloat64(simulations.WithLabelValues("test")); got != 2 {
+		t.Errorf("Expected 2 simulations, got %v", got)
+	}
+	if got := testutil.ToFloat64(simulationIterations.WithLabelValues("test")); got != 3000 {
+		t.Errorf("Expected 3000 iterations, got %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Evaluated("test", 3)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{`poker_evaluator_calls_total{operation="test"} 3`, "go_goroutines", "process_"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the metrics to contain %q", want)
+		}
+	}
+}
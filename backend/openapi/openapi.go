@@ -0,0 +1,416 @@
+// Package openapi describes the HTTP API as an OpenAPI 3 document. It lives
+// apart from the api package, which clients import, because the responses
+// it documents come from every part of the server.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"texas-holdem-backend/api"
+	"texas-holdem-backend/ev"
+	"texas-holdem-backend/handhistory"
+	"texas-holdem-backend/poker"
+	"texas-holdem-backend/replay"
+	"texas-holdem-backend/store"
+)
+
+// Operation describes a route for the OpenAPI document. Request and
+// Response are zero values of the JSON body types, nil when there is no
+// body; other bodies are described by their content type alone.
+type Operation struct {
+	Method       string
+	Path         string // With {name} path parameters, as routed
+	Summary      string
+	Query        []Param
+	Request      interface{}
+	RequestType  string // Content type of a body that is not JSON
+	Example      interface{}
+	Response     interface{}
+	ResponseType string // Content type of a response that is not JSON
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Type        string // "string" or "integer"
+	Description string
+	Required    bool
+}
+
+// Operations lists every route of the API. The OpenAPI document is built
+// from it, and the server's tests check it against the routes registered.
+var Operations = []Operation{
+	{Method: "GET", Path: "/health", Summary: "Report that the server is up", Response: map[string]string{}},
+	{Method: "GET", Path: "/livez", Summary: "Liveness probe: the process is serving", Response: map[string]string{}},
+	{
+		Method: "GET", Path: "/readyz",
+		Summary:  "Readiness probe: 503 until the self-test passes and while draining",
+		Response: api.ReadinessResponse{},
+	},
+	{Method: "GET", Path: "/openapi.json", Summary: "This document", Response: map[string]interface{}{}},
+	{Method: "GET", Path: "/metrics", Summary: "Prometheus metrics", ResponseType: "text/plain"},
+	{
+		Method: "POST", Path: api.Prefix + "/evaluate", Summary: "Find the best hand of 2 hole cards and 5 board cards",
+		Request:  api.EvaluateHandRequest{},
+		Example:  api.EvaluateHandRequest{HoleCards: []string{"HA", "HK"}, BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"}},
+		Response: api.EvaluateHandResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/compare", Summary: "Compare two players' hands on the same community cards",
+		Request: api.CompareHandsRequest{},
+		Example: api.CompareHandsRequest{
+			Player1HoleCards: []string{"HA", "HK"},
+			Player2HoleCards: []string{"SA", "SK"},
+			CommunityCards:   []string{"HQ", "HJ", "HT", "D2", "C3"},
+		},
+		Response: api.CompareHandsResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/evaluate/batch",
+		Summary: "Evaluate many hands; send application/x-ndjson, one request per line, to stream",
+		Query:   []Param{{Name: "parallel", Type: "integer", Description: "Cores to spread the items over"}},
+		Request: []api.EvaluateHandRequest{},
+		Example: []api.EvaluateHandRequest{
+			{HoleCards: []string{"HA", "HK"}, BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"}},
+			{HoleCards: []string{"S7", "D2"}, BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"}},
+		},
+		Response: api.BatchResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/compare/batch",
+		Summary: "Compare many pairs of hands; send application/x-ndjson, one request per line, to stream",
+		Query:   []Param{{Name: "parallel", Type: "integer", Description: "Cores to spread the items over"}},
+		Request: []api.CompareHandsRequest{},
+		Example: []api.CompareHandsRequest{{
+			Player1HoleCards: []string{"HA", "HK"},
+			Player2HoleCards: []string{"SA", "SK"},
+			CommunityCards:   []string{"HQ", "HJ", "HT", "D2", "C3"},
+		}},
+		Response: api.BatchResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/montecarlo", Summary: "Simulate a hand's odds against random opponents",
+		Request:  api.MonteCarloRequest{},
+		Example:  api.MonteCarloRequest{HoleCards: []string{"HA", "HK"}, BoardCards: []string{"HQ", "HJ"}, NumPlayers: 6, NumSimulations: 1000},
+		Response: api.MonteCarloResponse{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/preflop", Summary: "Look up precomputed preflop all-in odds",
+		Query: []Param{
+			{Name: "hand", Type: "string", Description: `A class such as "AKs" or two cards such as "HA,DK"`, Required: true},
+			{Name: "vs", Type: "string", Description: "The hand to face heads up"},
+			{Name: "opponents", Type: "integer", Description: "Random opponents, 1 by default"},
+		},
+		Response: api.PreflopResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/outs", Summary: "List the cards that improve a hand on the flop or turn",
+		Request:  api.OutsRequest{},
+		Example:  api.OutsRequest{HoleCards: []string{"H7", "H6"}, BoardCards: []string{"S8", "S9", "D2"}, Opponents: [][]string{{"SA", "SK"}}},
+		Response: poker.OutsAnalysis{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/potential", Summary: "Compute hand strength and potential against a range",
+		Request:  api.PotentialRequest{},
+		Example:  api.PotentialRequest{HoleCards: []string{"DA", "CQ"}, BoardCards: []string{"H3", "C4", "HJ"}, Range: "QQ+,AKs", Opponents: 1},
+		Response: poker.Potential{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/board", Summary: "Describe the texture of a board",
+		Query:    []Param{{Name: "cards", Type: "string", Description: "3 to 5 comma-separated cards", Required: true}},
+		Response: poker.BoardTexture{},
+	},
+	{Method: "GET", Path: api.Prefix + "/jobs/{id}", Summary: "Fetch a stored simulation job", Response: store.Job{}},
+	{
+		Method: "GET", Path: api.Prefix + "/sessions", Summary: "List stored table sessions",
+		Query:    []Param{{Name: "table", Type: "string", Description: "Only this table's sessions"}},
+		Response: []store.Session{},
+	},
+	{Method: "GET", Path: api.Prefix + "/sessions/{id}", Summary: "Fetch a stored table session", Response: store.Session{}},
+	{Method: "GET", Path: api.Prefix + "/players", Summary: "List player profiles", Response: []store.Player{}},
+	{Method: "GET", Path: api.Prefix + "/players/{id}", Summary: "Fetch a player profile", Response: store.Player{}},
+	{
+		Method: "PUT", Path: api.Prefix + "/players/{id}", Summary: "Update a player's display name and notes",
+		Request:  api.PlayerUpdateRequest{},
+		Response: store.Player{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/tables/{id}/hands", Summary: "Download a live table's hand histories",
+		Query:        []Param{tokenParam},
+		ResponseType: "text/plain",
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/tables/{id}/hands/{hand}", Summary: "Download one hand history of a live table",
+		Query:        []Param{tokenParam},
+		ResponseType: "text/plain",
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/hands/import", Summary: "Import PokerStars hand histories, as the body or multipart files",
+		RequestType: "text/plain",
+		Response:    api.ImportResponse{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/hands/convert/ohh", Summary: "Convert PokerStars hand histories to Open Hand History",
+		Query:       []Param{{Name: "format", Type: "string", Description: `"ohh" to download an .ohh file`}},
+		RequestType: "text/plain",
+		Response:    ConvertOHHResponse{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/hands/{site}/{hand}/replay", Summary: "Replay an imported hand",
+		Query:    replayParams,
+		Response: replay.Timeline{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/tables/{id}/hands/{hand}/replay", Summary: "Replay a hand played at a live table",
+		Query:    append([]Param{tokenParam}, replayParams...),
+		Response: replay.Timeline{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/stats", Summary: "Per-player HUD statistics",
+		Query: []Param{
+			{Name: "player", Type: "string"},
+			{Name: "stakes", Type: "string", Description: `e.g. "$0.25/$0.50"`},
+			{Name: "position", Type: "string"},
+			{Name: "from", Type: "string", Description: "YYYY-MM-DD"},
+			{Name: "to", Type: "string", Description: "YYYY-MM-DD"},
+		},
+		Response: api.StatsResponse{},
+	},
+	{
+		Method: "GET", Path: api.Prefix + "/tables/{id}/ev", Summary: "All-in adjusted winnings of a player at a live table",
+		Query:    []Param{{Name: "player", Type: "string", Required: true}},
+		Response: ev.Report{},
+	},
+	{
+		Method: "POST", Path: api.Prefix + "/hands/ev", Summary: "All-in adjusted winnings over uploaded hand histories",
+		Query:       []Param{{Name: "player", Type: "string", Description: "The hero of the hands by default"}},
+		RequestType: "text/plain",
+		Response:    ev.Report{},
+	},
+}
+
+// ConvertOHHResponse documents api.ConvertOHHResponse, whose hands are
+// left as raw JSON there so that clients need not import handhistory
+type ConvertOHHResponse struct {
+	Hands  []handhistory.OHHFile `json:"hands"`
+	Errors []api.ImportError     `json:"errors"`
+}
+
+// tokenParam reveals a seated player's own hole cards in the hands of a
+// live table, which are otherwise hidden unless shown down
+var tokenParam = Param{Name: "token", Type: "string", Description: "Seat token of a player at the table, to see that player's hole cards"}
+
+var replayParams = []Param{
+	{Name: "simulations", Type: "integer", Description: "Monte Carlo runs per equity"},
+	{Name: "equity", Type: "string", Description: `"false" to skip equities`},
+}
+
+// Document builds the OpenAPI 3 document of the API from Operations, with a
+// schema for every request and response type
+func Document() map[string]interface{} {
+	s := &schemas{defs: map[string]interface{}{}, names: map[reflect.Type]string{}}
+	errorRef := s.of(reflect.TypeOf(api.Error{}))
+
+	paths := map[string]interface{}{}
+	for _, op := range Operations {
+		o := map[string]interface{}{"summary": op.Summary}
+		var params []interface{}
+		for _, name := range pathParams(op.Path) {
+			params = append(params, map[string]interface{}{
+				"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, p := range op.Query {
+			param := map[string]interface{}{"name": p.Name, "in": "query", "schema": map[string]interface{}{"type": p.Type}}
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			if p.Required {
+				param["required"] = true
+			}
+			params = append(params, param)
+		}
+		if params != nil {
+			o["parameters"] = params
+		}
+
+		switch {
+		case op.Request != nil:
+			media := map[string]interface{}{"schema": s.of(reflect.TypeOf(op.Request))}
+			if op.Example != nil {
+				media["example"] = op.Example
+			}
+			o["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{"application/json": media},
+			}
+		case op.RequestType != "":
+			o["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{op.RequestType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+			}
+		}
+
+		ok := map[string]interface{}{"description": "OK"}
+		if op.Response != nil {
+			ok["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": s.of(reflect.TypeOf(op.Response))}}
+		} else if op.ResponseType != "" {
+			ok["content"] = map[string]interface{}{op.ResponseType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+		}
+		o["responses"] = map[string]interface{}{
+			"200": ok,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorRef}},
+			},
+		}
+
+		item, _ := paths[op.Path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Texas Hold'em API",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": s.defs},
+	}
+}
+
+// pathParams lists the {name} parameters of a path
+func pathParams(path string) []string {
+	var names []string
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			names = append(names, part[1:len(part)-1])
+		}
+	}
+	return names
+}
+
+// schemas collects the named struct types of the document under
+// components/schemas
+type schemas struct {
+	defs  map[string]interface{}
+	names map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// of returns the schema of a type as encoding/json writes it
+func (s *schemas) of(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	case t.Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.of(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name, ok := s.names[t]
+		if !ok {
+			name = s.name(t)
+			s.names[t] = name
+			s.defs[name] = nil // Claimed before the fields, which may refer back
+			s.defs[name] = s.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	// Interfaces hold anything
+	return map[string]interface{}{}
+}
+
+// name picks a schema name for a struct type, qualifying it with its package
+// when another type already took the plain name
+func (s *schemas) name(t reflect.Type) string {
+	if _, taken := s.defs[t.Name()]; !taken {
+		return t.Name()
+	}
+	pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
+
+func (s *schemas) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	s.fields(t, props)
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+// fields adds a struct's JSON fields to props, with those of embedded
+// structs promoted as encoding/json does: a field of the outer struct wins
+// over one of the same name in an embedded struct
+func (s *schemas) fields(t reflect.Type, props map[string]interface{}) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.of(f.Type)
+	}
+
+	for _, et := range embedded {
+		promoted := map[string]interface{}{}
+		s.fields(et, promoted)
+		for name, schema := range promoted {
+			if _, ok := props[name]; !ok {
+				props[name] = schema
+			}
+		}
+	}
+}
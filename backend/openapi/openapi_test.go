@@ -0,0 +1,33 @@
+package openapi
+
+import "testing"
+
+func TestOpenAPISchemas(t *testing.T) {
+	schemas := Document()["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	// Embedded structs are flattened as encoding/json does
+	preflop := schemas["PreflopResponse"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, name := range []string{"hand", "win", "tie", "equity", "loss"} {
+		if _, ok := preflop[name]; !ok {
+			t.Errorf("Expected PreflopResponse to have %q, got %v", name, preflop)
+		}
+	}
+
+	// Converted hands are described as Open Hand History files
+	convert := schemas["ConvertOHHResponse"].(map[string]interface{})["properties"].(map[string]interface{})
+	if items := convert["hands"].(map[string]interface{})["items"].(map[string]interface{}); items["$ref"] != "#/components/schemas/OHHFile" {
+		t.Errorf("Expected hands to be OHH files, got %v", items)
+	}
+
+	for name, schema := range schemas {
+		if schema == nil {
+			t.Errorf("Expected schema %s to be filled in", name)
+		}
+	}
+
+	// Streets are written by name
+	step := schemas["Step"].(map[string]interface{})["properties"].(map[string]interface{})
+	if street := step["street"].(map[string]interface{}); street["type"] != "string" {
+		t.Errorf("Expected street to be a string, got %v", street)
+	}
+}
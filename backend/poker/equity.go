@@ -0,0 +1,209 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Exact enumeration of a preflop all-in runs well over a million boards, too
+// many for EvaluateBestHand. The evaluator below works on cards packed into
+// a 64-bit mask, 16 bits per suit with bit 0 for a deuce, and scores a hand
+// as its HandRank in the top bits followed by up to five 4-bit card values,
+// so that scores compare as plain integers.
+
+var suitIndex = map[string]uint{"H": 0, "D": 1, "C": 2, "S": 3}
+
+func cardBit(c Card) uint64 {
+	return 1 << (16*suitIndex[c.Suit] + uint(c.Value-2))
+}
+
+// highest removes and returns the value of the highest rank in the mask
+func highest(ranks *uint32) uint32 {
+	i := bits.Len32(*ranks) - 1
+	*ranks &^= 1 << uint(i)
+	return uint32(i + 2)
+}
+
+// kickers packs the values of the n highest ranks in the mask
+func kickers(ranks uint32, n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		v = v<<4 | highest(&ranks)
+	}
+	return v
+}
+
+func packScore(rank HandRank, values uint32, n int) uint32 {
+	return uint32(rank)<<20 | values<<(4*uint(5-n))
+}
+
+// straightHigh returns the value of the top card of the best straight in the
+// mask, 5 for the wheel, or 0
+func straightHigh(ranks uint32) uint32 {
+	m := ranks<<1 | ranks>>12&1 // Aces also play low
+	run := m & (m << 1) & (m << 2) & (m << 3) & (m << 4)
+	if run == 0 {
+		return 0
+	}
+	return uint32(bits.Len32(run))
+}
+
+// evaluateMask scores the best five-card hand in a mask of five to seven
+// cards, ranking hands exactly like EvaluateBestHand
+func evaluateMask(m uint64) uint32 {
+	s0, s1 := uint32(m)&0x1fff, uint32(m>>16)&0x1fff
+	s2, s3 := uint32(m>>32)&0x1fff, uint32(m>>48)&0x1fff
+	ranks := s0 | s1 | s2 | s3
+
+	for _, suit := range [4]uint32{s0, s1, s2, s3} {
+		if bits.OnesCount32(suit) < 5 {
+			continue
+		}
+		if high := straightHigh(suit); high == 14 {
+			return packScore(RoyalFlush, high, 1)
+		} else if high > 0 {
+			return packScore(StraightFlush, high, 1)
+		}
+		return packScore(Flush, kickers(suit, 5), 5)
+	}
+
+	quads := s0 & s1 & s2 & s3
+	threes := s0&s1&s2 | s0&s1&s3 | s0&s2&s3 | s1&s2&s3
+	twos := s0&s1 | s0&s2 | s0&s3 | s1&s2 | s1&s3 | s2&s3
+	trips, pairs := threes&^quads, twos&^threes
+
+	if quads != 0 {
+		q := highest(&quads)
+		rest := ranks &^ (1 << (q - 2))
+		return packScore(FourOfAKind, q<<4|highest(&rest), 2)
+	}
+	if trips != 0 && (bits.OnesCount32(trips) > 1 || pairs != 0) {
+		t := highest(&trips)
+		rest := trips | pairs
+		return packScore(FullHouse, t<<4|highest(&rest), 2)
+	}
+	if high := straightHigh(ranks); high > 0 {
+		return packScore(Straight, high, 1)
+	}
+	if trips != 0 {
+		t := highest(&trips)
+		return packScore(ThreeOfAKind, t<<8|kickers(ranks&^(1<<(t-2)), 2), 3)
+	}
+	if bits.OnesCount32(pairs) >= 2 {
+		p1 := highest(&pairs)
+		p2 := highest(&pairs)
+		rest := ranks &^ (1<<(p1-2) | 1<<(p2-2))
+		return packScore(TwoPair, p1<<8|p2<<4|highest(&rest), 3)
+	}
+	if pairs != 0 {
+		p := highest(&pairs)
+		return packScore(OnePair, p<<12|kickers(ranks&^(1<<(p-2)), 3), 4)
+	}
+	return packScore(HighCard, kickers(ranks, 5), 5)
+}
+
+// PotEquity runs out every possible remainder of the board and returns the
+// average amount each hand wins from the pots. Pot.Eligible indexes into
+// hands; hands not eligible for any pot may be nil. Split pots are shared
+// evenly without regard to odd chips, so the results add up to the pots.
+func PotEquity(hands [][]string, boardCardsStrs []string, pots []Pot) ([]float64, error) {
+	var used uint64
+	masks := make([]uint64, len(hands))
+	add := func(cardStrs []string) (uint64, error) {
+		cards, err := ParseCards(cardStrs)
+		if err != nil {
+			return 0, err
+		}
+		var m uint64
+		for _, c := range cards {
+			bit := cardBit(c)
+			if used&bit != 0 {
+				return 0, fmt.Errorf("card %s%s used twice", c.Suit, c.Rank)
+			}
+			used |= bit
+			m |= bit
+		}
+		return m, nil
+	}
+
+	for i, hand := range hands {
+		if hand == nil {
+			continue
+		}
+		if len(hand) != 2 {
+			return nil, fmt.Errorf("hand %d has %d cards", i, len(hand))
+		}
+		m, err := add(hand)
+		if err != nil {
+			return nil, err
+		}
+		masks[i] = m
+	}
+	if len(boardCardsStrs) > 5 {
+		return nil, fmt.Errorf("board has %d cards", len(boardCardsStrs))
+	}
+	board, err := add(boardCardsStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	live := make([]bool, len(hands))
+	for _, pot := range pots {
+		for _, i := range pot.Eligible {
+			if i < 0 || i >= len(hands) || hands[i] == nil {
+				return nil, fmt.Errorf("hand %d is eligible for a pot but unknown", i)
+			}
+			live[i] = true
+		}
+	}
+
+	var deck []uint64
+	for bit := uint64(1); bit != 0; bit <<= 1 {
+		if bit&0x1fff1fff1fff1fff != 0 && used&bit == 0 {
+			deck = append(deck, bit)
+		}
+	}
+
+	won := make([]float64, len(hands))
+	scores := make([]uint32, len(hands))
+	winners := make([]int, 0, len(hands))
+	runs := 0
+	showdown := func(board uint64) {
+		for i, m := range masks {
+			if live[i] {
+				scores[i] = evaluateMask(m | board)
+			}
+		}
+		for _, pot := range pots {
+			winners = winners[:0]
+			for _, i := range pot.Eligible {
+				if len(winners) == 0 || scores[i] > scores[winners[0]] {
+					winners = append(winners[:0], i)
+				} else if scores[i] == scores[winners[0]] {
+					winners = append(winners, i)
+				}
+			}
+			for _, i := range winners {
+				won[i] += float64(pot.Amount) / float64(len(winners))
+			}
+		}
+		runs++
+	}
+
+	var deal func(deck []uint64, board uint64, cardsNeeded int)
+	deal = func(deck []uint64, board uint64, cardsNeeded int) {
+		if cardsNeeded == 0 {
+			showdown(board)
+			return
+		}
+		for i := 0; i <= len(deck)-cardsNeeded; i++ {
+			deal(deck[i+1:], board|deck[i], cardsNeeded-1)
+		}
+	}
+	deal(deck, board, 5-len(boardCardsStrs))
+
+	for i := range won {
+		won[i] /= float64(runs)
+	}
+	return won, nil
+}
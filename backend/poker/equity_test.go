@@ -0,0 +1,143 @@
+package poker
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEvaluateMask(t *testing.T) {
+	var deck []Card
+	for _, suit := range []string{"H", "D", "C", "S"} {
+		for rank, value := range rankValues {
+			deck = append(deck, Card{Suit: suit, Rank: rank, Value: value})
+		}
+	}
+	mask := func(cards []Card) uint64 {
+		var m uint64
+		for _, c := range cards {
+			m |= cardBit(c)
+		}
+		return m
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		rng.Shuffle(len(deck), func(a, b int) { deck[a], deck[b] = deck[b], deck[a] })
+		a, b := deck[:7], deck[7:14]
+		scoreA, scoreB := EvaluateBestHand(a), EvaluateBestHand(b)
+		maskA, maskB := evaluateMask(mask(a)), evaluateMask(mask(b))
+
+		if HandRank(maskA>>20) != scoreA.Rank {
+			t.Fatalf("Expected %v for %v, got %v", scoreA.Rank, a, HandRank(maskA>>20))
+		}
+		expected := compareScores(scoreA, scoreB)
+		got := 0
+		if maskA > maskB {
+			got = 1
+		} else if maskA < maskB {
+			got = -1
+		}
+		if got != expected {
+			t.Fatalf("Expected %d comparing %v with %v, got %d", expected, a, b, got)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		cards []string
+		rank  HandRank
+	}{
+		{"Wheel", []string{"HA", "D2", "C3", "S4", "H5", "D9", "CK"}, Straight},
+		{"Steel wheel", []string{"HA", "H2", "H3", "H4", "H5", "D9", "CK"}, StraightFlush},
+		{"Royal flush", []string{"HA", "HK", "HQ", "HJ", "HT", "H9", "CK"}, RoyalFlush},
+		{"Two trips", []string{"HA", "DA", "CA", "SK", "HK", "DK", "C2"}, FullHouse},
+		{"Three pairs", []string{"HA", "DA", "CK", "SK", "HQ", "DQ", "CJ"}, TwoPair},
+		{"Quads with a pair", []string{"H9", "D9", "C9", "S9", "HK", "DK", "C2"}, FourOfAKind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, _ := ParseCards(tt.cards)
+			if got := HandRank(evaluateMask(mask(cards)) >> 20); got != tt.rank {
+				t.Errorf("Expected %v, got %v", tt.rank, got)
+			}
+		})
+	}
+}
+
+func TestPotEquity(t *testing.T) {
+	tests := []struct {
+		name      string
+		hands     [][]string
+		board     []string
+		pots      []Pot
+		expected  []float64
+		tolerance float64
+	}{
+		{
+			name:     "Two outs on the turn",
+			hands:    [][]string{{"SA", "DA"}, {"SK", "CK"}},
+			board:    []string{"HK", "C7", "D2", "S9"},
+			pots:     []Pot{{Amount: 440, Eligible: []int{0, 1}}},
+			expected: []float64{20, 420},
+		},
+		{
+			name:     "Board plays",
+			hands:    [][]string{{"S2", "D3"}, {"C4", "S5"}},
+			board:    []string{"HA", "HK", "HQ", "HJ", "HT"},
+			pots:     []Pot{{Amount: 100, Eligible: []int{0, 1}}},
+			expected: []float64{50, 50},
+		},
+		{
+			name:  "Short stack can only win the main pot",
+			hands: [][]string{{"SA", "DA"}, {"SK", "CK"}, nil, {"S7", "D7"}},
+			board: []string{"HK", "C7", "D2", "S9", "H3"},
+			pots: []Pot{
+				{Amount: 300, Eligible: []int{0, 1, 3}},
+				{Amount: 200, Eligible: []int{0, 1}},
+			},
+			expected: []float64{0, 500, 0, 0},
+		},
+		{
+			name:      "Aces against kings preflop",
+			hands:     [][]string{{"SA", "DA"}, {"SK", "CK"}},
+			pots:      []Pot{{Amount: 1, Eligible: []int{0, 1}}},
+			expected:  []float64{0.82, 0.18},
+			tolerance: 0.01,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PotEquity(tt.hands, tt.board, tt.pots)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i := range tt.expected {
+				if math.Abs(got[i]-tt.expected[i]) > tt.tolerance+1e-9 {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+
+	errors := []struct {
+		name  string
+		hands [][]string
+		board []string
+		pots  []Pot
+	}{
+		{"Invalid card", [][]string{{"XX", "SA"}}, nil, nil},
+		{"Duplicate card", [][]string{{"SA", "DA"}, {"SA", "CK"}}, nil, nil},
+		{"Unknown eligible hand", [][]string{{"SA", "DA"}, nil}, nil, []Pot{{Amount: 10, Eligible: []int{0, 1}}}},
+		{"Too many board cards", [][]string{{"SA", "DA"}}, []string{"H2", "H3", "H4", "H5", "H6", "H7"}, nil},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PotEquity(tt.hands, tt.board, tt.pots); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// Hands that differ only by a renaming of suits are strategically the same:
+// AhKh on 2c3c4d plays exactly like AsKs on 2d3d4h. A HandIndexer numbers
+// these equivalence classes. Each suit holds some hole cards and some board
+// cards; a class is the multiset of what the four suits hold, so it can be
+// ranked with combinatorial numbers. Classes are grouped by pattern, the
+// sorted list of how many hole and board cards each suit has, and ranked
+// within each pattern suit group by suit group.
+
+// suitShape is how many hole and board cards one suit has
+type suitShape struct {
+	hole, board int
+}
+
+func (s suitShape) less(o suitShape) bool {
+	if s.hole != o.hole {
+		return s.hole > o.hole
+	}
+	return s.board > o.board
+}
+
+// configs is the number of ways a suit of this shape can hold its cards
+func (s suitShape) configs() uint64 {
+	return binomial(13, s.hole) * binomial(uint64(13-s.hole), s.board)
+}
+
+// isoPattern is the shapes of the four suits, sorted
+type isoPattern struct {
+	shapes [4]suitShape
+	offset uint64 // Index of the pattern's first class
+	size   uint64
+}
+
+// groups splits the pattern into runs of suits with the same shape
+func (p *isoPattern) groups() [][2]int {
+	var out [][2]int
+	start := 0
+	for i := 1; i <= 4; i++ {
+		if i == 4 || p.shapes[i] != p.shapes[start] {
+			out = append(out, [2]int{start, i})
+			start = i
+		}
+	}
+	return out
+}
+
+// groupSize is the number of multisets of m configurations of a shape
+func groupSize(shape suitShape, m int) uint64 {
+	return binomial(shape.configs()+uint64(m)-1, m)
+}
+
+// HandIndexer numbers the suit isomorphism classes of two hole cards and a
+// board of a given size
+type HandIndexer struct {
+	boardSize int
+	patterns  []isoPattern
+	byShapes  map[[4]suitShape]int
+	size      uint64
+}
+
+// Indexers for every street. The board is taken as a whole, so a turn
+// class does not distinguish the turn card from the flop.
+var (
+	PreflopIndexer = NewHandIndexer(0)
+	FlopIndexer    = NewHandIndexer(3)
+	TurnIndexer    = NewHandIndexer(4)
+	RiverIndexer   = NewHandIndexer(5)
+)
+
+// NewHandIndexer creates an indexer for boards of 0 to 5 cards
+func NewHandIndexer(boardSize int) *HandIndexer {
+	ix := &HandIndexer{boardSize: boardSize, byShapes: make(map[[4]suitShape]int)}
+	var shapes [4]suitShape
+	var fill func(i, hole, board int)
+	fill = func(i, hole, board int) {
+		if i == 4 {
+			if hole == 0 && board == 0 {
+				p := isoPattern{shapes: shapes, offset: ix.size, size: 1}
+				for _, g := range p.groups() {
+					p.size *= groupSize(shapes[g[0]], g[1]-g[0])
+				}
+				ix.byShapes[shapes] = len(ix.patterns)
+				ix.patterns = append(ix.patterns, p)
+				ix.size += p.size
+			}
+			return
+		}
+		for h := hole; h >= 0; h-- {
+			for b := board; b >= 0; b-- {
+				s := suitShape{h, b}
+				if i > 0 && s.less(shapes[i-1]) {
+					continue // Keep shapes sorted
+				}
+				shapes[i] = s
+				fill(i+1, hole-h, board-b)
+			}
+		}
+	}
+	fill(0, 2, boardSize)
+	return ix
+}
+
+// Size is the number of classes; indexes run from 0 to Size-1
+func (ix *HandIndexer) Size() uint64 {
+	return ix.size
+}
+
+// suitCards is what one suit holds, as 13-bit rank masks with bit 0 for a
+// deuce
+type suitCards struct {
+	hole, board uint32
+}
+
+func (s suitCards) shape() suitShape {
+	return suitShape{bits.OnesCount32(s.hole), bits.OnesCount32(s.board)}
+}
+
+// config ranks the suit's cards among those of its shape: the hole ranks,
+// then the board ranks among the ranks not in the hole
+func (s suitCards) config() uint64 {
+	shape := s.shape()
+	return rankSubset(s.hole)*binomial(uint64(13-shape.hole), shape.board) + rankSubset(compressRanks(s.board, s.hole))
+}
+
+func configCards(shape suitShape, c uint64) suitCards {
+	per := binomial(uint64(13-shape.hole), shape.board)
+	hole := unrankSubset(c/per, shape.hole)
+	return suitCards{hole: hole, board: expandRanks(unrankSubset(c%per, shape.board), hole)}
+}
+
+func (ix *HandIndexer) suits(hole, board []string) ([4]suitCards, error) {
+	var suits [4]suitCards
+	if len(hole) != 2 || len(board) != ix.boardSize {
+		return suits, fmt.Errorf("expected 2 hole cards and %d board cards, got %d and %d", ix.boardSize, len(hole), len(board))
+	}
+	var seen uint64
+	for i, cardStr := range append(append([]string(nil), hole...), board...) {
+		c, err := ParseCard(cardStr)
+		if err != nil {
+			return suits, err
+		}
+		bit := cardBit(c)
+		if seen&bit != 0 {
+			return suits, fmt.Errorf("card %s used twice", cardStr)
+		}
+		seen |= bit
+		s, r := suitIndex[c.Suit], uint32(1)<<uint(c.Value-2)
+		if i < 2 {
+			suits[s].hole |= r
+		} else {
+			suits[s].board |= r
+		}
+	}
+	return suits, nil
+}
+
+// sortSuits orders suits by shape and then by configuration, the order in
+// which classes assign them
+func sortSuits(suits []suitCards) {
+	sort.SliceStable(suits, func(i, j int) bool {
+		a, b := suits[i].shape(), suits[j].shape()
+		if a != b {
+			return a.less(b)
+		}
+		return suits[i].config() < suits[j].config()
+	})
+}
+
+// Index returns the class of hole cards on a board
+func (ix *HandIndexer) Index(hole, board []string) (uint64, error) {
+	suits, err := ix.suits(hole, board)
+	if err != nil {
+		return 0, err
+	}
+	sortSuits(suits[:])
+
+	var shapes [4]suitShape
+	for i, s := range suits {
+		shapes[i] = s.shape()
+	}
+	p := &ix.patterns[ix.byShapes[shapes]]
+
+	var index, radix uint64 = 0, 1
+	for _, g := range p.groups() {
+		// The multiset a0 <= a1 <= ... is ranked as the set a0 < a1+1 < ...
+		var rank uint64
+		for i, s := range suits[g[0]:g[1]] {
+			rank += binomial(s.config()+uint64(i), i+1)
+		}
+		index += rank * radix
+		radix *= groupSize(shapes[g[0]], g[1]-g[0])
+	}
+	return p.offset + index, nil
+}
+
+// Unindex returns the canonical hole cards and board of a class: the
+// suits, in the order hearts, diamonds, clubs, spades, hold the most hole
+// cards, then the most board cards, then the lowest configurations.
+func (ix *HandIndexer) Unindex(index uint64) ([]string, []string, error) {
+	if index >= ix.size {
+		return nil, nil, fmt.Errorf("index %d out of range 0-%d", index, ix.size-1)
+	}
+	n := sort.Search(len(ix.patterns), func(i int) bool { return ix.patterns[i].offset > index }) - 1
+	p := &ix.patterns[n]
+	index -= p.offset
+
+	var suits [4]suitCards
+	for _, g := range p.groups() {
+		shape, m := p.shapes[g[0]], g[1]-g[0]
+		size := groupSize(shape, m)
+		rank := index % size
+		index /= size
+		for i := m - 1; i >= 0; i-- {
+			x := largestBinomial(rank, i+1)
+			rank -= binomial(x, i+1)
+			suits[g[0]+i] = configCards(shape, x-uint64(i))
+		}
+	}
+
+	var hole, board []string
+	for s, cards := range suits {
+		for r := 12; r >= 0; r-- {
+			card := suitLetters[s:s+1] + rankLetters[r:r+1]
+			if cards.hole&(1<<uint(r)) != 0 {
+				hole = append(hole, card)
+			}
+			if cards.board&(1<<uint(r)) != 0 {
+				board = append(board, card)
+			}
+		}
+	}
+	if board == nil {
+		board = []string{}
+	}
+	return hole, board, nil
+}
+
+// Canonical returns the representative of the class of hole cards on a
+// board, as given by Unindex
+func (ix *HandIndexer) Canonical(hole, board []string) ([]string, []string, error) {
+	index, err := ix.Index(hole, board)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ix.Unindex(index)
+}
+
+// IndexerFor returns the indexer for a board of 0 or 3 to 5 cards
+func IndexerFor(boardSize int) (*HandIndexer, error) {
+	switch boardSize {
+	case 0:
+		return PreflopIndexer, nil
+	case 3:
+		return FlopIndexer, nil
+	case 4:
+		return TurnIndexer, nil
+	case 5:
+		return RiverIndexer, nil
+	}
+	return nil, fmt.Errorf("no street has %d board cards", boardSize)
+}
+
+// CanonicalKey names the class of hole cards on a board, for use as a cache
+// key, e.g. "flop:123456"
+func CanonicalKey(hole, board []string) (string, error) {
+	ix, err := IndexerFor(len(board))
+	if err != nil {
+		return "", err
+	}
+	index, err := ix.Index(hole, board)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d", isoStreets[len(board)], index), nil
+}
+
+var isoStreets = map[int]string{0: "preflop", 3: "flop", 4: "turn", 5: "river"}
+
+const (
+	suitLetters = "HDCS" // In the order of suitIndex
+	rankLetters = "23456789TJQKA"
+)
+
+func binomial(n uint64, k int) uint64 {
+	if k < 0 || uint64(k) > n {
+		return 0
+	}
+	r := uint64(1)
+	for i := uint64(1); i <= uint64(k); i++ {
+		r = r * (n - uint64(k) + i) / i
+	}
+	return r
+}
+
+// largestBinomial returns the largest x with binomial(x, k) <= rank
+func largestBinomial(rank uint64, k int) uint64 {
+	x := uint64(k - 1)
+	for binomial(x+1, k) <= rank {
+		x++
+	}
+	return x
+}
+
+// rankSubset ranks a set of ranks among those of its size in colex order
+func rankSubset(mask uint32) uint64 {
+	var rank uint64
+	for i := 1; mask != 0; i++ {
+		r := bits.TrailingZeros32(mask)
+		mask &= mask - 1
+		rank += binomial(uint64(r), i)
+	}
+	return rank
+}
+
+func unrankSubset(rank uint64, k int) uint32 {
+	var mask uint32
+	for i := k; i >= 1; i-- {
+		x := largestBinomial(rank, i)
+		rank -= binomial(x, i)
+		mask |= 1 << uint(x)
+	}
+	return mask
+}
+
+// compressRanks renumbers the ranks in mask as if the ranks in removed did
+// not exist
+func compressRanks(mask, removed uint32) uint32 {
+	var out uint32
+	j := uint(0)
+	for r := uint(0); r < 13; r++ {
+		if removed&(1<<r) != 0 {
+			continue
+		}
+		if mask&(1<<r) != 0 {
+			out |= 1 << j
+		}
+		j++
+	}
+	return out
+}
+
+// expandRanks undoes compressRanks
+func expandRanks(mask, removed uint32) uint32 {
+	var out uint32
+	j := uint(0)
+	for r := uint(0); r < 13; r++ {
+		if removed&(1<<r) != 0 {
+			continue
+		}
+		if mask&(1<<j) != 0 {
+			out |= 1 << r
+		}
+		j++
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package poker
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// permuteSuits renames the suits of cards, suit i becoming perm[i]
+func permuteSuits(cards []string, perm string) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = perm[strings.IndexByte(suitLetters, c[0]):][:1] + c[1:]
+	}
+	return out
+}
+
+var suitPermutations = func() []string {
+	var out []string
+	var permute func(prefix, rest string)
+	permute = func(prefix, rest string) {
+		if rest == "" {
+			out = append(out, prefix)
+		}
+		for i := range rest {
+			permute(prefix+rest[i:i+1], rest[:i]+rest[i+1:])
+		}
+	}
+	permute("", suitLetters)
+	return out
+}()
+
+func randomCards(rng *rand.Rand, n int) []string {
+	var cards []string
+	for _, i := range rng.Perm(52)[:n] {
+		cards = append(cards, suitLetters[i/13:i/13+1]+rankLetters[i%13:i%13+1])
+	}
+	return cards
+}
+
+func TestHandIndexerSize(t *testing.T) {
+	tests := []struct {
+		ix       *HandIndexer
+		expected uint64
+	}{
+		{PreflopIndexer, 169},
+		{FlopIndexer, 1286792},
+		{TurnIndexer, 13960050},
+		{RiverIndexer, 123156254},
+	}
+	for _, tt := range tests {
+		if got := tt.ix.Size(); got != tt.expected {
+			t.Errorf("Expected %d classes with %d board cards, got %d", tt.expected, tt.ix.boardSize, got)
+		}
+	}
+}
+
+func TestHandIndexer(t *testing.T) {
+	same := []struct {
+		name          string
+		hole1, board1 []string
+		hole2, board2 []string
+	}{
+		{"flop", []string{"HA", "HK"}, []string{"C2", "C3", "D4"}, []string{"SK", "SA"}, []string{"D3", "H4", "D2"}},
+		{"preflop", []string{"HA", "DK"}, []string{}, []string{"CK", "SA"}, []string{}},
+		{"turn", []string{"H7", "D7"}, []string{"HQ", "C2", "S2", "DJ"}, []string{"C7", "S7"}, []string{"D2", "SQ", "H2", "CJ"}},
+	}
+	for _, tt := range same {
+		t.Run(tt.name, func(t *testing.T) {
+			ix, _ := IndexerFor(len(tt.board1))
+			a, err1 := ix.Index(tt.hole1, tt.board1)
+			b, err2 := ix.Index(tt.hole2, tt.board2)
+			if err1 != nil || err2 != nil || a != b {
+				t.Errorf("Expected the same class, got %d (%v) and %d (%v)", a, err1, b, err2)
+			}
+		})
+	}
+
+	// Suited and offsuit are different
+	a, _ := FlopIndexer.Index([]string{"HA", "HK"}, []string{"C2", "C3", "D4"})
+	b, _ := FlopIndexer.Index([]string{"HA", "SK"}, []string{"C2", "C3", "D4"})
+	if a == b {
+		t.Errorf("Expected AKs and AKo to be different classes")
+	}
+
+	hole, board, err := FlopIndexer.Canonical([]string{"SA", "SK"}, []string{"D2", "D3", "H4"})
+	if err != nil || strings.Join(hole, " ") != "HA HK" || strings.Join(board, " ") != "D3 D2 C4" {
+		t.Errorf("Unexpected canonical hand %v %v (%v)", hole, board, err)
+	}
+
+	errors := []struct {
+		hole, board []string
+	}{
+		{[]string{"HA"}, []string{"C2", "C3", "D4"}},
+		{[]string{"HA", "HK"}, []string{"C2", "C3"}},
+		{[]string{"HA", "HK"}, []string{"C2", "C3", "HA"}},
+		{[]string{"HA", "XX"}, []string{"C2", "C3", "D4"}},
+	}
+	for _, tt := range errors {
+		if _, err := FlopIndexer.Index(tt.hole, tt.board); err == nil {
+			t.Errorf("Expected error for %v %v", tt.hole, tt.board)
+		}
+	}
+	if _, _, err := FlopIndexer.Unindex(FlopIndexer.Size()); err == nil {
+		t.Errorf("Expected error for an index out of range")
+	}
+	if _, err := IndexerFor(2); err == nil {
+		t.Errorf("Expected error for a 2 card board")
+	}
+}
+
+func TestPreflopClasses(t *testing.T) {
+	classes := make(map[uint64]string)
+	for i := 0; i < 52; i++ {
+		for j := i + 1; j < 52; j++ {
+			hole := []string{
+				suitLetters[i/13:i/13+1] + rankLetters[i%13:i%13+1],
+				suitLetters[j/13:j/13+1] + rankLetters[j%13:j%13+1],
+			}
+			index, err := PreflopIndexer.Index(hole, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			class, _ := HandClass(hole)
+			if seen, ok := classes[index]; ok && seen != class {
+				t.Fatalf("%v is %s but has the index of %s", hole, class, seen)
+			}
+			classes[index] = class
+		}
+	}
+	if len(classes) != NumHandClasses {
+		t.Errorf("Expected %d classes, got %d", NumHandClasses, len(classes))
+	}
+	for index, class := range classes {
+		hole, _, err := PreflopIndexer.Unindex(index)
+		if got, _ := HandClass(hole); err != nil || got != class {
+			t.Errorf("Expected %d to be %s, got %v (%v)", index, class, hole, err)
+		}
+	}
+}
+
+func TestHandIndexerRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, ix := range []*HandIndexer{FlopIndexer, TurnIndexer, RiverIndexer} {
+		for n := 0; n < 2000; n++ {
+			// Every suit renaming of a hand has its index
+			cards := randomCards(rng, 2+ix.boardSize)
+			index, err := ix.Index(cards[:2], cards[2:])
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, perm := range suitPermutations {
+				p := permuteSuits(cards, perm)
+				if got, _ := ix.Index(p[:2], p[2:]); got != index {
+					t.Fatalf("Expected %v to have index %d like %v, got %d", p, index, cards, got)
+				}
+			}
+
+			// Indexes map back to a hand with that index
+			index = uint64(rng.Int63n(int64(ix.Size())))
+			hole, board, err := ix.Unindex(index)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got, err := ix.Index(hole, board); err != nil || got != index {
+				t.Fatalf("Expected %v %v to have index %d, got %d (%v)", hole, board, index, got, err)
+			}
+		}
+	}
+}
@@ -1,11 +1,25 @@
 package poker
 
 import (
+	"fmt"
 	"math/rand"
 	"time"
 )
 
 func MonteCarloSimulation(holeCardsStrs, boardCardsStrs []string, numPlayers, numSimulations int) (float64, float64, float64) {
+	return MonteCarloSeeded(holeCardsStrs, boardCardsStrs, numPlayers, numSimulations, time.Now().UnixNano())
+}
+
+// MonteCarloSeeded runs MonteCarloSimulation with its random numbers drawn
+// from seed, so that the same seed always gives the same odds
+func MonteCarloSeeded(holeCardsStrs, boardCardsStrs []string, numPlayers, numSimulations int, seed int64) (float64, float64, float64) {
+	return MonteCarloRand(holeCardsStrs, boardCardsStrs, numPlayers, numSimulations, rand.New(rand.NewSource(seed)))
+}
+
+// MonteCarloRand runs MonteCarloSimulation with its random numbers drawn
+// from rng. Callers splitting a simulation into parts pass the same rng to
+// each.
+func MonteCarloRand(holeCardsStrs, boardCardsStrs []string, numPlayers, numSimulations int, rng *rand.Rand) (float64, float64, float64) {
 	holeCards, err := ParseCards(holeCardsStrs)
 	if err != nil {
 		return 0, 0, 0
@@ -28,8 +42,6 @@ func MonteCarloSimulation(holeCardsStrs, boardCardsStrs []string, numPlayers, nu
 	ties := 0
 	losses := 0
 
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	for i := 0; i < numSimulations; i++ {
 		result := simulateHand(holeCards, boardCards, usedCards, numPlayers, rng)
 		if result > 0 {
@@ -115,3 +127,170 @@ func drawRandomCard(usedCards map[string]bool, rng *rand.Rand) Card {
 		}
 	}
 }
+
+// HandsEquity returns each hand's share of the pot, split pots included, when
+// the given hole cards are all known. Boards missing one or two cards are
+// enumerated exactly; earlier streets are sampled numSimulations times with
+// boards drawn from rng, seeded from the clock when nil. Callers splitting a
+// simulation into parts pass the same rng to each.
+func HandsEquity(hands [][]string, boardCardsStrs []string, numSimulations int, rng *rand.Rand) ([]float64, error) {
+	holeCards := make([][]Card, len(hands))
+	usedCards := make(map[string]bool)
+	for i, hand := range hands {
+		cards, err := ParseCards(hand)
+		if err != nil {
+			return nil, err
+		}
+		holeCards[i] = cards
+		for _, c := range hand {
+			usedCards[c] = true
+		}
+	}
+	boardCards, err := ParseCards(boardCardsStrs)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range boardCardsStrs {
+		usedCards[c] = true
+	}
+
+	equity := make([]float64, len(hands))
+	runs := 0
+	score := func(board []Card) {
+		splitPot(holeCards, board, equity)
+		runs++
+	}
+
+	cardsNeeded := 5 - len(boardCards)
+	if cardsNeeded <= 2 {
+		var deck []Card
+		for _, suit := range []string{"H", "D", "C", "S"} {
+			for rank, value := range rankValues {
+				if !usedCards[suit+rank] {
+					deck = append(deck, Card{Suit: suit, Rank: rank, Value: value})
+				}
+			}
+		}
+		enumerateBoards(deck, boardCards, cardsNeeded, score)
+	} else {
+		if rng == nil {
+			rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		for i := 0; i < numSimulations; i++ {
+			simUsedCards := make(map[string]bool, len(usedCards)+cardsNeeded)
+			for k, v := range usedCards {
+				simUsedCards[k] = v
+			}
+			simBoard := append([]Card(nil), boardCards...)
+			for len(simBoard) < 5 {
+				card := drawRandomCard(simUsedCards, rng)
+				simBoard = append(simBoard, card)
+				simUsedCards[card.Suit+card.Rank] = true
+			}
+			score(simBoard)
+		}
+	}
+
+	if runs == 0 {
+		return equity, nil
+	}
+	for i := range equity {
+		equity[i] /= float64(runs)
+	}
+	return equity, nil
+}
+
+// splitPot adds each hand's share of the pot on a complete board to equity,
+// for as many hands as equity holds
+func splitPot(holeCards [][]Card, board []Card, equity []float64) {
+	scores := make([]HandScore, len(holeCards))
+	best := 0
+	for i, hole := range holeCards {
+		scores[i] = EvaluateBestHand(append(append([]Card(nil), hole...), board...))
+		if compareScores(scores[i], scores[best]) > 0 {
+			best = i
+		}
+	}
+	var winners []int
+	for i := range scores {
+		if compareScores(scores[i], scores[best]) == 0 {
+			winners = append(winners, i)
+		}
+	}
+	for _, i := range winners {
+		if i < len(equity) {
+			equity[i] += 1 / float64(len(winners))
+		}
+	}
+}
+
+// EquityAgainstRandom returns the share of the pot of each of the given
+// hands, dealt together against unknown random hands. Each of the
+// numSimulations runs deals the random hands and the rest of the board
+// from rng, seeded from the clock when nil.
+func EquityAgainstRandom(hands [][]string, unknown int, boardCardsStrs []string, numSimulations int, rng *rand.Rand) ([]float64, error) {
+	holeCards := make([][]Card, len(hands), len(hands)+unknown)
+	usedCards := make(map[string]bool)
+	for i, hand := range hands {
+		cards, err := ParseCards(hand)
+		if err != nil {
+			return nil, err
+		}
+		holeCards[i] = cards
+		for _, c := range cards {
+			usedCards[c.Suit+c.Rank] = true
+		}
+	}
+	boardCards, err := ParseCards(boardCardsStrs)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range boardCards {
+		usedCards[c.Suit+c.Rank] = true
+	}
+	if len(usedCards) != 2*len(hands)+len(boardCards) {
+		return nil, fmt.Errorf("a card is dealt twice")
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	equity := make([]float64, len(hands))
+	if numSimulations <= 0 {
+		return equity, nil
+	}
+	for i := 0; i < numSimulations; i++ {
+		simUsedCards := make(map[string]bool, len(usedCards)+2*unknown+5)
+		for k, v := range usedCards {
+			simUsedCards[k] = v
+		}
+		draw := func() Card {
+			card := drawRandomCard(simUsedCards, rng)
+			simUsedCards[card.Suit+card.Rank] = true
+			return card
+		}
+		dealt := holeCards
+		for j := 0; j < unknown; j++ {
+			dealt = append(dealt, []Card{draw(), draw()})
+		}
+		simBoard := append([]Card(nil), boardCards...)
+		for len(simBoard) < 5 {
+			simBoard = append(simBoard, draw())
+		}
+		splitPot(dealt, simBoard, equity)
+	}
+	for i := range equity {
+		equity[i] /= float64(numSimulations)
+	}
+	return equity, nil
+}
+
+func enumerateBoards(deck, board []Card, cardsNeeded int, fn func([]Card)) {
+	if cardsNeeded == 0 {
+		fn(board)
+		return
+	}
+	for i := range deck {
+		enumerateBoards(deck[i+1:], append(board, deck[i]), cardsNeeded-1, fn)
+	}
+}
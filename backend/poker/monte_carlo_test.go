@@ -0,0 +1,116 @@
+package poker
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHandsEquity(t *testing.T) {
+	tests := []struct {
+		name      string
+		hands     [][]string
+		board     []string
+		expected  []float64
+		tolerance float64
+	}{
+		{
+			name:     "River decided",
+			hands:    [][]string{{"SQ", "DQ"}, {"D7", "S7"}},
+			board:    []string{"HQ", "C7", "D2", "S9", "H3"},
+			expected: []float64{1, 0},
+		},
+		{
+			name:     "Board plays",
+			hands:    [][]string{{"S2", "D3"}, {"C4", "S5"}},
+			board:    []string{"HA", "HK", "HQ", "HJ", "HT"},
+			expected: []float64{0.5, 0.5},
+		},
+		{
+			name:     "Two outs on the turn",
+			hands:    [][]string{{"SA", "DA"}, {"SK", "CK"}},
+			board:    []string{"HK", "C7", "D2", "S9"},
+			expected: []float64{2.0 / 44, 42.0 / 44},
+		},
+		{
+			name:      "Aces against kings preflop",
+			hands:     [][]string{{"SA", "DA"}, {"SK", "CK"}},
+			expected:  []float64{0.82, 0.18},
+			tolerance: 0.03,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HandsEquity(tt.hands, tt.board, 5000, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i := range tt.expected {
+				if math.Abs(got[i]-tt.expected[i]) > tt.tolerance+1e-9 {
+					t.Errorf("Expected equity %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := HandsEquity([][]string{{"XX", "SA"}}, nil, 10, nil); err == nil {
+		t.Errorf("Expected error for invalid card")
+	}
+}
+
+// TestHandsEquitySeeded checks that a simulation split into parts drawing
+// from one generator gives the same equities as one run from the same seed
+func TestHandsEquitySeeded(t *testing.T) {
+	hands := [][]string{{"SA", "DA"}, {"SK", "CK"}, {"H9", "H8"}}
+	whole, _ := HandsEquity(hands, nil, 1000, rand.New(rand.NewSource(7)))
+
+	rng := rand.New(rand.NewSource(7))
+	sums := make([]float64, len(hands))
+	for part := 0; part < 4; part++ {
+		equity, _ := HandsEquity(hands, nil, 250, rng)
+		for i, e := range equity {
+			sums[i] += e * 250
+		}
+	}
+	for i := range whole {
+		if math.Abs(sums[i]/1000-whole[i]) > 1e-9 {
+			t.Fatalf("Expected %v from the parts, got %v", whole, sums)
+		}
+	}
+}
+
+func TestEquityAgainstRandom(t *testing.T) {
+	// Every ace is known, so the random hand never holds one and the two
+	// pairs of aces split most pots between them
+	hands := [][]string{{"SA", "DA"}, {"HA", "CA"}}
+	equity, err := EquityAgainstRandom(hands, 1, nil, 2000, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, e := range equity {
+		if e < 0.35 || e > 0.5 {
+			t.Errorf("Expected hand %d to have about 40%%, got %v", i, e)
+		}
+	}
+	if equity[0]+equity[1] > 1 {
+		t.Errorf("Expected the random hand to win some pots, got %v", equity)
+	}
+
+	if _, err := EquityAgainstRandom([][]string{{"SA", "DA"}, {"SA", "CK"}}, 1, nil, 10, nil); err == nil {
+		t.Errorf("Expected error for a card dealt twice")
+	}
+}
+
+func TestMonteCarloSeeded(t *testing.T) {
+	hole, board := []string{"HA", "SA"}, []string{"D7", "C2", "S9"}
+	w1, t1, l1 := MonteCarloSeeded(hole, board, 3, 500, 42)
+	w2, t2, l2 := MonteCarloSeeded(hole, board, 3, 500, 42)
+	if w1 != w2 || t1 != t2 || l1 != l2 {
+		t.Errorf("Expected the same odds from the same seed, got %v/%v/%v and %v/%v/%v", w1, t1, l1, w2, t2, l2)
+	}
+	if math.Abs(w1+t1+l1-1) > 1e-9 {
+		t.Errorf("Expected odds adding up to 1, got %v", w1+t1+l1)
+	}
+}
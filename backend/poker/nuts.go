@@ -0,0 +1,193 @@
+package poker
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RankedHand is one strength of holding on a board: every two-card holding
+// that makes exactly this hand, kickers included
+type RankedHand struct {
+	Hand    string   `json:"hand"`
+	Value   string   `json:"value"`
+	Combos  int      `json:"combos"`
+	Example []string `json:"example"`
+}
+
+// HandStrength places a holding among every other holding on the same
+// board. Holdings that share a card with it are left out, so a hand can be
+// the nuts when it blocks the only better one.
+type HandStrength struct {
+	Nuts         RankedHand   `json:"nuts"`
+	Position     int          `json:"position"` // 1 for the nuts
+	Label        string       `json:"label"`    // e.g. "2nd nuts"
+	Beats        float64      `json:"beats"`    // Share of other holdings beaten
+	CombosAhead  int          `json:"combosAhead"`
+	CombosTied   int          `json:"combosTied"`
+	CombosBehind int          `json:"combosBehind"`
+	Ahead        []RankedHand `json:"ahead"` // What beats the holding, best first, kickers merged
+}
+
+// handLevel counts the holdings that score the same
+type handLevel struct {
+	score   uint32
+	combos  int
+	example uint64
+}
+
+// boardLevels scores every holding on a board that avoids the dead cards
+// and groups them by score, best first
+func boardLevels(board, dead uint64) []handLevel {
+	byScore := make(map[uint32]*handLevel)
+	for i := 0; i < 52; i++ {
+		a := uint64(1) << uint(16*(i/13)+i%13)
+		if (board|dead)&a != 0 {
+			continue
+		}
+		for j := i + 1; j < 52; j++ {
+			b := uint64(1) << uint(16*(j/13)+j%13)
+			if (board|dead)&b != 0 {
+				continue
+			}
+			score := evaluateMask(board | a | b)
+			l, ok := byScore[score]
+			if !ok {
+				l = &handLevel{score: score, example: a | b}
+				byScore[score] = l
+			}
+			l.combos++
+		}
+	}
+	levels := make([]handLevel, 0, len(byScore))
+	for _, l := range byScore {
+		levels = append(levels, *l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].score > levels[j].score })
+	return levels
+}
+
+// maskCards lists the cards in a mask, highest first
+func maskCards(m uint64) []string {
+	var cards []string
+	for v := 14; v >= 2; v-- {
+		for s := 0; s < 4; s++ {
+			if m&(1<<uint(16*s+v-2)) != 0 {
+				cards = append(cards, suitLetters[s:s+1]+rankLetters[v-2:v-1])
+			}
+		}
+	}
+	return cards
+}
+
+func (l handLevel) ranked(boardCards []Card) RankedHand {
+	example := maskCards(l.example)
+	cards, _ := ParseCards(example)
+	score := EvaluateBestHand(append(cards, boardCards...))
+	return RankedHand{
+		Hand:    score.Rank.String(),
+		Value:   formatHandValue(score),
+		Combos:  l.combos,
+		Example: example,
+	}
+}
+
+func parseBoard(boardCards []string) ([]Card, uint64, error) {
+	if len(boardCards) < 3 || len(boardCards) > 5 {
+		return nil, 0, fmt.Errorf("expected 3 to 5 board cards, got %d", len(boardCards))
+	}
+	cards, err := ParseCards(boardCards)
+	if err != nil {
+		return nil, 0, err
+	}
+	var mask uint64
+	for i, c := range cards {
+		if mask&cardBit(c) != 0 {
+			return nil, 0, fmt.Errorf("card %s used twice", boardCards[i])
+		}
+		mask |= cardBit(c)
+	}
+	return cards, mask, nil
+}
+
+// BoardHands ranks every hand a holding can make on a flop, turn or river
+// board, the nuts first
+func BoardHands(boardCards []string) ([]RankedHand, error) {
+	cards, board, err := parseBoard(boardCards)
+	if err != nil {
+		return nil, err
+	}
+	levels := boardLevels(board, 0)
+	hands := make([]RankedHand, len(levels))
+	for i, l := range levels {
+		hands[i] = l.ranked(cards)
+	}
+	return hands, nil
+}
+
+// RelativeStrength compares hole cards with every holding they could be up
+// against on a board
+func RelativeStrength(holeCards, boardCards []string) (*HandStrength, error) {
+	cards, board, err := parseBoard(boardCards)
+	if err != nil {
+		return nil, err
+	}
+	if len(holeCards) != 2 {
+		return nil, fmt.Errorf("expected 2 hole cards, got %d", len(holeCards))
+	}
+	hole, err := ParseCards(holeCards)
+	if err != nil {
+		return nil, err
+	}
+	var hero uint64
+	for i, c := range hole {
+		if (board|hero)&cardBit(c) != 0 {
+			return nil, fmt.Errorf("card %s used twice", holeCards[i])
+		}
+		hero |= cardBit(c)
+	}
+
+	score := evaluateMask(board | hero)
+	s := &HandStrength{
+		Nuts:     boardLevels(board, 0)[0].ranked(cards),
+		Position: 1,
+		Ahead:    []RankedHand{},
+	}
+	for _, l := range boardLevels(board, hero) {
+		switch {
+		case l.score > score:
+			s.Position++
+			s.CombosAhead += l.combos
+			if r := l.ranked(cards); len(s.Ahead) > 0 && s.Ahead[len(s.Ahead)-1].Value == r.Value {
+				s.Ahead[len(s.Ahead)-1].Combos += r.Combos
+			} else {
+				s.Ahead = append(s.Ahead, r)
+			}
+		case l.score == score:
+			s.CombosTied += l.combos
+		default:
+			s.CombosBehind += l.combos
+		}
+	}
+	s.Beats = float64(s.CombosBehind) / float64(s.CombosAhead+s.CombosTied+s.CombosBehind)
+	s.Label = nutsLabel(s.Position)
+	return s, nil
+}
+
+// nutsLabel names a position in the ranking: "nuts", "2nd nuts", ...
+func nutsLabel(position int) string {
+	if position == 1 {
+		return "nuts"
+	}
+	suffix := "th"
+	if position%100 < 11 || position%100 > 13 {
+		switch position % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s nuts", position, suffix)
+}
@@ -0,0 +1,116 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelativeStrength(t *testing.T) {
+	board := []string{"SK", "S9", "S5", "C2", "D7"}
+	tests := []struct {
+		name     string
+		hole     []string
+		board    []string
+		label    string
+		ahead    int
+		tied     int
+		minBeats float64
+		maxBeats float64
+	}{
+		{"blocking the nut flush", []string{"SA", "S3"}, board, "nuts", 0, 0, 1, 1},
+		{"king high flush", []string{"SQ", "SJ"}, board, "8th nuts", 7, 0, 0.99, 1},
+		{"king high", []string{"C3", "D4"}, board, "", -1, -1, 0, 0.3},
+		{"board plays", []string{"C2", "D3"}, []string{"HA", "DK", "CQ", "SJ", "HT"}, "nuts", 0, 990, 0, 0},
+		{"bottom set", []string{"H2", "D2"}, []string{"S9", "S8", "C2"}, "3rd nuts", 6, 0, 0.99, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := RelativeStrength(tt.hole, tt.board)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.label != "" && s.Label != tt.label {
+				t.Errorf("Expected %s, got %s", tt.label, s.Label)
+			}
+			if tt.ahead >= 0 && s.CombosAhead != tt.ahead {
+				t.Errorf("Expected %d combos ahead, got %d in %+v", tt.ahead, s.CombosAhead, s.Ahead)
+			}
+			if tt.tied >= 0 && s.CombosTied != tt.tied {
+				t.Errorf("Expected %d combos tied, got %d", tt.tied, s.CombosTied)
+			}
+			if s.Beats < tt.minBeats || s.Beats > tt.maxBeats {
+				t.Errorf("Expected to beat %v-%v of holdings, got %v", tt.minBeats, tt.maxBeats, s.Beats)
+			}
+			others := 45 * 44 / 2
+			if len(tt.board) == 3 {
+				others = 47 * 46 / 2
+			}
+			if total := s.CombosAhead + s.CombosTied + s.CombosBehind; total != others {
+				t.Errorf("Expected %d other holdings, got %d", others, total)
+			}
+		})
+	}
+
+	s, _ := RelativeStrength([]string{"SA", "S3"}, board)
+	if s.Nuts.Value != "Flush, Ace high" || strings.Join(s.Nuts.Example, ",") != "SA,SQ" {
+		t.Errorf("Expected the nuts to be AQ of spades, got %+v", s.Nuts)
+	}
+
+	s, _ = RelativeStrength([]string{"H2", "D2"}, []string{"S9", "S8", "C2"})
+	if s.Ahead[0].Value != "Three 9s" || s.Ahead[0].Combos != 3 || s.Ahead[1].Value != "Three 8s" {
+		t.Errorf("Unexpected hands ahead: %+v", s.Ahead)
+	}
+
+	// Flushes that differ only in kickers are listed together
+	s, _ = RelativeStrength([]string{"C3", "D4"}, board)
+	if s.Ahead[1].Value != "Flush, King high" || s.Ahead[1].Combos != 36 {
+		t.Errorf("Unexpected hands ahead: %+v", s.Ahead[:2])
+	}
+
+	errors := []struct {
+		hole, board []string
+	}{
+		{[]string{"SA"}, board},
+		{[]string{"SA", "SK"}, board},
+		{[]string{"SA", "C3"}, []string{"SK", "S9"}},
+		{[]string{"SA", "C3"}, []string{"SK", "S9", "SK"}},
+		{[]string{"SA", "X3"}, board},
+	}
+	for _, tt := range errors {
+		if _, err := RelativeStrength(tt.hole, tt.board); err == nil {
+			t.Errorf("Expected error for %v on %v", tt.hole, tt.board)
+		}
+	}
+}
+
+func TestBoardHands(t *testing.T) {
+	hands, err := BoardHands([]string{"SK", "S9", "S5", "C2", "D7"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	total := 0
+	for _, h := range hands {
+		total += h.Combos
+	}
+	if total != 47*46/2 {
+		t.Errorf("Expected every holding to be ranked, got %d", total)
+	}
+	if hands[0].Value != "Flush, Ace high" || hands[len(hands)-1].Hand != "High Card" {
+		t.Errorf("Unexpected ranking from %+v to %+v", hands[0], hands[len(hands)-1])
+	}
+
+	royal, _ := BoardHands([]string{"HA", "HK", "HQ", "HJ", "HT"})
+	if len(royal) != 1 || royal[0].Hand != "Royal Flush" {
+		t.Errorf("Expected every holding to play the board, got %+v", royal)
+	}
+}
+
+func TestNutsLabel(t *testing.T) {
+	for position, expected := range map[int]string{
+		1: "nuts", 2: "2nd nuts", 3: "3rd nuts", 4: "4th nuts", 11: "11th nuts", 12: "12th nuts", 22: "22nd nuts",
+	} {
+		if got := nutsLabel(position); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// Draw types
+const (
+	FlushDraw             = "flush draw"
+	OpenEndedStraightDraw = "open-ended straight draw"
+	Gutshot               = "gutshot"
+	BackdoorFlushDraw     = "backdoor flush draw"
+	BackdoorStraightDraw  = "backdoor straight draw"
+)
+
+// Out is a card that improves the hand. It is tainted when it also gives an
+// opponent a hand at least as good.
+type Out struct {
+	Card    string `json:"card"`
+	Tainted bool   `json:"tainted,omitempty"`
+}
+
+// OutGroup lists the outs that make the same hand
+type OutGroup struct {
+	Hand string `json:"hand"`
+	Outs []Out  `json:"outs"`
+}
+
+// Draw is a straight or flush draw and the cards that complete it. The
+// outs of a backdoor draw are those that keep it alive on the turn.
+type Draw struct {
+	Type string   `json:"type"`
+	Outs []string `json:"outs"`
+}
+
+// OutsAnalysis lists the cards that improve a hand on the flop or turn
+type OutsAnalysis struct {
+	Hand       string     `json:"hand"`
+	Groups     []OutGroup `json:"groups"`
+	Draws      []Draw     `json:"draws"`
+	Outs       int        `json:"outs"`
+	CleanOuts  int        `json:"cleanOuts"`
+	Unseen     int        `json:"unseen"`
+	NextCard   float64    `json:"nextCard"`   // Chance of an out on the next card
+	ByRiver    float64    `json:"byRiver"`    // Chance of an out by the river
+	CleanRiver float64    `json:"cleanRiver"` // Chance of a clean out by the river
+}
+
+// AnalyzeOuts finds every card that improves hole cards on a flop or turn
+// board. An out must raise the rank of the hand and the hole cards must play,
+// so pairing the board does not count as making two pair. With opponents'
+// hands, an out that leaves any of them level or ahead is tainted.
+func AnalyzeOuts(holeCards, boardCards []string, opponents [][]string) (*OutsAnalysis, error) {
+	if len(holeCards) != 2 {
+		return nil, fmt.Errorf("expected 2 hole cards, got %d", len(holeCards))
+	}
+	if len(boardCards) != 3 && len(boardCards) != 4 {
+		return nil, fmt.Errorf("outs need a flop or turn board, got %d cards", len(boardCards))
+	}
+
+	var used uint64
+	parse := func(strs []string) ([]Card, error) {
+		cards, err := ParseCards(strs)
+		if err != nil {
+			return nil, err
+		}
+		for i, c := range cards {
+			if used&cardBit(c) != 0 {
+				return nil, fmt.Errorf("card %s used twice", strs[i])
+			}
+			used |= cardBit(c)
+		}
+		return cards, nil
+	}
+	hole, err := parse(holeCards)
+	if err != nil {
+		return nil, err
+	}
+	board, err := parse(boardCards)
+	if err != nil {
+		return nil, err
+	}
+	opps := make([][]Card, len(opponents))
+	for i, o := range opponents {
+		if len(o) != 2 {
+			return nil, fmt.Errorf("expected 2 cards for opponent %d, got %d", i+1, len(o))
+		}
+		if opps[i], err = parse(o); err != nil {
+			return nil, err
+		}
+	}
+
+	hand := append(append([]Card{}, hole...), board...)
+	current := EvaluateBestHand(hand)
+	a := &OutsAnalysis{Hand: current.Rank.String(), Groups: []OutGroup{}, Draws: []Draw{}}
+
+	groups := make(map[HandRank][]Out)
+	for _, c := range unseenCards(used) {
+		a.Unseen++
+		made := EvaluateBestHand(append(hand, c))
+		if made.Rank <= current.Rank || made.Rank <= boardRank(append(append([]Card{}, board...), c)) {
+			continue
+		}
+		out := Out{Card: c.Suit + c.Rank}
+		for _, o := range opps {
+			if compareScores(EvaluateBestHand(append(append([]Card{c}, o...), board...)), made) >= 0 {
+				out.Tainted = true
+			}
+		}
+		groups[made.Rank] = append(groups[made.Rank], out)
+		a.Outs++
+		if !out.Tainted {
+			a.CleanOuts++
+		}
+	}
+	for rank := RoyalFlush; rank > current.Rank; rank-- {
+		if outs := groups[rank]; outs != nil {
+			a.Groups = append(a.Groups, OutGroup{Hand: rank.String(), Outs: outs})
+		}
+	}
+
+	a.Draws = findDraws(hole, board, used, current.Rank)
+
+	toCome := 5 - len(board)
+	a.NextCard = float64(a.Outs) / float64(a.Unseen)
+	a.ByRiver = hitChance(a.Unseen, a.Outs, toCome)
+	a.CleanRiver = hitChance(a.Unseen, a.CleanOuts, toCome)
+	return a, nil
+}
+
+// unseenCards lists the cards not in the mask, in a fixed order
+func unseenCards(used uint64) []Card {
+	var cards []Card
+	for _, suit := range []string{"S", "H", "D", "C"} {
+		for v := 14; v >= 2; v-- {
+			c := Card{Suit: suit, Rank: rankLetters[v-2 : v-1], Value: v}
+			if used&cardBit(c) == 0 {
+				cards = append(cards, c)
+			}
+		}
+	}
+	return cards
+}
+
+// boardRank is the rank of the board on its own. EvaluateBestHand needs five
+// cards, so smaller boards are ranked by their pairs.
+func boardRank(board []Card) HandRank {
+	if len(board) >= 5 {
+		return EvaluateBestHand(board).Rank
+	}
+	counts := make(map[int]int)
+	pairs := 0
+	best := 1
+	for _, c := range board {
+		counts[c.Value]++
+		if counts[c.Value] == 2 {
+			pairs++
+		}
+		if counts[c.Value] > best {
+			best = counts[c.Value]
+		}
+	}
+	switch {
+	case best == 4:
+		return FourOfAKind
+	case best == 3:
+		return ThreeOfAKind
+	case pairs == 2:
+		return TwoPair
+	case pairs == 1:
+		return OnePair
+	}
+	return HighCard
+}
+
+// hitChance is the chance that at least one of outs unseen cards comes in
+// the next toCome cards
+func hitChance(unseen, outs, toCome int) float64 {
+	miss := 1.0
+	for i := 0; i < toCome; i++ {
+		miss *= float64(unseen-outs-i) / float64(unseen-i)
+	}
+	return 1 - miss
+}
+
+// findDraws classifies the straight and flush draws that use a hole card
+func findDraws(hole, board []Card, used uint64, rank HandRank) []Draw {
+	draws := []Draw{}
+	cards := append(append([]Card{}, hole...), board...)
+	unseen := unseenCards(used)
+	cardsOf := func(keep func(c Card) bool) []string {
+		var out []string
+		for _, c := range unseen {
+			if keep(c) {
+				out = append(out, c.Suit+c.Rank)
+			}
+		}
+		return out
+	}
+
+	if rank < Flush {
+		for _, suit := range []string{"S", "H", "D", "C"} {
+			n, inHole := 0, false
+			for i, c := range cards {
+				if c.Suit == suit {
+					n++
+					inHole = inHole || i < 2
+				}
+			}
+			if !inHole {
+				continue
+			}
+			sameSuit := func(c Card) bool { return c.Suit == suit }
+			if n == 4 {
+				draws = append(draws, Draw{Type: FlushDraw, Outs: cardsOf(sameSuit)})
+			} else if n == 3 && len(board) == 3 {
+				draws = append(draws, Draw{Type: BackdoorFlushDraw, Outs: cardsOf(sameSuit)})
+			}
+		}
+	}
+
+	if rank < Straight {
+		ranks := rankMask(cards)
+		boardRanks := rankMask(board)
+		// Ranks that make a straight the hole cards play in
+		completes := func(ranks uint32) uint32 {
+			var m uint32
+			for r := uint32(0); r < 13; r++ {
+				bit := uint32(1) << r
+				if ranks&bit == 0 && straightHigh(ranks|bit) != 0 && straightHigh(boardRanks|bit) == 0 {
+					m |= bit
+				}
+			}
+			return m
+		}
+		ofRanks := func(m uint32) func(c Card) bool {
+			return func(c Card) bool { return m&(1<<uint(c.Value-2)) != 0 }
+		}
+
+		if m := completes(ranks); bits.OnesCount32(m) >= 2 {
+			draws = append(draws, Draw{Type: OpenEndedStraightDraw, Outs: cardsOf(ofRanks(m))})
+		} else if m != 0 {
+			draws = append(draws, Draw{Type: Gutshot, Outs: cardsOf(ofRanks(m))})
+		} else if len(board) == 3 {
+			// A backdoor draw needs a turn card that leaves a straight draw
+			var turns uint32
+			for r := uint32(0); r < 13; r++ {
+				bit := uint32(1) << r
+				if ranks&bit == 0 && completes(ranks|bit) != 0 {
+					turns |= bit
+				}
+			}
+			if turns != 0 {
+				draws = append(draws, Draw{Type: BackdoorStraightDraw, Outs: cardsOf(ofRanks(turns))})
+			}
+		}
+	}
+
+	sort.SliceStable(draws, func(i, j int) bool { return len(draws[i].Outs) > len(draws[j].Outs) })
+	return draws
+}
+
+func rankMask(cards []Card) uint32 {
+	var m uint32
+	for _, c := range cards {
+		m |= 1 << uint(c.Value-2)
+	}
+	return m
+}
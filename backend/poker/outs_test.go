@@ -0,0 +1,166 @@
+package poker
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func drawTypes(a *OutsAnalysis) map[string]int {
+	types := make(map[string]int)
+	for _, d := range a.Draws {
+		types[d.Type] = len(d.Outs)
+	}
+	return types
+}
+
+func groupSizes(a *OutsAnalysis) map[string]int {
+	sizes := make(map[string]int)
+	for _, g := range a.Groups {
+		sizes[g.Hand] = len(g.Outs)
+	}
+	return sizes
+}
+
+func TestAnalyzeOuts(t *testing.T) {
+	tests := []struct {
+		name      string
+		hole      []string
+		board     []string
+		opponents [][]string
+		groups    map[string]int
+		draws     map[string]int
+		clean     int
+	}{
+		{
+			name:   "flush draw and overcards",
+			hole:   []string{"HA", "HK"},
+			board:  []string{"H7", "H2", "C9"},
+			groups: map[string]int{"Flush": 9, "One Pair": 6},
+			draws:  map[string]int{FlushDraw: 9},
+			clean:  15,
+		},
+		{
+			name:   "open-ended",
+			hole:   []string{"H8", "D9"},
+			board:  []string{"CT", "S7", "H2"},
+			groups: map[string]int{"Straight": 8, "One Pair": 6},
+			draws:  map[string]int{OpenEndedStraightDraw: 8},
+			clean:  14,
+		},
+		{
+			name:   "gutshot on the turn",
+			hole:   []string{"H9", "D8"},
+			board:  []string{"C6", "S5", "HK", "D2"},
+			groups: map[string]int{"Straight": 4, "One Pair": 6},
+			draws:  map[string]int{Gutshot: 4},
+			clean:  10,
+		},
+		{
+			name:      "tainted by a flush draw",
+			hole:      []string{"H7", "H6"},
+			board:     []string{"S8", "S9", "D2"},
+			opponents: [][]string{{"SA", "SK"}},
+			groups:    map[string]int{"Straight": 8, "One Pair": 6},
+			draws:     map[string]int{OpenEndedStraightDraw: 8},
+			clean:     10,
+		},
+		{
+			name:   "backdoor draws",
+			hole:   []string{"HA", "HK"},
+			board:  []string{"HQ", "C5", "D2"},
+			groups: map[string]int{"One Pair": 6},
+			draws:  map[string]int{BackdoorFlushDraw: 10, BackdoorStraightDraw: 16},
+			clean:  6,
+		},
+		{
+			name:   "set",
+			hole:   []string{"H5", "D5"},
+			board:  []string{"C5", "SK", "D9"},
+			groups: map[string]int{"Four of a Kind": 1, "Full House": 6},
+			draws:  map[string]int{},
+			clean:  7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := AnalyzeOuts(tt.hole, tt.board, tt.opponents)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			groups := groupSizes(a)
+			for hand, n := range tt.groups {
+				if groups[hand] != n {
+					t.Errorf("Expected %d outs to %s, got %+v", n, hand, a.Groups)
+				}
+			}
+			if len(groups) != len(tt.groups) {
+				t.Errorf("Expected groups %v, got %v", tt.groups, groups)
+			}
+			draws := drawTypes(a)
+			for draw, n := range tt.draws {
+				if draws[draw] != n {
+					t.Errorf("Expected a %s with %d outs, got %+v", draw, n, a.Draws)
+				}
+			}
+			if len(draws) != len(tt.draws) {
+				t.Errorf("Expected draws %v, got %v", tt.draws, draws)
+			}
+			if a.CleanOuts != tt.clean {
+				t.Errorf("Expected %d clean outs, got %d", tt.clean, a.CleanOuts)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOutsChances(t *testing.T) {
+	a, _ := AnalyzeOuts([]string{"HA", "HK"}, []string{"H7", "H2", "C9"}, nil)
+	if a.Unseen != 47 || a.Outs != 15 {
+		t.Fatalf("Expected 15 outs in 47 cards, got %d in %d", a.Outs, a.Unseen)
+	}
+	if math.Abs(a.NextCard-15.0/47) > 1e-9 || math.Abs(a.ByRiver-(1-32.0/47*31/46)) > 1e-9 {
+		t.Errorf("Unexpected chances %v and %v", a.NextCard, a.ByRiver)
+	}
+	for _, g := range a.Groups {
+		for _, o := range g.Outs {
+			if o.Card[0] != 'H' && strings.ContainsRune("972", rune(o.Card[1])) {
+				t.Errorf("Expected pairing the board not to count, got %s", o.Card)
+			}
+		}
+	}
+
+	// The opponent's cards are not unseen, and spades are tainted
+	a, _ = AnalyzeOuts([]string{"H7", "H6"}, []string{"S8", "S9", "D2"}, [][]string{{"SA", "SK"}})
+	if a.Unseen != 45 {
+		t.Errorf("Expected 45 unseen cards, got %d", a.Unseen)
+	}
+	for _, g := range a.Groups {
+		for _, o := range g.Outs {
+			if o.Tainted != (o.Card[0] == 'S') {
+				t.Errorf("Expected only spades to be tainted, got %+v", o)
+			}
+		}
+	}
+
+	// On the turn there is one card to come
+	a, _ = AnalyzeOuts([]string{"H9", "D8"}, []string{"C6", "S5", "HK", "D2"}, nil)
+	if math.Abs(a.NextCard-a.ByRiver) > 1e-9 {
+		t.Errorf("Expected the same chances on the turn, got %v and %v", a.NextCard, a.ByRiver)
+	}
+
+	errors := []struct {
+		hole, board []string
+		opponents   [][]string
+	}{
+		{[]string{"HA"}, []string{"H7", "H2", "C9"}, nil},
+		{[]string{"HA", "HK"}, []string{"H7", "H2", "C9", "C3", "C4"}, nil},
+		{[]string{"HA", "HK"}, []string{"H7", "H2", "HA"}, nil},
+		{[]string{"HA", "HK"}, []string{"H7", "H2", "C9"}, [][]string{{"SA"}}},
+		{[]string{"HA", "HK"}, []string{"H7", "H2", "C9"}, [][]string{{"SA", "H7"}}},
+	}
+	for _, tt := range errors {
+		if _, err := AnalyzeOuts(tt.hole, tt.board, tt.opponents); err == nil {
+			t.Errorf("Expected error for %v %v %v", tt.hole, tt.board, tt.opponents)
+		}
+	}
+}
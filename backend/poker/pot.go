@@ -0,0 +1,118 @@
+package poker
+
+import "sort"
+
+// PotPlayer is one player's stake in a hand at showdown time. Players are
+// passed in position order, starting with the first seat to the left of the
+// button, so that odd chips can be awarded by position.
+type PotPlayer struct {
+	Contribution int
+	Folded       bool
+	Score        HandScore
+}
+
+// Pot is a main pot or side pot and the players who can win it
+type Pot struct {
+	Amount   int
+	Eligible []int
+}
+
+// BuildPots splits the players' contributions into a main pot followed by
+// side pots. A new pot is started at every distinct all-in level of the
+// players still in the hand; chips put in by folded players are counted in
+// every pot their contribution reaches but they are never eligible to win.
+func BuildPots(players []PotPlayer) []Pot {
+	var levels []int
+	seen := make(map[int]bool)
+	for _, p := range players {
+		if p.Folded || p.Contribution <= 0 || seen[p.Contribution] {
+			continue
+		}
+		seen[p.Contribution] = true
+		levels = append(levels, p.Contribution)
+	}
+	sort.Ints(levels)
+
+	var pots []Pot
+	prev := 0
+	for _, level := range levels {
+		pot := Pot{}
+		for i, p := range players {
+			pot.Amount += clampContribution(p.Contribution, prev, level)
+			if !p.Folded && p.Contribution >= level {
+				pot.Eligible = append(pot.Eligible, i)
+			}
+		}
+		pots = append(pots, pot)
+		prev = level
+	}
+
+	// Dead money above the highest live contribution goes into the last pot
+	dead := 0
+	for _, p := range players {
+		if p.Contribution > prev {
+			dead += p.Contribution - prev
+		}
+	}
+	if dead > 0 {
+		if len(pots) == 0 {
+			return []Pot{{Amount: dead}}
+		}
+		pots[len(pots)-1].Amount += dead
+	}
+
+	return pots
+}
+
+func clampContribution(contribution, low, high int) int {
+	if contribution <= low {
+		return 0
+	}
+	if contribution > high {
+		return high - low
+	}
+	return contribution - low
+}
+
+// DistributePots awards every pot to the best eligible hands and returns the
+// amount won by each player.
+func DistributePots(players []PotPlayer) []int {
+	payouts := make([]int, len(players))
+	for _, pot := range BuildPots(players) {
+		for i, share := range SplitPot(players, pot) {
+			payouts[i] += share
+		}
+	}
+	return payouts
+}
+
+// SplitPot returns each player's share of a single pot. The pot goes to the
+// best eligible hand; split pots are divided evenly and any odd chips go one
+// at a time to the winners in position order.
+func SplitPot(players []PotPlayer, pot Pot) []int {
+	shares := make([]int, len(players))
+	if len(pot.Eligible) == 0 {
+		return shares
+	}
+
+	winners := []int{pot.Eligible[0]}
+	for _, i := range pot.Eligible[1:] {
+		cmp := compareScores(players[i].Score, players[winners[0]].Score)
+		if cmp > 0 {
+			winners = []int{i}
+		} else if cmp == 0 {
+			winners = append(winners, i)
+		}
+	}
+
+	share := pot.Amount / len(winners)
+	remainder := pot.Amount % len(winners)
+	for j, i := range winners {
+		shares[i] = share
+		if j < remainder {
+			shares[i]++
+		}
+	}
+
+	return shares
+}
@@ -0,0 +1,219 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func scoreOf(t *testing.T, cardStrs ...string) HandScore {
+	t.Helper()
+	cards, err := ParseCards(cardStrs)
+	if err != nil {
+		t.Fatalf("Failed to parse %v: %v", cardStrs, err)
+	}
+	return EvaluateBestHand(cards)
+}
+
+func TestBuildPots(t *testing.T) {
+	tests := []struct {
+		name     string
+		players  []PotPlayer
+		expected []Pot
+	}{
+		{
+			name: "Everyone matched - single pot",
+			players: []PotPlayer{
+				{Contribution: 100}, {Contribution: 100}, {Contribution: 100},
+			},
+			expected: []Pot{{Amount: 300, Eligible: []int{0, 1, 2}}},
+		},
+		{
+			name: "One short all-in - main and side pot",
+			players: []PotPlayer{
+				{Contribution: 50}, {Contribution: 200}, {Contribution: 200},
+			},
+			expected: []Pot{
+				{Amount: 150, Eligible: []int{0, 1, 2}},
+				{Amount: 300, Eligible: []int{1, 2}},
+			},
+		},
+		{
+			name: "Three different all-in amounts",
+			players: []PotPlayer{
+				{Contribution: 25}, {Contribution: 75}, {Contribution: 150}, {Contribution: 150},
+			},
+			expected: []Pot{
+				{Amount: 100, Eligible: []int{0, 1, 2, 3}},
+				{Amount: 150, Eligible: []int{1, 2, 3}},
+				{Amount: 150, Eligible: []int{2, 3}},
+			},
+		},
+		{
+			name: "Folded contributor adds dead money but is not eligible",
+			players: []PotPlayer{
+				{Contribution: 60, Folded: true}, {Contribution: 40}, {Contribution: 100},
+			},
+			expected: []Pot{
+				{Amount: 120, Eligible: []int{1, 2}},
+				{Amount: 80, Eligible: []int{2}},
+			},
+		},
+		{
+			name: "Folded player put in more than every live player",
+			players: []PotPlayer{
+				{Contribution: 300, Folded: true}, {Contribution: 100}, {Contribution: 100},
+			},
+			expected: []Pot{{Amount: 500, Eligible: []int{1, 2}}},
+		},
+		{
+			name: "Uncalled chips form a pot only the bettor can win",
+			players: []PotPlayer{
+				{Contribution: 30}, {Contribution: 500},
+			},
+			expected: []Pot{
+				{Amount: 60, Eligible: []int{0, 1}},
+				{Amount: 470, Eligible: []int{1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pots := BuildPots(tt.players)
+			if !reflect.DeepEqual(pots, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, pots)
+			}
+		})
+	}
+}
+
+func TestDistributePots(t *testing.T) {
+	board := []string{"D2", "C7", "H9", "SJ", "D4"}
+	with := func(hole ...string) []string {
+		return append(hole, board...)
+	}
+
+	nuts := scoreOf(t, with("HJ", "CJ")...)   // Trip jacks
+	second := scoreOf(t, with("H9", "C9")...) // Trip nines
+	third := scoreOf(t, with("SA", "DJ")...)  // Pair of jacks
+	third2 := scoreOf(t, with("HA", "CJ")...) // Same pair of jacks, same kicker
+	third3 := scoreOf(t, with("CA", "HJ")...) // And once more
+	worst := scoreOf(t, with("S3", "H5")...)  // Jack high
+
+	tests := []struct {
+		name     string
+		players  []PotPlayer
+		expected []int
+	}{
+		{
+			name: "Best hand wins everything",
+			players: []PotPlayer{
+				{Contribution: 100, Score: worst},
+				{Contribution: 100, Score: nuts},
+				{Contribution: 100, Score: second},
+			},
+			expected: []int{0, 300, 0},
+		},
+		{
+			name: "Short stack wins main pot, side pot goes to next best",
+			players: []PotPlayer{
+				{Contribution: 50, Score: nuts},
+				{Contribution: 200, Score: second},
+				{Contribution: 200, Score: worst},
+			},
+			expected: []int{150, 300, 0},
+		},
+		{
+			name: "Folded contributor with the best cards wins nothing",
+			players: []PotPlayer{
+				{Contribution: 100, Folded: true, Score: nuts},
+				{Contribution: 100, Score: worst},
+				{Contribution: 100, Score: second},
+			},
+			expected: []int{0, 0, 300},
+		},
+		{
+			name: "Two-way tie splits evenly",
+			players: []PotPlayer{
+				{Contribution: 100, Score: third},
+				{Contribution: 100, Score: third2},
+				{Contribution: 100, Score: worst},
+			},
+			expected: []int{150, 150, 0},
+		},
+		{
+			name: "Odd chip goes to the first winner in position order",
+			players: []PotPlayer{
+				{Contribution: 1, Folded: true, Score: nuts},
+				{Contribution: 50, Score: third},
+				{Contribution: 50, Score: third2},
+			},
+			expected: []int{0, 51, 50},
+		},
+		{
+			name: "Three-way tie with two odd chips",
+			players: []PotPlayer{
+				{Contribution: 2, Folded: true, Score: worst},
+				{Contribution: 100, Score: third},
+				{Contribution: 100, Score: third2},
+				{Contribution: 100, Score: third3},
+			},
+			expected: []int{0, 101, 101, 100},
+		},
+		{
+			name: "Three-way tie for the main pot, side pot to a single winner",
+			players: []PotPlayer{
+				{Contribution: 40, Score: third},
+				{Contribution: 40, Score: third2},
+				{Contribution: 100, Score: third3},
+				{Contribution: 100, Score: worst},
+			},
+			expected: []int{54, 53, 173, 0},
+		},
+		{
+			name: "Short stack loses, side pot split between the covering players",
+			players: []PotPlayer{
+				{Contribution: 30, Score: worst},
+				{Contribution: 120, Score: third},
+				{Contribution: 120, Folded: true, Score: nuts},
+				{Contribution: 120, Score: third2},
+			},
+			expected: []int{0, 195, 0, 195},
+		},
+		{
+			name: "Uncalled excess returns to the bettor",
+			players: []PotPlayer{
+				{Contribution: 30, Score: nuts},
+				{Contribution: 500, Score: worst},
+			},
+			expected: []int{60, 470},
+		},
+		{
+			name: "Everyone else folded",
+			players: []PotPlayer{
+				{Contribution: 10, Folded: true},
+				{Contribution: 20, Folded: true},
+				{Contribution: 40, Score: worst},
+			},
+			expected: []int{0, 0, 70},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payouts := DistributePots(tt.players)
+			if !reflect.DeepEqual(payouts, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, payouts)
+			}
+
+			total, paid := 0, 0
+			for i, p := range tt.players {
+				total += p.Contribution
+				paid += payouts[i]
+			}
+			if total != paid {
+				t.Errorf("Chips not conserved: contributed %d, paid %d", total, paid)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+)
+
+// Potential holds the hand strength metrics of Billings et al., "Opponent
+// Modeling in Poker" (1998), measured against every opponent holding,
+// weighted by a range
+type Potential struct {
+	HS        float64 `json:"hs"`   // Share of holdings beaten now, ties counting half
+	HSN       float64 `json:"hsN"`  // HS against every opponent at once, HS^n
+	PPot      float64 `json:"ppot"` // Chance of going from behind to ahead by the river
+	NPot      float64 `json:"npot"` // Chance of going from ahead to behind by the river
+	EHS       float64 `json:"ehs"`  // Effective hand strength, HSn(1-NPot) + (1-HSn)PPot
+	Opponents int     `json:"opponents"`
+	Holdings  float64 `json:"holdings"` // Weighted count of opponent holdings
+}
+
+// Indexes into the ahead/tied/behind tables
+const (
+	ahead = iota
+	tied
+	behind
+)
+
+func standing(hero, villain uint32) int {
+	switch {
+	case hero > villain:
+		return ahead
+	case hero == villain:
+		return tied
+	}
+	return behind
+}
+
+// cardMask returns the bit of a card index 0-51, as used by evaluateMask
+func cardMask(i int) uint64 {
+	return 1 << uint(16*(i/13)+i%13)
+}
+
+// HandPotential computes HS, PPOT, NPOT and EHS for hole cards on a flop,
+// turn or river board by enumerating every opponent holding and every
+// runout. Holdings are weighted by the range, nil meaning any two cards.
+// HS is raised to the power of the number of opponents, which assumes they
+// hold independent hands from the same range.
+func HandPotential(holeCards, boardCards []string, r *HandRange, opponents int) (*Potential, error) {
+	if len(holeCards) != 2 {
+		return nil, fmt.Errorf("expected 2 hole cards, got %d", len(holeCards))
+	}
+	if opponents < 1 || opponents > MaxPreflopOpponents {
+		return nil, fmt.Errorf("opponents must be between 1 and %d", MaxPreflopOpponents)
+	}
+	_, board, err := parseBoard(boardCards)
+	if err != nil {
+		return nil, err
+	}
+	hole, err := ParseCards(holeCards)
+	if err != nil {
+		return nil, err
+	}
+	var hero uint64
+	for i, c := range hole {
+		if (board|hero)&cardBit(c) != 0 {
+			return nil, fmt.Errorf("card %s used twice", holeCards[i])
+		}
+		hero |= cardBit(c)
+	}
+	if r == nil {
+		r = FullRange()
+	}
+
+	// The runouts from the current board to the river, with the hero's
+	// final score on each
+	var runouts []uint64
+	toCome := 5 - len(boardCards)
+	for i := 0; i < 52 && toCome > 0; i++ {
+		a := cardMask(i)
+		if (board|hero)&a != 0 {
+			continue
+		}
+		if toCome == 1 {
+			runouts = append(runouts, a)
+			continue
+		}
+		for j := i + 1; j < 52; j++ {
+			if b := cardMask(j); (board|hero|a)&b == 0 {
+				runouts = append(runouts, a|b)
+			}
+		}
+	}
+	heroFinal := make([]uint32, len(runouts))
+	for k, run := range runouts {
+		heroFinal[k] = evaluateMask(board | hero | run)
+	}
+
+	var hp [3][3]float64
+	var totals [3]float64
+	heroNow := evaluateMask(board | hero)
+	p := &Potential{Opponents: opponents}
+	for i := 0; i < 52; i++ {
+		a := cardMask(i)
+		if (board|hero)&a != 0 {
+			continue
+		}
+		for j := i + 1; j < 52; j++ {
+			b := cardMask(j)
+			w := r[comboClass(i, j)]
+			if (board|hero)&b != 0 || w == 0 {
+				continue
+			}
+			villain := a | b
+			now := standing(heroNow, evaluateMask(board|villain))
+			totals[now] += w
+			p.Holdings += w
+			if len(runouts) == 0 {
+				continue
+			}
+
+			// Runouts that use the villain's cards are skipped, so each
+			// holding's weight is spread over the rest
+			var counts [3]float64
+			n := 0
+			for k, run := range runouts {
+				if run&villain != 0 {
+					continue
+				}
+				counts[standing(heroFinal[k], evaluateMask(board|villain|run))]++
+				n++
+			}
+			for later, c := range counts {
+				hp[now][later] += w * c / float64(n)
+			}
+		}
+	}
+	if p.Holdings == 0 {
+		return nil, fmt.Errorf("the range holds no hands that are not on the board")
+	}
+
+	p.HS = (totals[ahead] + totals[tied]/2) / p.Holdings
+	if d := totals[behind] + totals[tied]/2; d > 0 && len(runouts) > 0 {
+		p.PPot = (hp[behind][ahead] + hp[behind][tied]/2 + hp[tied][ahead]/2) / d
+	}
+	if d := totals[ahead] + totals[tied]/2; d > 0 && len(runouts) > 0 {
+		p.NPot = (hp[ahead][behind] + hp[tied][behind]/2 + hp[ahead][tied]/2) / d
+	}
+	p.HSN = math.Pow(p.HS, float64(opponents))
+	p.EHS = p.HSN*(1-p.NPot) + (1-p.HSN)*p.PPot
+	return p, nil
+}
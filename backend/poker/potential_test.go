@@ -0,0 +1,78 @@
+package poker
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHandPotential(t *testing.T) {
+	// The worked example from Billings et al.
+	p, err := HandPotential([]string{"DA", "CQ"}, []string{"H3", "C4", "HJ"}, nil, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(p.HS-0.585) > 0.001 || math.Abs(p.PPot-0.208) > 0.001 || math.Abs(p.NPot-0.274) > 0.001 {
+		t.Errorf("Expected HS 0.585, PPOT 0.208 and NPOT 0.274, got %+v", p)
+	}
+	if p.Holdings != 1081 || math.Abs(p.EHS-(p.HS*(1-p.NPot)+(1-p.HS)*p.PPot)) > 1e-9 {
+		t.Errorf("Unexpected holdings or EHS: %+v", p)
+	}
+
+	two, _ := HandPotential([]string{"DA", "CQ"}, []string{"H3", "C4", "HJ"}, nil, 2)
+	if math.Abs(two.HSN-p.HS*p.HS) > 1e-9 || two.EHS >= p.EHS {
+		t.Errorf("Expected a lower EHS against two opponents, got %+v", two)
+	}
+
+	// Against a range of overpairs and sets the hand is always behind now.
+	// The board and hole cards leave 3 combos of JJ, QQ and AA.
+	r, _ := ParseRange("JJ+")
+	p, _ = HandPotential([]string{"DA", "CQ"}, []string{"H3", "C4", "HJ"}, r, 1)
+	if p.HS != 0 || p.NPot != 0 || p.PPot <= 0 || p.Holdings != 15 {
+		t.Errorf("Unexpected potential against JJ+: %+v", p)
+	}
+
+	tests := []struct {
+		name  string
+		hole  []string
+		board []string
+		hs    float64
+	}{
+		{"river nuts", []string{"HA", "HK"}, []string{"HQ", "HJ", "HT", "C2", "D3"}, 1},
+		{"river board plays", []string{"C2", "D3"}, []string{"HA", "DK", "CQ", "SJ", "HT"}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := HandPotential(tt.hole, tt.board, nil, 1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if p.HS != tt.hs || p.PPot != 0 || p.NPot != 0 || p.EHS != tt.hs {
+				t.Errorf("Expected HS and EHS %v with no potential on the river, got %+v", tt.hs, p)
+			}
+		})
+	}
+
+	// A flush draw with two overcards on the turn has about 15 outs in 44
+	p, _ = HandPotential([]string{"HA", "HK"}, []string{"H7", "H2", "C9", "D4"}, nil, 1)
+	if math.Abs(p.PPot-15.0/44) > 0.05 {
+		t.Errorf("Expected PPOT close to 15 outs in 44, got %+v", p)
+	}
+
+	none, _ := ParseRange("")
+	errors := []struct {
+		hole, board []string
+		r           *HandRange
+		opponents   int
+	}{
+		{[]string{"DA"}, []string{"H3", "C4", "HJ"}, nil, 1},
+		{[]string{"DA", "CQ"}, []string{"H3", "C4"}, nil, 1},
+		{[]string{"DA", "CQ"}, []string{"H3", "C4", "DA"}, nil, 1},
+		{[]string{"DA", "CQ"}, []string{"H3", "C4", "HJ"}, nil, 0},
+		{[]string{"DA", "CQ"}, []string{"H3", "C4", "HJ"}, none, 1},
+	}
+	for _, tt := range errors {
+		if _, err := HandPotential(tt.hole, tt.board, tt.r, tt.opponents); err == nil {
+			t.Errorf("Expected error for %v on %v", tt.hole, tt.board)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package poker
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// The preflop equity table is generated offline, see cmd/preflopgen
+//go:generate go run ../cmd/preflopgen -o preflop_equity.bin
+
+//go:embed preflop_equity.bin
+var preflopData []byte
+
+// NumHandClasses is the number of starting hands up to suit isomorphism:
+// 13 pairs, 78 suited and 78 offsuit hands
+const NumHandClasses = 169
+
+// MaxPreflopOpponents is the most random opponents the table covers
+const MaxPreflopOpponents = 9
+
+// rankOrder lists ranks from the top, in the order of the usual 13x13
+// starting hand grid
+const rankOrder = "AKQJT98765432"
+
+// Odds are the chances of winning and tying a hand and the share of the
+// pot won on average, split pots included. Odds estimated by sampling carry
+// the margin of error of their 95% confidence interval; exact odds have
+// none.
+type Odds struct {
+	Win    float64 `json:"win"`
+	Tie    float64 `json:"tie"`
+	Equity float64 `json:"equity"`
+	Margin float64 `json:"margin"`
+}
+
+// Loss is the chance of losing the hand outright
+func (o Odds) Loss() float64 {
+	return 1 - o.Win - o.Tie
+}
+
+// SamplingMargin returns the margin of error, at 95% confidence, of a
+// probability p estimated from n samples
+func SamplingMargin(p float64, n int) float64 {
+	return 1.96 * math.Sqrt(p*(1-p)/float64(n))
+}
+
+// samplingMargin is the widest margin of the odds' chances and equity, when
+// estimated from n samples. A share of the pot varies no more than a
+// chance with the same mean, so its margin is bounded the same way.
+func (o Odds) samplingMargin(n int) float64 {
+	return math.Max(SamplingMargin(o.Win, n), math.Max(SamplingMargin(o.Tie, n), SamplingMargin(o.Equity, n)))
+}
+
+// HandClassName returns the name of a starting hand class by index. Classes
+// are numbered row by row through the 13x13 grid with aces first: pairs on
+// the diagonal, suited hands above it and offsuit hands below.
+func HandClassName(i int) string {
+	hi, lo := i/13, i%13
+	switch {
+	case hi == lo:
+		return rankOrder[hi:hi+1] + rankOrder[hi:hi+1]
+	case hi < lo:
+		return rankOrder[hi:hi+1] + rankOrder[lo:lo+1] + "s"
+	default:
+		return rankOrder[lo:lo+1] + rankOrder[hi:hi+1] + "o"
+	}
+}
+
+// HandClassIndex parses a starting hand class such as "AKs", "T9o" or "77"
+func HandClassIndex(name string) (int, error) {
+	name = strings.ToUpper(name)
+	if len(name) < 2 || len(name) > 3 {
+		return 0, fmt.Errorf("invalid hand class: %s", name)
+	}
+	r1, r2 := strings.IndexByte(rankOrder, name[0]), strings.IndexByte(rankOrder, name[1])
+	if r1 < 0 || r2 < 0 {
+		return 0, fmt.Errorf("invalid hand class: %s", name)
+	}
+	if r1 > r2 {
+		r1, r2 = r2, r1
+	}
+	switch {
+	case len(name) == 2 && r1 == r2:
+		return r1*13 + r1, nil
+	case len(name) == 3 && r1 != r2 && name[2] == 'S':
+		return r1*13 + r2, nil
+	case len(name) == 3 && r1 != r2 && name[2] == 'O':
+		return r2*13 + r1, nil
+	}
+	return 0, fmt.Errorf("invalid hand class: %s", name)
+}
+
+// HandClass returns the starting hand class of two hole cards, e.g. "AKs"
+func HandClass(holeCards []string) (string, error) {
+	if len(holeCards) != 2 {
+		return "", fmt.Errorf("expected 2 hole cards, got %d", len(holeCards))
+	}
+	cards, err := ParseCards(holeCards)
+	if err != nil {
+		return "", err
+	}
+	if cards[0] == cards[1] {
+		return "", fmt.Errorf("card %s used twice", holeCards[0])
+	}
+	hi, lo := 14-cards[0].Value, 14-cards[1].Value
+	if hi > lo {
+		hi, lo = lo, hi
+	}
+	if hi != lo && cards[0].Suit != cards[1].Suit {
+		hi, lo = lo, hi
+	}
+	return HandClassName(hi*13 + lo), nil
+}
+
+// classCombos returns the card masks of every hand in a class
+func classCombos(class int) []uint64 {
+	hi, lo := class/13, class%13
+	bit := func(rank, suit int) uint64 { return 1 << uint(16*suit+12-rank) }
+	var combos []uint64
+	for s1 := 0; s1 < 4; s1++ {
+		for s2 := 0; s2 < 4; s2++ {
+			switch {
+			case hi == lo && s1 < s2,
+				hi < lo && s1 == s2,
+				hi > lo && s1 != s2:
+				combos = append(combos, bit(hi, s1)|bit(lo, s2))
+			}
+		}
+	}
+	return combos
+}
+
+// PreflopTable holds the all-in equity of every starting hand class heads
+// up against every other, and against one to nine random hands
+type PreflopTable struct {
+	HeadsUp  [NumHandClasses][NumHandClasses]Odds
+	VsRandom [NumHandClasses][MaxPreflopOpponents]Odds // Indexed by opponents - 1
+
+	// MultiwaySamples is how many boards each VsRandom entry was sampled
+	// from; HeadsUp is exact
+	MultiwaySamples int
+}
+
+// randomCard returns a card not in used
+func randomCard(rng *rand.Rand, used uint64) uint64 {
+	for {
+		c := rng.Intn(52)
+		bit := uint64(1) << uint(16*(c/13)+c%13)
+		if used&bit == 0 {
+			return bit
+		}
+	}
+}
+
+func randomBoard(rng *rand.Rand, used uint64) uint64 {
+	var board uint64
+	for i := 0; i < 5; i++ {
+		board |= randomCard(rng, used|board)
+	}
+	return board
+}
+
+// suitRelabellings lists the 24 ways of relabelling the four suits
+var suitRelabellings = func() [][4]uint {
+	var perms [][4]uint
+	for i := 0; i < 256; i++ {
+		p := [4]uint{uint(i & 3), uint(i >> 2 & 3), uint(i >> 4 & 3), uint(i >> 6)}
+		if 1<<p[0]|1<<p[1]|1<<p[2]|1<<p[3] == 15 {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}()
+
+func relabelSuits(m uint64, p [4]uint) uint64 {
+	var out uint64
+	for s := uint(0); s < 4; s++ {
+		out |= (m >> (16 * s) & 0x1fff) << (16 * p[s])
+	}
+	return out
+}
+
+// canonicalMatchup returns the smallest relabelling of the suits of two
+// hands. Matchups with the same one have the same odds.
+func canonicalMatchup(a, b uint64) [2]uint64 {
+	var best [2]uint64
+	for i, p := range suitRelabellings {
+		m := [2]uint64{relabelSuits(a, p), relabelSuits(b, p)}
+		if i == 0 || m[0] < best[0] || m[0] == best[0] && m[1] < best[1] {
+			best = m
+		}
+	}
+	return best
+}
+
+// enumerateHeadsUp deals every board to two hands, counting the first's
+// wins and ties
+func enumerateHeadsUp(a, b uint64) (wins, ties, boards int) {
+	var deck []uint64
+	for s := 0; s < 4; s++ {
+		for r := 0; r < 13; r++ {
+			if bit := uint64(1) << uint(16*s+r); (a|b)&bit == 0 {
+				deck = append(deck, bit)
+			}
+		}
+	}
+	n := len(deck)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			b2 := deck[i] | deck[j]
+			for k := j + 1; k < n; k++ {
+				b3 := b2 | deck[k]
+				for l := k + 1; l < n; l++ {
+					b4 := b3 | deck[l]
+					for m := l + 1; m < n; m++ {
+						board := b4 | deck[m]
+						sa, sb := evaluateMask(a|board), evaluateMask(b|board)
+						if sa > sb {
+							wins++
+						} else if sa == sb {
+							ties++
+						}
+						boards++
+					}
+				}
+			}
+		}
+	}
+	return wins, ties, boards
+}
+
+// headsUpOdds returns the exact odds of class a against class b, averaged
+// over every pair of their hands that do not share a card. Pairs equal up
+// to suits are enumerated once.
+func headsUpOdds(combos [][]uint64, a, b int) Odds {
+	matchups := make(map[[2]uint64]int)
+	for _, ca := range combos[a] {
+		for _, cb := range combos[b] {
+			if ca&cb == 0 {
+				matchups[canonicalMatchup(ca, cb)]++
+			}
+		}
+	}
+	wins, ties, total := 0, 0, 0
+	for m, n := range matchups {
+		w, t, boards := enumerateHeadsUp(m[0], m[1])
+		wins, ties, total = wins+n*w, ties+n*t, total+n*boards
+	}
+	win, tie := float64(wins)/float64(total), float64(ties)/float64(total)
+	return Odds{Win: win, Tie: tie, Equity: win + tie/2}
+}
+
+// BuildPreflopTable computes the table. Heads-up matchups are enumerated
+// exactly; the odds against random hands are estimated from multiwaySamples
+// boards per class and number of opponents. Progress, if set, is called
+// after each class.
+func BuildPreflopTable(multiwaySamples int, seed int64, progress func(done, total int)) *PreflopTable {
+	t := &PreflopTable{MultiwaySamples: multiwaySamples}
+	rng := rand.New(rand.NewSource(seed))
+	combos := make([][]uint64, NumHandClasses)
+	for i := range combos {
+		combos[i] = classCombos(i)
+	}
+
+	for a := 0; a < NumHandClasses; a++ {
+		for b := a; b < NumHandClasses; b++ {
+			odds := headsUpOdds(combos, a, b)
+			loss := odds.Loss()
+			t.HeadsUp[a][b] = odds
+			t.HeadsUp[b][a] = Odds{Win: loss, Tie: odds.Tie, Equity: loss + odds.Tie/2}
+		}
+		t.buildVsRandom(rng, combos[a], a)
+
+		if progress != nil {
+			progress(a+1, NumHandClasses)
+		}
+	}
+	return t
+}
+
+// buildVsRandom samples the odds of class a against one to nine random
+// hands
+func (t *PreflopTable) buildVsRandom(rng *rand.Rand, combos []uint64, a int) {
+	for n := 1; n <= MaxPreflopOpponents; n++ {
+		wins, ties := 0, 0
+		share := 0.0
+		for s := 0; s < t.MultiwaySamples; s++ {
+			hero := combos[s%len(combos)]
+			used := hero
+			opponents := make([]uint64, n)
+			for i := range opponents {
+				c1 := randomCard(rng, used)
+				c2 := randomCard(rng, used|c1)
+				opponents[i] = c1 | c2
+				used |= opponents[i]
+			}
+			board := randomBoard(rng, used)
+			score := evaluateMask(hero | board)
+			best, tied := uint32(0), 0
+			for _, o := range opponents {
+				os := evaluateMask(o | board)
+				if os > best {
+					best, tied = os, 0
+				}
+				if os == score {
+					tied++
+				}
+			}
+			if score > best {
+				wins++
+				share++
+			} else if score == best {
+				ties++
+				share += 1 / float64(tied+1)
+			}
+		}
+		total := float64(t.MultiwaySamples)
+		odds := Odds{Win: float64(wins) / total, Tie: float64(ties) / total, Equity: share / total}
+		odds.Margin = odds.samplingMargin(t.MultiwaySamples)
+		t.VsRandom[a][n-1] = odds
+	}
+}
+
+// preflopMagic starts the binary encoding of a PreflopTable. It is followed
+// by MultiwaySamples as a little-endian uint32, then by every Odds of
+// HeadsUp and of VsRandom, in row order, each value a little-endian uint16
+// fraction of 65535.
+const preflopMagic = "PFEQ2"
+
+func (t *PreflopTable) odds() []*Odds {
+	var out []*Odds
+	for a := range t.HeadsUp {
+		for b := range t.HeadsUp[a] {
+			out = append(out, &t.HeadsUp[a][b])
+		}
+	}
+	for a := range t.VsRandom {
+		for n := range t.VsRandom[a] {
+			out = append(out, &t.VsRandom[a][n])
+		}
+	}
+	return out
+}
+
+// MarshalBinary encodes the table compactly for embedding
+func (t *PreflopTable) MarshalBinary() ([]byte, error) {
+	odds := t.odds()
+	data := make([]byte, len(preflopMagic), len(preflopMagic)+4+len(odds)*6)
+	copy(data, preflopMagic)
+	data = binary.LittleEndian.AppendUint32(data, uint32(t.MultiwaySamples))
+	for _, o := range odds {
+		for _, v := range []float64{o.Win, o.Tie, o.Equity} {
+			data = binary.LittleEndian.AppendUint16(data, uint16(math.Round(v*math.MaxUint16)))
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a table encoded by MarshalBinary
+func (t *PreflopTable) UnmarshalBinary(data []byte) error {
+	odds := t.odds()
+	if len(data) != len(preflopMagic)+4+len(odds)*6 || string(data[:len(preflopMagic)]) != preflopMagic {
+		return fmt.Errorf("invalid preflop equity table")
+	}
+	t.MultiwaySamples = int(binary.LittleEndian.Uint32(data[len(preflopMagic):]))
+	data = data[len(preflopMagic)+4:]
+	value := func(i int) float64 {
+		return float64(binary.LittleEndian.Uint16(data[2*i:])) / math.MaxUint16
+	}
+	for i, o := range odds {
+		o.Win, o.Tie, o.Equity = value(3*i), value(3*i+1), value(3*i+2)
+	}
+	for a := range t.VsRandom {
+		for n := range t.VsRandom[a] {
+			t.VsRandom[a][n].Margin = t.VsRandom[a][n].samplingMargin(t.MultiwaySamples)
+		}
+	}
+	return nil
+}
+
+var (
+	preflopOnce  sync.Once
+	preflopTable *PreflopTable
+	preflopErr   error
+)
+
+// EmbeddedPreflopTable returns the table built into the binary
+func EmbeddedPreflopTable() (*PreflopTable, error) {
+	preflopOnce.Do(func() {
+		preflopTable = &PreflopTable{}
+		preflopErr = preflopTable.UnmarshalBinary(preflopData)
+	})
+	return preflopTable, preflopErr
+}
+
+// PreflopHeadsUp returns the exact all-in odds of one starting hand class
+// against another, averaged over the hands in each class that do not share
+// a card
+func PreflopHeadsUp(hand, villain string) (Odds, error) {
+	a, err := HandClassIndex(hand)
+	if err != nil {
+		return Odds{}, err
+	}
+	b, err := HandClassIndex(villain)
+	if err != nil {
+		return Odds{}, err
+	}
+	t, err := EmbeddedPreflopTable()
+	if err != nil {
+		return Odds{}, err
+	}
+	return t.HeadsUp[a][b], nil
+}
+
+// PreflopVsRandom returns the all-in odds of a starting hand class against
+// one to nine random hands, as sampled with their margin of error
+func PreflopVsRandom(hand string, opponents int) (Odds, error) {
+	a, err := HandClassIndex(hand)
+	if err != nil {
+		return Odds{}, err
+	}
+	if opponents < 1 || opponents > MaxPreflopOpponents {
+		return Odds{}, fmt.Errorf("opponents must be between 1 and %d", MaxPreflopOpponents)
+	}
+	t, err := EmbeddedPreflopTable()
+	if err != nil {
+		return Odds{}, err
+	}
+	return t.VsRandom[a][opponents-1], nil
+}
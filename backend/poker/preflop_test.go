@@ -0,0 +1,208 @@
+package poker
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+func TestHandClass(t *testing.T) {
+	tests := []struct {
+		cards    []string
+		expected string
+	}{
+		{[]string{"HA", "DA"}, "AA"},
+		{[]string{"SK", "SA"}, "AKs"},
+		{[]string{"C7", "D2"}, "72o"},
+		{[]string{"H2", "H7"}, "72s"},
+		{[]string{"DT", "C9"}, "T9o"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			got, err := HandClass(tt.cards)
+			if err != nil || got != tt.expected {
+				t.Errorf("Expected %s, got %s (%v)", tt.expected, got, err)
+			}
+		})
+	}
+
+	for _, cards := range [][]string{{"HA"}, {"HA", "HA"}, {"HA", "XX"}} {
+		if _, err := HandClass(cards); err == nil {
+			t.Errorf("Expected error for %v", cards)
+		}
+	}
+}
+
+func TestHandClassIndex(t *testing.T) {
+	total := 0
+	for i := 0; i < NumHandClasses; i++ {
+		name := HandClassName(i)
+		got, err := HandClassIndex(name)
+		if err != nil || got != i {
+			t.Errorf("Expected %s to be class %d, got %d (%v)", name, i, got, err)
+		}
+
+		combos := classCombos(i)
+		expected := map[int]int{2: 6, 3: 4}[len(name)]
+		if name[len(name)-1] == 'o' {
+			expected = 12
+		}
+		if len(combos) != expected {
+			t.Errorf("Expected %d combos of %s, got %d", expected, name, len(combos))
+		}
+		for _, c := range combos {
+			if bits.OnesCount64(c) != 2 {
+				t.Errorf("Invalid combo %x of %s", c, name)
+			}
+		}
+		total += len(combos)
+	}
+	if total != 1326 {
+		t.Errorf("Expected 1326 starting hands, got %d", total)
+	}
+
+	if i, err := HandClassIndex("kas"); err != nil || HandClassName(i) != "AKs" {
+		t.Errorf("Expected kas to be read as AKs, got %s (%v)", HandClassName(i), err)
+	}
+	for _, name := range []string{"AKx", "AAs", "AK", "A", "ZZ", "AKo2"} {
+		if _, err := HandClassIndex(name); err == nil {
+			t.Errorf("Expected error for %q", name)
+		}
+	}
+}
+
+func TestPreflopTable(t *testing.T) {
+	table, err := EmbeddedPreflopTable()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	headsUp := []struct {
+		hand, villain string
+		equity        float64
+	}{
+		{"AA", "KK", 0.82},
+		{"AKs", "QQ", 0.46},
+		{"AKo", "22", 0.47},
+		{"72o", "AA", 0.12},
+		{"JTs", "JTs", 0.5},
+	}
+	for _, tt := range headsUp {
+		t.Run(tt.hand+" vs "+tt.villain, func(t *testing.T) {
+			got, err := PreflopHeadsUp(tt.hand, tt.villain)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(got.Equity-tt.equity) > 0.01 {
+				t.Errorf("Expected equity %v, got %+v", tt.equity, got)
+			}
+		})
+	}
+
+	vsRandom := []struct {
+		hand      string
+		opponents int
+		equity    float64
+	}{
+		{"AA", 1, 0.852},
+		{"72o", 1, 0.346},
+		{"AA", 9, 0.31},
+	}
+	for _, tt := range vsRandom {
+		got, err := PreflopVsRandom(tt.hand, tt.opponents)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if math.Abs(got.Equity-tt.equity) > 0.01 {
+			t.Errorf("Expected %s to have equity %v against %d, got %+v", tt.hand, tt.equity, tt.opponents, got)
+		}
+	}
+
+	// Each matchup is the mirror image of the other side's
+	for a := 0; a < NumHandClasses; a++ {
+		for b := 0; b < NumHandClasses; b++ {
+			x, y := table.HeadsUp[a][b], table.HeadsUp[b][a]
+			if math.Abs(x.Win-y.Loss()) > 1e-4 || math.Abs(x.Equity+y.Equity-1) > 1e-4 {
+				t.Fatalf("%s vs %s: %+v does not mirror %+v", HandClassName(a), HandClassName(b), x, y)
+			}
+		}
+	}
+
+	if table.HeadsUp[0][1].Margin != 0 {
+		t.Errorf("Expected exact heads-up odds, got %+v", table.HeadsUp[0][1])
+	}
+	if m := table.VsRandom[0][8].Margin; m <= 0 || m > 0.002 {
+		t.Errorf("Expected a margin of error under 0.2%%, got %v", m)
+	}
+
+	if _, err := PreflopVsRandom("AA", 10); err == nil {
+		t.Errorf("Expected error for 10 opponents")
+	}
+	if _, err := PreflopHeadsUp("AA", "AKx"); err == nil {
+		t.Errorf("Expected error for an invalid class")
+	}
+}
+
+func TestHeadsUpOdds(t *testing.T) {
+	combos := make([][]uint64, NumHandClasses)
+	for i := range combos {
+		combos[i] = classCombos(i)
+	}
+	aa, _ := HandClassIndex("AA")
+	kk, _ := HandClassIndex("KK")
+	got := headsUpOdds(combos, aa, kk)
+
+	// The embedded table holds the same enumeration, rounded
+	table, err := EmbeddedPreflopTable()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := table.HeadsUp[aa][kk]
+	if math.Abs(got.Win-want.Win) > 1e-4 || math.Abs(got.Tie-want.Tie) > 1e-4 || got.Margin != 0 {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Relabelling the suits of both hands leaves the matchup as it was
+	spades := canonicalMatchup(combos[aa][0], combos[kk][0])
+	for _, p := range suitRelabellings {
+		if m := canonicalMatchup(relabelSuits(combos[aa][0], p), relabelSuits(combos[kk][0], p)); m != spades {
+			t.Fatalf("Expected %x, got %x", spades, m)
+		}
+	}
+}
+
+func TestPreflopTableEncoding(t *testing.T) {
+	table := &PreflopTable{MultiwaySamples: 1000}
+	rng := rand.New(rand.NewSource(1))
+	for _, o := range table.odds() {
+		o.Win, o.Tie = rng.Float64()/2, rng.Float64()/4
+		o.Equity = o.Win + o.Tie/2
+	}
+	data, err := table.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := &PreflopTable{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.MultiwaySamples != 1000 {
+		t.Errorf("Expected 1000 multiway samples, got %d", decoded.MultiwaySamples)
+	}
+	expected := table.odds()
+	for i, o := range decoded.odds() {
+		want := expected[i]
+		if math.Abs(o.Win-want.Win) > 1e-4 || math.Abs(o.Tie-want.Tie) > 1e-4 || math.Abs(o.Equity-want.Equity) > 1e-4 {
+			t.Fatalf("Entry %d: expected %+v, got %+v", i, *want, *o)
+		}
+	}
+	if o := decoded.VsRandom[0][0]; o.Margin != o.samplingMargin(1000) || o.Margin == 0 {
+		t.Errorf("Expected the sampled odds to carry their margin, got %+v", o)
+	}
+
+	if err := decoded.UnmarshalBinary(data[:100]); err == nil {
+		t.Errorf("Expected error for a truncated table")
+	}
+}
@@ -0,0 +1,155 @@
+package poker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HandRange weights each starting hand class from 0 (never held) to 1
+type HandRange [NumHandClasses]float64
+
+// FullRange holds every starting hand
+func FullRange() *HandRange {
+	r := &HandRange{}
+	for i := range r {
+		r[i] = 1
+	}
+	return r
+}
+
+// ParseRange reads a range in the usual shorthand, comma separated: "AA",
+// "AKs", "AKo", "AK" for both, "TT+" and "ATs+" for everything up to the
+// top, "22-66" and "A2s-A5s" for spans, and "any" for every hand. Any
+// entry may end in a weight such as ":0.5"; later entries override earlier
+// ones.
+func ParseRange(s string) (*HandRange, error) {
+	r := &HandRange{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.ToUpper(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		weight := 1.0
+		if i := strings.IndexByte(entry, ':'); i >= 0 {
+			w, err := strconv.ParseFloat(entry[i+1:], 64)
+			if err != nil || w < 0 || w > 1 {
+				return nil, fmt.Errorf("invalid weight in range entry %q", entry)
+			}
+			entry, weight = entry[:i], w
+		}
+		classes, err := rangeClasses(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range classes {
+			r[c] = weight
+		}
+	}
+	return r, nil
+}
+
+// rangeHand is a hand class pattern: two ranks as indexes into rankOrder,
+// the higher first, and "S", "O" or "" for both
+type rangeHand struct {
+	hi, lo int
+	suffix string
+}
+
+func parseRangeHand(s string) (rangeHand, bool) {
+	if len(s) < 2 || len(s) > 3 {
+		return rangeHand{}, false
+	}
+	h := rangeHand{hi: strings.IndexByte(rankOrder, s[0]), lo: strings.IndexByte(rankOrder, s[1]), suffix: s[2:]}
+	if h.hi < 0 || h.lo < 0 || (h.suffix != "" && h.suffix != "S" && h.suffix != "O") {
+		return rangeHand{}, false
+	}
+	if h.hi > h.lo {
+		h.hi, h.lo = h.lo, h.hi
+	}
+	return h, h.hi != h.lo || h.suffix == ""
+}
+
+func (h rangeHand) pair() bool {
+	return h.hi == h.lo
+}
+
+// classes lists the hand classes the pattern covers
+func (h rangeHand) classes() []int {
+	switch {
+	case h.pair():
+		return []int{h.hi*13 + h.hi}
+	case h.suffix == "S":
+		return []int{h.hi*13 + h.lo}
+	case h.suffix == "O":
+		return []int{h.lo*13 + h.hi}
+	}
+	return []int{h.hi*13 + h.lo, h.lo*13 + h.hi}
+}
+
+func rangeClasses(entry string) ([]int, error) {
+	invalid := fmt.Errorf("invalid range entry %q", entry)
+	if entry == "ANY" || entry == "RANDOM" || entry == "100%" {
+		classes := make([]int, NumHandClasses)
+		for i := range classes {
+			classes[i] = i
+		}
+		return classes, nil
+	}
+
+	var from, to rangeHand
+	switch {
+	case strings.HasSuffix(entry, "+"):
+		h, ok := parseRangeHand(strings.TrimSuffix(entry, "+"))
+		if !ok {
+			return nil, invalid
+		}
+		// Pairs go up to aces, other hands up to a kicker one below the top
+		from, to = h, h
+		if h.pair() {
+			to.hi, to.lo = 0, 0
+		} else {
+			to.lo = h.hi + 1
+		}
+	case strings.Contains(entry, "-"):
+		parts := strings.SplitN(entry, "-", 2)
+		a, ok1 := parseRangeHand(parts[0])
+		b, ok2 := parseRangeHand(parts[1])
+		if !ok1 || !ok2 || a.pair() != b.pair() || a.suffix != b.suffix || !a.pair() && a.hi != b.hi {
+			return nil, invalid
+		}
+		from, to = a, b
+	default:
+		h, ok := parseRangeHand(entry)
+		if !ok {
+			return nil, invalid
+		}
+		return h.classes(), nil
+	}
+
+	if from.lo < to.lo {
+		from, to = to, from
+	}
+	var classes []int
+	for lo := from.lo; lo >= to.lo; lo-- {
+		h := rangeHand{hi: from.hi, lo: lo, suffix: from.suffix}
+		if from.pair() {
+			h.hi = lo
+		}
+		classes = append(classes, h.classes()...)
+	}
+	return classes, nil
+}
+
+// comboClass returns the hand class of two cards given as indexes 0-51,
+// 13 per suit with deuces first
+func comboClass(a, b int) int {
+	hi, lo := 12-a%13, 12-b%13
+	if hi > lo {
+		hi, lo = lo, hi
+	}
+	if hi != lo && a/13 != b/13 {
+		hi, lo = lo, hi
+	}
+	return hi*13 + lo
+}
@@ -0,0 +1,76 @@
+package poker
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// rangeNames lists the classes in a range with their weights
+func rangeNames(r *HandRange) string {
+	var names []string
+	for i, w := range r {
+		if w == 1 {
+			names = append(names, HandClassName(i))
+		} else if w > 0 {
+			names = append(names, HandClassName(i)+":"+strconv.FormatFloat(w, 'g', -1, 64))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"AA", "AA"},
+		{"AKs, ako", "AKo AKs"},
+		{"KQ", "KQo KQs"},
+		{"TT+", "AA JJ KK QQ TT"},
+		{"ATs+", "AJs AKs AQs ATs"},
+		{"K9o+", "K9o KJo KQo KTo"},
+		{"22-44", "22 33 44"},
+		{"A5s-A2s", "A2s A3s A4s A5s"},
+		{"QQ+,AKs:0.5", "AA AKs:0.5 KK QQ"},
+		{"AA,AA:0.25", "AA:0.25"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, err := ParseRange(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := rangeNames(r); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	any, err := ParseRange("any")
+	if err != nil || *any != *FullRange() {
+		t.Errorf("Expected any to be every hand, got %v (%v)", any, err)
+	}
+
+	for _, input := range []string{"AAs", "AKx", "XX", "AK:2", "AK:x", "22-AKs", "A2s-K5s", "A2s-A5o", "AKs++", "A"} {
+		if _, err := ParseRange(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestComboClass(t *testing.T) {
+	index := func(card string) int {
+		c, _ := ParseCard(card)
+		return int(suitIndex[c.Suit])*13 + c.Value - 2
+	}
+	for _, hand := range [][]string{{"HA", "DA"}, {"SK", "SA"}, {"C7", "D2"}, {"H2", "H7"}} {
+		class, _ := HandClass(hand)
+		if got := HandClassName(comboClass(index(hand[0]), index(hand[1]))); got != class {
+			t.Errorf("Expected %v to be %s, got %s", hand, class, got)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package poker
+
+// Typical preflop ranges of an opener and of a player who called the open,
+// used to judge which of them a board favors
+const (
+	RaiserRange = "22+,A2s+,K9s+,Q9s+,J9s+,T9s,98s,ATo+,KJo+,QJo"
+	CallerRange = "22-TT,A2s-AJs,K9s-KQs,Q9s+,J8s+,T8s+,97s+,86s+,75s+,65s,54s,ATo-AJo,KTo+,QTo+,JTo"
+)
+
+// BoardTexture describes a flop, turn or river board
+type BoardTexture struct {
+	Cards            []string       `json:"cards"`
+	Pairing          string         `json:"pairing"`        // "unpaired", "paired", "two pair", "trips", "full house" or "quads"
+	Suits            string         `json:"suits"`          // "rainbow", "two-tone", "three-flush", "four-flush" or "monotone"
+	Connectivity     string         `json:"connectivity"`   // "connected", "gapped" or "disconnected"
+	StraightCombos   int            `json:"straightCombos"` // Pairs of ranks that make a straight
+	StraightPossible bool           `json:"straightPossible"`
+	FlushPossible    bool           `json:"flushPossible"`
+	FlushDraw        bool           `json:"flushDraw"` // Two of a suit with cards to come
+	HighCard         string         `json:"highCard"`
+	Height           string         `json:"height"`  // "high" with a ten or better on top, "middle" with 8 or 9, else "low"
+	Wetness          string         `json:"wetness"` // "dry", "semi-wet" or "wet"
+	Ranges           RangeAdvantage `json:"ranges"`
+}
+
+// RangeAdvantage compares the opener's and the caller's ranges on a board
+type RangeAdvantage struct {
+	Equity       float64 `json:"equity"`       // Share of matchups the raiser is ahead in now, ties counting half
+	RaiserStrong float64 `json:"raiserStrong"` // Share of the raiser's hands with two pair or better
+	CallerStrong float64 `json:"callerStrong"`
+	Favors       string  `json:"favors"` // "raiser", "caller" or "neutral", on equity and strong hands
+}
+
+// ClassifyBoard describes the texture of 3 to 5 board cards
+func ClassifyBoard(boardCards []string) (*BoardTexture, error) {
+	cards, board, err := parseBoard(boardCards)
+	if err != nil {
+		return nil, err
+	}
+	t := &BoardTexture{Cards: boardCards}
+
+	counts := make(map[int]int)
+	suits := make(map[string]int)
+	top, maxSuit := 0, 0
+	for _, c := range cards {
+		counts[c.Value]++
+		suits[c.Suit]++
+		if c.Value > top {
+			top = c.Value
+		}
+		if suits[c.Suit] > maxSuit {
+			maxSuit = suits[c.Suit]
+		}
+	}
+	pairs, trips, quads := 0, 0, 0
+	for _, n := range counts {
+		switch n {
+		case 2:
+			pairs++
+		case 3:
+			trips++
+		case 4:
+			quads++
+		}
+	}
+	switch {
+	case quads > 0:
+		t.Pairing = "quads"
+	case trips > 0 && pairs > 0:
+		t.Pairing = "full house"
+	case trips > 0:
+		t.Pairing = "trips"
+	case pairs > 1:
+		t.Pairing = "two pair"
+	case pairs == 1:
+		t.Pairing = "paired"
+	default:
+		t.Pairing = "unpaired"
+	}
+
+	switch {
+	case maxSuit == len(cards):
+		t.Suits = "monotone"
+	case maxSuit == 4:
+		t.Suits = "four-flush"
+	case maxSuit == 3:
+		t.Suits = "three-flush"
+	case maxSuit == 2:
+		t.Suits = "two-tone"
+	default:
+		t.Suits = "rainbow"
+	}
+	t.FlushPossible = maxSuit >= 3
+	t.FlushDraw = maxSuit == 2 && len(cards) < 5
+
+	ranks := rankMask(cards)
+	for a := 0; a < 13; a++ {
+		for b := a; b < 13; b++ {
+			if straightHigh(ranks|1<<uint(a)|1<<uint(b)) != 0 {
+				t.StraightCombos++
+			}
+		}
+	}
+	t.StraightPossible = t.StraightCombos > 0
+	// Two ranks close together also leave straight draws. The wheel is left
+	// out, so an ace and a deuce do not make a board connected.
+	close := false
+	for r := 0; r < 13; r++ {
+		if ranks&(1<<uint(r)) != 0 && ranks&(3<<uint(r+1)) != 0 {
+			close = true
+		}
+	}
+	switch {
+	case t.StraightCombos >= 2:
+		t.Connectivity = "connected"
+	case t.StraightCombos > 0 || close:
+		t.Connectivity = "gapped"
+	default:
+		t.Connectivity = "disconnected"
+	}
+
+	t.HighCard = rankLetters[top-2 : top-1]
+	switch {
+	case top >= 10:
+		t.Height = "high"
+	case top >= 8:
+		t.Height = "middle"
+	default:
+		t.Height = "low"
+	}
+
+	// Flush and straight draws make a board wet; pairs dry it out
+	wetness := map[string]int{"two-tone": 1, "three-flush": 2, "four-flush": 2, "monotone": 2}[t.Suits] +
+		map[string]int{"gapped": 1, "connected": 2}[t.Connectivity]
+	if t.Pairing != "unpaired" {
+		wetness--
+	}
+	switch {
+	case wetness >= 3:
+		t.Wetness = "wet"
+	case wetness == 2:
+		t.Wetness = "semi-wet"
+	default:
+		t.Wetness = "dry"
+	}
+
+	raiser, _ := ParseRange(RaiserRange)
+	caller, _ := ParseRange(CallerRange)
+	t.Ranges = compareRanges(cards, board, raiser, caller)
+	return t, nil
+}
+
+// weightedCombo is a holding in a range
+type weightedCombo struct {
+	mask   uint64
+	weight float64
+	score  uint32
+}
+
+// rangeCombos lists the holdings in a range that avoid the board, scored
+// on it
+func rangeCombos(r *HandRange, board uint64) []weightedCombo {
+	var combos []weightedCombo
+	for i := 0; i < 52; i++ {
+		for j := i + 1; j < 52; j++ {
+			m := cardMask(i) | cardMask(j)
+			if w := r[comboClass(i, j)]; w > 0 && m&board == 0 {
+				combos = append(combos, weightedCombo{m, w, evaluateMask(board | m)})
+			}
+		}
+	}
+	return combos
+}
+
+func compareRanges(cards []Card, board uint64, raiser, caller *HandRange) RangeAdvantage {
+	rs, cs := rangeCombos(raiser, board), rangeCombos(caller, board)
+	// A strong hand must be better than the board itself, so two pair on a
+	// double-paired board does not count
+	floor := boardRank(cards)
+	strong := func(combos []weightedCombo) float64 {
+		var n, total float64
+		for _, c := range combos {
+			total += c.weight
+			if rank := HandRank(c.score >> 20); rank >= TwoPair && rank > floor {
+				n += c.weight
+			}
+		}
+		return n / total
+	}
+
+	var won, total float64
+	for _, r := range rs {
+		for _, c := range cs {
+			if r.mask&c.mask != 0 {
+				continue
+			}
+			w := r.weight * c.weight
+			total += w
+			switch {
+			case r.score > c.score:
+				won += w
+			case r.score == c.score:
+				won += w / 2
+			}
+		}
+	}
+
+	// Overpairs win most matchups on almost any flop, so the nut advantage,
+	// the difference in strong hands, counts double
+	a := RangeAdvantage{Equity: won / total, RaiserStrong: strong(rs), CallerStrong: strong(cs), Favors: "neutral"}
+	switch edge := a.Equity - 0.5 + 2*(a.RaiserStrong-a.CallerStrong); {
+	case edge > 0.05:
+		a.Favors = "raiser"
+	case edge < -0.05:
+		a.Favors = "caller"
+	}
+	return a
+}
@@ -0,0 +1,65 @@
+package poker
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClassifyBoardLabeled(t *testing.T) {
+	file, err := os.Open("testdata/board_textures.csv")
+	if err != nil {
+		t.Fatalf("Failed to open labeled boards: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	for _, record := range records[1:] {
+		board := strings.Fields(record[0])
+		t.Run(record[7], func(t *testing.T) {
+			tx, err := ClassifyBoard(board)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := []string{tx.Pairing, tx.Suits, tx.Connectivity, tx.Height, tx.Wetness, tx.Ranges.Favors}
+			for i, name := range []string{"pairing", "suits", "connectivity", "height", "wetness", "favors"} {
+				// Range advantage is only labeled where it is clear cut
+				if expected := record[i+1]; expected != "" && got[i] != expected {
+					t.Errorf("%v: expected %s %s, got %s", board, name, expected, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyBoard(t *testing.T) {
+	tx, err := ClassifyBoard([]string{"H9", "H8", "H7"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tx.StraightPossible || !tx.FlushPossible || tx.FlushDraw || tx.StraightCombos != 3 || tx.HighCard != "9" {
+		t.Errorf("Unexpected texture: %+v", tx)
+	}
+	if tx.Ranges.CallerStrong <= tx.Ranges.RaiserStrong {
+		t.Errorf("Expected the caller to have more strong hands on 987, got %+v", tx.Ranges)
+	}
+
+	tx, _ = ClassifyBoard([]string{"HK", "H7", "D2"})
+	if tx.StraightPossible || tx.FlushPossible || !tx.FlushDraw {
+		t.Errorf("Unexpected texture: %+v", tx)
+	}
+	if tx.Ranges.Equity <= 0.5 {
+		t.Errorf("Expected the raiser to be ahead on K72, got %+v", tx.Ranges)
+	}
+
+	for _, board := range [][]string{{"HK", "H7"}, {"HK", "H7", "HK"}, {"HK", "H7", "D2", "C3", "S4", "S5"}} {
+		if _, err := ClassifyBoard(board); err == nil {
+			t.Errorf("Expected error for %v", board)
+		}
+	}
+}
@@ -0,0 +1,1103 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: holdem/v1/poker.proto
+
+package holdemv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EvaluateHandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HoleCards  []string `protobuf:"bytes,1,rep,name=hole_cards,json=holeCards,proto3" json:"hole_cards,omitempty"`
+	BoardCards []string `protobuf:"bytes,2,rep,name=board_cards,json=boardCards,proto3" json:"board_cards,omitempty"`
+}
+
+func (x *EvaluateHandRequest) Reset() {
+	*x = EvaluateHandRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvaluateHandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateHandRequest) ProtoMessage() {}
+
+func (x *EvaluateHandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateHandRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateHandRequest) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EvaluateHandRequest) GetHoleCards() []string {
+	if x != nil {
+		return x.HoleCards
+	}
+	return nil
+}
+
+func (x *EvaluateHandRequest) GetBoardCards() []string {
+	if x != nil {
+		return x.BoardCards
+	}
+	return nil
+}
+
+type EvaluateHandResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BestHand  string        `protobuf:"bytes,1,opt,name=best_hand,json=bestHand,proto3" json:"best_hand,omitempty"`
+	HandValue string        `protobuf:"bytes,2,opt,name=hand_value,json=handValue,proto3" json:"hand_value,omitempty"`
+	Cards     []string      `protobuf:"bytes,3,rep,name=cards,proto3" json:"cards,omitempty"`
+	Strength  *HandStrength `protobuf:"bytes,4,opt,name=strength,proto3" json:"strength,omitempty"`
+}
+
+func (x *EvaluateHandResponse) Reset() {
+	*x = EvaluateHandResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvaluateHandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateHandResponse) ProtoMessage() {}
+
+func (x *EvaluateHandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateHandResponse.ProtoReflect.Descriptor instead.
+func (*EvaluateHandResponse) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EvaluateHandResponse) GetBestHand() string {
+	if x != nil {
+		return x.BestHand
+	}
+	return ""
+}
+
+func (x *EvaluateHandResponse) GetHandValue() string {
+	if x != nil {
+		return x.HandValue
+	}
+	return ""
+}
+
+func (x *EvaluateHandResponse) GetCards() []string {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *EvaluateHandResponse) GetStrength() *HandStrength {
+	if x != nil {
+		return x.Strength
+	}
+	return nil
+}
+
+// RankedHand is a hand value of the board, with how many holdings make it
+// and one of them
+type RankedHand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hand    string   `protobuf:"bytes,1,opt,name=hand,proto3" json:"hand,omitempty"`
+	Value   string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Combos  int32    `protobuf:"varint,3,opt,name=combos,proto3" json:"combos,omitempty"`
+	Example []string `protobuf:"bytes,4,rep,name=example,proto3" json:"example,omitempty"`
+}
+
+func (x *RankedHand) Reset() {
+	*x = RankedHand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RankedHand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RankedHand) ProtoMessage() {}
+
+func (x *RankedHand) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RankedHand.ProtoReflect.Descriptor instead.
+func (*RankedHand) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RankedHand) GetHand() string {
+	if x != nil {
+		return x.Hand
+	}
+	return ""
+}
+
+func (x *RankedHand) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *RankedHand) GetCombos() int32 {
+	if x != nil {
+		return x.Combos
+	}
+	return 0
+}
+
+func (x *RankedHand) GetExample() []string {
+	if x != nil {
+		return x.Example
+	}
+	return nil
+}
+
+// HandStrength places a holding among every other holding on the same
+// board
+type HandStrength struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nuts *RankedHand `protobuf:"bytes,1,opt,name=nuts,proto3" json:"nuts,omitempty"`
+	// 1 for the nuts
+	Position int32 `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+	// e.g. "2nd nuts"
+	Label string `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	// Share of other holdings beaten
+	Beats        float64 `protobuf:"fixed64,4,opt,name=beats,proto3" json:"beats,omitempty"`
+	CombosAhead  int32   `protobuf:"varint,5,opt,name=combos_ahead,json=combosAhead,proto3" json:"combos_ahead,omitempty"`
+	CombosTied   int32   `protobuf:"varint,6,opt,name=combos_tied,json=combosTied,proto3" json:"combos_tied,omitempty"`
+	CombosBehind int32   `protobuf:"varint,7,opt,name=combos_behind,json=combosBehind,proto3" json:"combos_behind,omitempty"`
+	// What beats the holding, best first, kickers merged
+	Ahead []*RankedHand `protobuf:"bytes,8,rep,name=ahead,proto3" json:"ahead,omitempty"`
+}
+
+func (x *HandStrength) Reset() {
+	*x = HandStrength{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HandStrength) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandStrength) ProtoMessage() {}
+
+func (x *HandStrength) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandStrength.ProtoReflect.Descriptor instead.
+func (*HandStrength) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HandStrength) GetNuts() *RankedHand {
+	if x != nil {
+		return x.Nuts
+	}
+	return nil
+}
+
+func (x *HandStrength) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *HandStrength) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *HandStrength) GetBeats() float64 {
+	if x != nil {
+		return x.Beats
+	}
+	return 0
+}
+
+func (x *HandStrength) GetCombosAhead() int32 {
+	if x != nil {
+		return x.CombosAhead
+	}
+	return 0
+}
+
+func (x *HandStrength) GetCombosTied() int32 {
+	if x != nil {
+		return x.CombosTied
+	}
+	return 0
+}
+
+func (x *HandStrength) GetCombosBehind() int32 {
+	if x != nil {
+		return x.CombosBehind
+	}
+	return 0
+}
+
+func (x *HandStrength) GetAhead() []*RankedHand {
+	if x != nil {
+		return x.Ahead
+	}
+	return nil
+}
+
+type CompareHandsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Player1HoleCards []string `protobuf:"bytes,1,rep,name=player1_hole_cards,json=player1HoleCards,proto3" json:"player1_hole_cards,omitempty"`
+	Player2HoleCards []string `protobuf:"bytes,2,rep,name=player2_hole_cards,json=player2HoleCards,proto3" json:"player2_hole_cards,omitempty"`
+	CommunityCards   []string `protobuf:"bytes,3,rep,name=community_cards,json=communityCards,proto3" json:"community_cards,omitempty"`
+}
+
+func (x *CompareHandsRequest) Reset() {
+	*x = CompareHandsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompareHandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareHandsRequest) ProtoMessage() {}
+
+func (x *CompareHandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareHandsRequest.ProtoReflect.Descriptor instead.
+func (*CompareHandsRequest) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CompareHandsRequest) GetPlayer1HoleCards() []string {
+	if x != nil {
+		return x.Player1HoleCards
+	}
+	return nil
+}
+
+func (x *CompareHandsRequest) GetPlayer2HoleCards() []string {
+	if x != nil {
+		return x.Player2HoleCards
+	}
+	return nil
+}
+
+func (x *CompareHandsRequest) GetCommunityCards() []string {
+	if x != nil {
+		return x.CommunityCards
+	}
+	return nil
+}
+
+type CompareHandsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Player1 *EvaluateHandResponse `protobuf:"bytes,1,opt,name=player1,proto3" json:"player1,omitempty"`
+	Player2 *EvaluateHandResponse `protobuf:"bytes,2,opt,name=player2,proto3" json:"player2,omitempty"`
+	Winner  string                `protobuf:"bytes,3,opt,name=winner,proto3" json:"winner,omitempty"`
+}
+
+func (x *CompareHandsResponse) Reset() {
+	*x = CompareHandsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompareHandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareHandsResponse) ProtoMessage() {}
+
+func (x *CompareHandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareHandsResponse.ProtoReflect.Descriptor instead.
+func (*CompareHandsResponse) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CompareHandsResponse) GetPlayer1() *EvaluateHandResponse {
+	if x != nil {
+		return x.Player1
+	}
+	return nil
+}
+
+func (x *CompareHandsResponse) GetPlayer2() *EvaluateHandResponse {
+	if x != nil {
+		return x.Player2
+	}
+	return nil
+}
+
+func (x *CompareHandsResponse) GetWinner() string {
+	if x != nil {
+		return x.Winner
+	}
+	return ""
+}
+
+// Hand is one player's hole cards
+type Hand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cards []string `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+}
+
+func (x *Hand) Reset() {
+	*x = Hand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Hand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hand) ProtoMessage() {}
+
+func (x *Hand) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hand.ProtoReflect.Descriptor instead.
+func (*Hand) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Hand) GetCards() []string {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+// ShowdownRequest holds the hole cards of two or more players and the five
+// community cards
+type ShowdownRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hands          []*Hand  `protobuf:"bytes,1,rep,name=hands,proto3" json:"hands,omitempty"`
+	CommunityCards []string `protobuf:"bytes,2,rep,name=community_cards,json=communityCards,proto3" json:"community_cards,omitempty"`
+}
+
+func (x *ShowdownRequest) Reset() {
+	*x = ShowdownRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShowdownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShowdownRequest) ProtoMessage() {}
+
+func (x *ShowdownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShowdownRequest.ProtoReflect.Descriptor instead.
+func (*ShowdownRequest) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ShowdownRequest) GetHands() []*Hand {
+	if x != nil {
+		return x.Hands
+	}
+	return nil
+}
+
+func (x *ShowdownRequest) GetCommunityCards() []string {
+	if x != nil {
+		return x.CommunityCards
+	}
+	return nil
+}
+
+// ShowdownResponse has each player's best hand, in the order given, and the
+// indexes of the players who win, more than one on a split
+type ShowdownResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hands   []*EvaluateHandResponse `protobuf:"bytes,1,rep,name=hands,proto3" json:"hands,omitempty"`
+	Winners []int32                 `protobuf:"varint,2,rep,packed,name=winners,proto3" json:"winners,omitempty"`
+}
+
+func (x *ShowdownResponse) Reset() {
+	*x = ShowdownResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShowdownResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShowdownResponse) ProtoMessage() {}
+
+func (x *ShowdownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShowdownResponse.ProtoReflect.Descriptor instead.
+func (*ShowdownResponse) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ShowdownResponse) GetHands() []*EvaluateHandResponse {
+	if x != nil {
+		return x.Hands
+	}
+	return nil
+}
+
+func (x *ShowdownResponse) GetWinners() []int32 {
+	if x != nil {
+		return x.Winners
+	}
+	return nil
+}
+
+// EquityRequest holds the hole cards of two or more players and 0 to 5
+// board cards. Boards missing one or two cards are enumerated exactly;
+// earlier streets are sampled num_simulations times, 10000 by default.
+type EquityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hands          []*Hand  `protobuf:"bytes,1,rep,name=hands,proto3" json:"hands,omitempty"`
+	BoardCards     []string `protobuf:"bytes,2,rep,name=board_cards,json=boardCards,proto3" json:"board_cards,omitempty"`
+	NumSimulations int32    `protobuf:"varint,3,opt,name=num_simulations,json=numSimulations,proto3" json:"num_simulations,omitempty"`
+	// The same seed gives the same equities; from the clock when zero
+	Seed int64 `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+}
+
+func (x *EquityRequest) Reset() {
+	*x = EquityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EquityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EquityRequest) ProtoMessage() {}
+
+func (x *EquityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EquityRequest.ProtoReflect.Descriptor instead.
+func (*EquityRequest) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EquityRequest) GetHands() []*Hand {
+	if x != nil {
+		return x.Hands
+	}
+	return nil
+}
+
+func (x *EquityRequest) GetBoardCards() []string {
+	if x != nil {
+		return x.BoardCards
+	}
+	return nil
+}
+
+func (x *EquityRequest) GetNumSimulations() int32 {
+	if x != nil {
+		return x.NumSimulations
+	}
+	return 0
+}
+
+func (x *EquityRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// EquityResponse has each player's share of the pot, split pots included.
+// While progress is being streamed done counts the simulations so far out
+// of simulations; the last message has done equal to simulations.
+type EquityResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Equity      []float64 `protobuf:"fixed64,1,rep,packed,name=equity,proto3" json:"equity,omitempty"`
+	Done        int32     `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	Simulations int32     `protobuf:"varint,3,opt,name=simulations,proto3" json:"simulations,omitempty"`
+	// Every runout was enumerated
+	Exact bool `protobuf:"varint,4,opt,name=exact,proto3" json:"exact,omitempty"`
+}
+
+func (x *EquityResponse) Reset() {
+	*x = EquityResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_holdem_v1_poker_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EquityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EquityResponse) ProtoMessage() {}
+
+func (x *EquityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_holdem_v1_poker_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EquityResponse.ProtoReflect.Descriptor instead.
+func (*EquityResponse) Descriptor() ([]byte, []int) {
+	return file_holdem_v1_poker_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EquityResponse) GetEquity() []float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return nil
+}
+
+func (x *EquityResponse) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *EquityResponse) GetSimulations() int32 {
+	if x != nil {
+		return x.Simulations
+	}
+	return 0
+}
+
+func (x *EquityResponse) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
+var File_holdem_v1_poker_proto protoreflect.FileDescriptor
+
+var file_holdem_v1_poker_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x6f, 0x6b, 0x65,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e,
+	0x76, 0x31, 0x22, 0x55, 0x0a, 0x13, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48, 0x61,
+	0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x6f, 0x6c,
+	0x65, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x68,
+	0x6f, 0x6c, 0x65, 0x43, 0x61, 0x72, 0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x61, 0x72,
+	0x64, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x43, 0x61, 0x72, 0x64, 0x73, 0x22, 0x9d, 0x01, 0x0a, 0x14, 0x45, 0x76,
+	0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x65, 0x73, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x63,
+	0x61, 0x72, 0x64, 0x73, 0x12, 0x33, 0x0a, 0x08, 0x73, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x52,
+	0x08, 0x73, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x22, 0x68, 0x0a, 0x0a, 0x52, 0x61, 0x6e,
+	0x6b, 0x65, 0x64, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x63, 0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x65, 0x78, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x22, 0x97, 0x02, 0x0a, 0x0c, 0x48, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x12, 0x29, 0x0a, 0x04, 0x6e, 0x75, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x61, 0x6e, 0x6b, 0x65, 0x64, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x04, 0x6e, 0x75, 0x74, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x65, 0x61, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x62, 0x65, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x62, 0x6f,
+	0x73, 0x5f, 0x61, 0x68, 0x65, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x41, 0x68, 0x65, 0x61, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6d, 0x62, 0x6f, 0x73, 0x5f, 0x74, 0x69, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x63, 0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x54, 0x69, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63,
+	0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x5f, 0x62, 0x65, 0x68, 0x69, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x62, 0x6f, 0x73, 0x42, 0x65, 0x68, 0x69, 0x6e, 0x64,
+	0x12, 0x2b, 0x0a, 0x05, 0x61, 0x68, 0x65, 0x61, 0x64, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x61, 0x6e, 0x6b,
+	0x65, 0x64, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x05, 0x61, 0x68, 0x65, 0x61, 0x64, 0x22, 0x9a, 0x01,
+	0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x31,
+	0x5f, 0x68, 0x6f, 0x6c, 0x65, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x10, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x31, 0x48, 0x6f, 0x6c, 0x65, 0x43, 0x61,
+	0x72, 0x64, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x32, 0x5f, 0x68,
+	0x6f, 0x6c, 0x65, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x10, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x32, 0x48, 0x6f, 0x6c, 0x65, 0x43, 0x61, 0x72, 0x64,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x5f, 0x63,
+	0x61, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x75, 0x6e, 0x69, 0x74, 0x79, 0x43, 0x61, 0x72, 0x64, 0x73, 0x22, 0xa4, 0x01, 0x0a, 0x14, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x31, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x31, 0x12, 0x39,
+	0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x32, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c,
+	0x75, 0x61, 0x74, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x32, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65,
+	0x72, 0x22, 0x1c, 0x0a, 0x04, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x72,
+	0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x22,
+	0x61, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x77, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x25, 0x0a, 0x05, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61,
+	0x6e, 0x64, 0x52, 0x05, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6f, 0x6d,
+	0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x43, 0x61, 0x72,
+	0x64, 0x73, 0x22, 0x63, 0x0a, 0x10, 0x53, 0x68, 0x6f, 0x77, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x05, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x05, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x07,
+	0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x22, 0x94, 0x01, 0x0a, 0x0d, 0x45, 0x71, 0x75, 0x69,
+	0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x05, 0x68, 0x61, 0x6e,
+	0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65,
+	0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x05, 0x68, 0x61, 0x6e, 0x64, 0x73,
+	0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x43, 0x61, 0x72, 0x64,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x75, 0x6d, 0x5f, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6e, 0x75, 0x6d, 0x53,
+	0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65,
+	0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x22, 0x74,
+	0x0a, 0x0e, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x06, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x6f, 0x6e, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x12, 0x20, 0x0a, 0x0b,
+	0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x78, 0x61, 0x63, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65,
+	0x78, 0x61, 0x63, 0x74, 0x32, 0xed, 0x02, 0x0a, 0x05, 0x50, 0x6f, 0x6b, 0x65, 0x72, 0x12, 0x4b,
+	0x0a, 0x08, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x12, 0x1e, 0x2e, 0x68, 0x6f, 0x6c,
+	0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48,
+	0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x68, 0x6f, 0x6c,
+	0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x48,
+	0x61, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x07, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x12, 0x1e, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x08, 0x53, 0x68, 0x6f, 0x77, 0x64,
+	0x6f, 0x77, 0x6e, 0x12, 0x1a, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x68, 0x6f, 0x77, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x68, 0x6f, 0x77,
+	0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x06,
+	0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x12, 0x18, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x19, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x71, 0x75,
+	0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0e, 0x45,
+	0x71, 0x75, 0x69, 0x74, 0x79, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x2e,
+	0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x71, 0x75, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x30, 0x01, 0x42, 0x2f, 0x5a, 0x2d, 0x74, 0x65, 0x78, 0x61, 0x73, 0x2d, 0x68, 0x6f,
+	0x6c, 0x64, 0x65, 0x6d, 0x2d, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6d, 0x2f, 0x76, 0x31, 0x3b, 0x68, 0x6f, 0x6c,
+	0x64, 0x65, 0x6d, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_holdem_v1_poker_proto_rawDescOnce sync.Once
+	file_holdem_v1_poker_proto_rawDescData = file_holdem_v1_poker_proto_rawDesc
+)
+
+func file_holdem_v1_poker_proto_rawDescGZIP() []byte {
+	file_holdem_v1_poker_proto_rawDescOnce.Do(func() {
+		file_holdem_v1_poker_proto_rawDescData = protoimpl.X.CompressGZIP(file_holdem_v1_poker_proto_rawDescData)
+	})
+	return file_holdem_v1_poker_proto_rawDescData
+}
+
+var file_holdem_v1_poker_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_holdem_v1_poker_proto_goTypes = []any{
+	(*EvaluateHandRequest)(nil),  // 0: holdem.v1.EvaluateHandRequest
+	(*EvaluateHandResponse)(nil), // 1: holdem.v1.EvaluateHandResponse
+	(*RankedHand)(nil),           // 2: holdem.v1.RankedHand
+	(*HandStrength)(nil),         // 3: holdem.v1.HandStrength
+	(*CompareHandsRequest)(nil),  // 4: holdem.v1.CompareHandsRequest
+	(*CompareHandsResponse)(nil), // 5: holdem.v1.CompareHandsResponse
+	(*Hand)(nil),                 // 6: holdem.v1.Hand
+	(*ShowdownRequest)(nil),      // 7: holdem.v1.ShowdownRequest
+	(*ShowdownResponse)(nil),     // 8: holdem.v1.ShowdownResponse
+	(*EquityRequest)(nil),        // 9: holdem.v1.EquityRequest
+	(*EquityResponse)(nil),       // 10: holdem.v1.EquityResponse
+}
+var file_holdem_v1_poker_proto_depIdxs = []int32{
+	3,  // 0: holdem.v1.EvaluateHandResponse.strength:type_name -> holdem.v1.HandStrength
+	2,  // 1: holdem.v1.HandStrength.nuts:type_name -> holdem.v1.RankedHand
+	2,  // 2: holdem.v1.HandStrength.ahead:type_name -> holdem.v1.RankedHand
+	1,  // 3: holdem.v1.CompareHandsResponse.player1:type_name -> holdem.v1.EvaluateHandResponse
+	1,  // 4: holdem.v1.CompareHandsResponse.player2:type_name -> holdem.v1.EvaluateHandResponse
+	6,  // 5: holdem.v1.ShowdownRequest.hands:type_name -> holdem.v1.Hand
+	1,  // 6: holdem.v1.ShowdownResponse.hands:type_name -> holdem.v1.EvaluateHandResponse
+	6,  // 7: holdem.v1.EquityRequest.hands:type_name -> holdem.v1.Hand
+	0,  // 8: holdem.v1.Poker.Evaluate:input_type -> holdem.v1.EvaluateHandRequest
+	4,  // 9: holdem.v1.Poker.Compare:input_type -> holdem.v1.CompareHandsRequest
+	7,  // 10: holdem.v1.Poker.Showdown:input_type -> holdem.v1.ShowdownRequest
+	9,  // 11: holdem.v1.Poker.Equity:input_type -> holdem.v1.EquityRequest
+	9,  // 12: holdem.v1.Poker.EquityProgress:input_type -> holdem.v1.EquityRequest
+	1,  // 13: holdem.v1.Poker.Evaluate:output_type -> holdem.v1.EvaluateHandResponse
+	5,  // 14: holdem.v1.Poker.Compare:output_type -> holdem.v1.CompareHandsResponse
+	8,  // 15: holdem.v1.Poker.Showdown:output_type -> holdem.v1.ShowdownResponse
+	10, // 16: holdem.v1.Poker.Equity:output_type -> holdem.v1.EquityResponse
+	10, // 17: holdem.v1.Poker.EquityProgress:output_type -> holdem.v1.EquityResponse
+	13, // [13:18] is the sub-list for method output_type
+	8,  // [8:13] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_holdem_v1_poker_proto_init() }
+func file_holdem_v1_poker_proto_init() {
+	if File_holdem_v1_poker_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_holdem_v1_poker_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*EvaluateHandRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*EvaluateHandResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*RankedHand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*HandStrength); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CompareHandsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*CompareHandsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Hand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ShowdownRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ShowdownResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*EquityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_holdem_v1_poker_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*EquityResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_holdem_v1_poker_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_holdem_v1_poker_proto_goTypes,
+		DependencyIndexes: file_holdem_v1_poker_proto_depIdxs,
+		MessageInfos:      file_holdem_v1_poker_proto_msgTypes,
+	}.Build()
+	File_holdem_v1_poker_proto = out.File
+	file_holdem_v1_poker_proto_rawDesc = nil
+	file_holdem_v1_poker_proto_goTypes = nil
+	file_holdem_v1_poker_proto_depIdxs = nil
+}
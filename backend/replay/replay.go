@@ -0,0 +1,437 @@
+// Package replay steps through a recorded hand, producing the table as it
+// looked after every action so that a hand can be reviewed or animated.
+package replay
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+	"texas-holdem-backend/poker"
+)
+
+// DefaultSimulations is the number of Monte Carlo runs used for equities
+// before the turn
+const DefaultSimulations = 1000
+
+// Timeline is a hand broken down into the states a viewer steps through
+type Timeline struct {
+	HandID     string `json:"handId"`
+	Table      string `json:"table"`
+	Limit      string `json:"limit"`
+	Currency   string `json:"currency,omitempty"`
+	SmallBlind int64  `json:"smallBlind"`
+	BigBlind   int64  `json:"bigBlind"`
+	ButtonSeat int    `json:"buttonSeat"`
+	Hero       string `json:"hero,omitempty"`
+	Steps      []Step `json:"steps"`
+}
+
+// Step is the table after one action or card deal. The first step is the
+// table before the blinds are posted.
+type Step struct {
+	Index       int                 `json:"index"`
+	Street      game.Street         `json:"street"`
+	Action      *Action             `json:"action,omitempty"`
+	Description string              `json:"description"`
+	Board       []string            `json:"board"`
+	Pot         int64               `json:"pot"` // Everything in the middle, including bets on this street
+	CurrentBet  int64               `json:"currentBet"`
+	Seats       []Seat              `json:"seats"`
+	ToAct       string              `json:"toAct,omitempty"`
+	Options     *game.ActionOptions `json:"options,omitempty"`
+}
+
+// Action is the action that led to a step
+type Action struct {
+	Player string                 `json:"player"`
+	Type   handhistory.ActionType `json:"type"`
+	Amount int64                  `json:"amount,omitempty"`
+	To     int64                  `json:"to,omitempty"`
+	AllIn  bool                   `json:"allIn,omitempty"`
+	Cards  []string               `json:"cards,omitempty"`
+}
+
+// Seat is a player's situation at a step. Cards are known from the first
+// step on when the hand history gives them for the seat, as imported hands
+// do for every hand shown at showdown; a hand redacted for one player only
+// shows another's cards from the step at which they are shown. Equity is
+// the player's share of the pot if the hand were run out from here; it is
+// only set at decision points, for players whose cards are known.
+type Seat struct {
+	Seat      int      `json:"seat"`
+	Name      string   `json:"name"`
+	Stack     int64    `json:"stack"`
+	Bet       int64    `json:"bet"`
+	Committed int64    `json:"committed"`
+	Won       int64    `json:"won,omitempty"`
+	Folded    bool     `json:"folded,omitempty"`
+	AllIn     bool     `json:"allIn,omitempty"`
+	Cards     []string `json:"cards,omitempty"`
+	Equity    *float64 `json:"equity,omitempty"`
+}
+
+// Options control how a timeline is built
+type Options struct {
+	Simulations int   // Monte Carlo runs per equity calculation; DefaultSimulations if zero
+	NoEquity    bool  // Skip equity calculations
+	Seed        int64 // Seeds every simulation of the replay; from the clock when zero
+}
+
+// Build replays a hand and returns its timeline
+func Build(hh *handhistory.HandHistory, opts Options) (*Timeline, error) {
+	if opts.Simulations <= 0 {
+		opts.Simulations = DefaultSimulations
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	structure, ok := game.StructureByName(hh.Limit, int(hh.BigBlind))
+	if !ok {
+		return nil, fmt.Errorf("unsupported betting structure %q", hh.Limit)
+	}
+
+	r := &replayer{
+		hh:        hh,
+		structure: structure,
+		opts:      opts,
+		equities:  make(map[string][]float64),
+		rng:       rand.New(rand.NewSource(seed)),
+		timeline: &Timeline{
+			HandID:     hh.HandID,
+			Table:      hh.TableName,
+			Limit:      hh.Limit,
+			Currency:   hh.Currency,
+			SmallBlind: hh.SmallBlind,
+			BigBlind:   hh.BigBlind,
+			ButtonSeat: hh.ButtonSeat,
+			Hero:       hh.Hero,
+		},
+	}
+	for _, s := range hh.Seats {
+		r.seats = append(r.seats, Seat{
+			Seat:   s.Number,
+			Name:   s.Name,
+			Stack:  s.Stack,
+			Folded: s.SittingOut,
+			Cards:  append([]string(nil), s.HoleCards...),
+		})
+	}
+	if err := r.run(); err != nil {
+		return nil, err
+	}
+	return r.timeline, nil
+}
+
+type replayer struct {
+	hh        *handhistory.HandHistory
+	structure game.BettingStructure
+	opts      Options
+	timeline  *Timeline
+
+	seats      []Seat
+	street     game.Street
+	board      []string
+	pot        int64
+	currentBet int64
+	lastRaise  int64
+	raises     int
+
+	betting []bettingState // Per step
+
+	// Equities only change when cards are dealt or players fold
+	equities map[string][]float64
+	rng      *rand.Rand // Draws the boards of every simulation
+}
+
+// bettingState is the part of the betting round a step does not expose
+type bettingState struct {
+	lastRaise int64
+	raises    int
+}
+
+func (r *replayer) run() error {
+	r.snapshot(nil, "Hand #"+r.hh.HandID+" starts")
+
+	for _, a := range r.hh.Actions {
+		for r.street < a.Street && r.street < game.Showdown {
+			r.nextStreet()
+		}
+		if err := r.apply(a); err != nil {
+			return err
+		}
+	}
+	for r.street < game.River && len(r.board) < len(r.hh.Board) {
+		r.nextStreet()
+	}
+
+	r.decisionPoints()
+	return nil
+}
+
+func (r *replayer) seat(name string) (*Seat, error) {
+	for i := range r.seats {
+		if r.seats[i].Name == name {
+			return &r.seats[i], nil
+		}
+	}
+	return nil, fmt.Errorf("action by unknown player %s", name)
+}
+
+func (r *replayer) nextStreet() {
+	r.street++
+	for i := range r.seats {
+		r.seats[i].Bet = 0
+	}
+	r.currentBet, r.lastRaise, r.raises = 0, 0, 0
+
+	dealt := map[game.Street]int{game.Flop: 3, game.Turn: 4, game.River: 5}[r.street]
+	if dealt == 0 || len(r.hh.Board) < dealt {
+		return
+	}
+	r.board = r.hh.Board[:dealt]
+	desc := r.street.String() + " " + siteCards(r.board[len(r.board)-1:])
+	if r.street == game.Flop {
+		desc = "Flop " + siteCards(r.board)
+	}
+	r.snapshot(nil, desc)
+}
+
+func (r *replayer) apply(a handhistory.Action) error {
+	s, err := r.seat(a.Player)
+	if err != nil {
+		return err
+	}
+
+	put := func(amount int64, live bool) {
+		s.Stack -= amount
+		s.Committed += amount
+		r.pot += amount
+		if live {
+			s.Bet += amount
+		}
+	}
+
+	switch a.Type {
+	case handhistory.PostAnte:
+		put(a.Amount, false)
+	case handhistory.PostSmallBlind, handhistory.PostBigBlind, handhistory.Call:
+		put(a.Amount, true)
+		if a.Type == handhistory.PostBigBlind {
+			r.raiseTo(s.Bet)
+			r.lastRaise = r.hh.BigBlind
+		}
+	case handhistory.Bet:
+		put(a.Amount, true)
+		r.raiseTo(s.Bet)
+	case handhistory.Raise:
+		put(a.To-s.Bet, true)
+		r.raiseTo(s.Bet)
+	case handhistory.Fold:
+		s.Folded = true
+	case handhistory.Uncalled:
+		s.Stack += a.Amount
+		s.Committed -= a.Amount
+		s.Bet -= a.Amount
+		r.pot -= a.Amount
+	case handhistory.Show:
+		if len(a.Cards) > 0 {
+			s.Cards = append([]string(nil), a.Cards...)
+		}
+	case handhistory.Collect:
+		s.Stack += a.Amount
+		s.Won += a.Amount
+		r.pot -= a.Amount
+	}
+	s.AllIn = s.Stack == 0 && s.Committed > 0
+
+	r.snapshot(&Action{
+		Player: a.Player,
+		Type:   a.Type,
+		Amount: a.Amount,
+		To:     a.To,
+		AllIn:  a.AllIn,
+		Cards:  a.Cards,
+	}, r.hh.ActionLine(a))
+	return nil
+}
+
+func (r *replayer) raiseTo(bet int64) {
+	if bet <= r.currentBet {
+		return
+	}
+	if raise := bet - r.currentBet; raise > r.lastRaise {
+		r.lastRaise = raise
+	}
+	r.currentBet = bet
+	r.raises++
+}
+
+func (r *replayer) snapshot(action *Action, description string) {
+	step := Step{
+		Index:       len(r.timeline.Steps),
+		Street:      r.street,
+		Action:      action,
+		Description: description,
+		Board:       append([]string{}, r.board...),
+		Pot:         r.pot,
+		CurrentBet:  r.currentBet,
+		Seats:       make([]Seat, len(r.seats)),
+	}
+	for i, s := range r.seats {
+		s.Cards = append([]string(nil), s.Cards...)
+		step.Seats[i] = s
+	}
+	r.timeline.Steps = append(r.timeline.Steps, step)
+	r.betting = append(r.betting, bettingState{lastRaise: r.lastRaise, raises: r.raises})
+}
+
+// decisionPoints fills in the player to act, their options and everyone's
+// equity wherever the next step is a player's decision
+func (r *replayer) decisionPoints() {
+	steps := r.timeline.Steps
+	for i := 0; i+1 < len(steps); i++ {
+		next := steps[i+1].Action
+		if next == nil || !isDecision(next.Type) {
+			continue
+		}
+		step := &steps[i]
+		step.ToAct = next.Player
+		if opts, ok := r.options(step, r.betting[i]); ok {
+			step.Options = &opts
+		}
+		if !r.opts.NoEquity {
+			r.equity(step)
+		}
+	}
+}
+
+func isDecision(t handhistory.ActionType) bool {
+	switch t {
+	case handhistory.Fold, handhistory.Check, handhistory.Call, handhistory.Bet, handhistory.Raise:
+		return true
+	}
+	return false
+}
+
+func (r *replayer) options(step *Step, b bettingState) (game.ActionOptions, bool) {
+	var player *Seat
+	othersCanAct := false
+	for i := range step.Seats {
+		s := &step.Seats[i]
+		if s.Name == step.ToAct {
+			player = s
+		} else if !s.Folded && !s.AllIn {
+			othersCanAct = true
+		}
+	}
+	if player == nil {
+		return game.ActionOptions{}, false
+	}
+
+	opts := r.structure.Options(game.BettingState{
+		Street:        step.Street,
+		Pot:           int(step.Pot),
+		CurrentBet:    int(step.CurrentBet),
+		PlayerBet:     int(player.Bet),
+		Stack:         int(player.Stack),
+		LastRaise:     int(b.lastRaise),
+		BigBlind:      int(r.hh.BigBlind),
+		Raises:        b.raises,
+		ActivePlayers: countActive(step.Seats),
+	})
+	// Nobody is left to respond to a bet, as the game engine enforces
+	if !othersCanAct {
+		var actions []game.ActionType
+		for _, a := range opts.Actions {
+			if a != game.Bet && a != game.Raise {
+				actions = append(actions, a)
+			}
+		}
+		opts.Actions = actions
+		opts.MinAmount, opts.MaxAmount = 0, 0
+	}
+	return opts, true
+}
+
+// equity sets the equity of every live player whose cards are known. When
+// all live hands are known they are run against each other; otherwise each
+// known hand is simulated against random hands for the unknown ones.
+func (r *replayer) equity(step *Step) {
+	var live []*Seat
+	var known []*Seat
+	for i := range step.Seats {
+		s := &step.Seats[i]
+		if s.Folded {
+			continue
+		}
+		live = append(live, s)
+		if len(s.Cards) == 2 {
+			known = append(known, s)
+		}
+	}
+	if len(known) == 0 {
+		return
+	}
+
+	// The known hands are dealt together, against random hands for the
+	// players whose cards are not known
+	names := make([]string, len(known))
+	hands := make([][]string, len(known))
+	for i, s := range known {
+		names[i] = s.Name
+		hands[i] = s.Cards
+	}
+	unknown := len(live) - len(known)
+	key := equityKey(step.Board, names)
+	if unknown > 0 {
+		key += fmt.Sprintf("|%d unknown", unknown)
+	}
+	equities, ok := r.equities[key]
+	if !ok {
+		var err error
+		if unknown == 0 {
+			equities, err = poker.HandsEquity(hands, step.Board, r.opts.Simulations, r.rng)
+		} else {
+			equities, err = poker.EquityAgainstRandom(hands, unknown, step.Board, r.opts.Simulations, r.rng)
+		}
+		if err != nil {
+			return
+		}
+		r.equities[key] = equities
+	}
+	for i, s := range known {
+		e := equities[i]
+		s.Equity = &e
+	}
+}
+
+func countActive(seats []Seat) int {
+	n := 0
+	for _, s := range seats {
+		if !s.Folded {
+			n++
+		}
+	}
+	return n
+}
+
+func siteCards(cards []string) string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = handhistory.ToSiteCard(c)
+	}
+	return "[" + strings.Join(out, " ") + "]"
+}
+
+// equityKey identifies the cards that decide the players' equities
+func equityKey(board []string, players []string) string {
+	names := append([]string(nil), players...)
+	sort.Strings(names)
+	return strings.Join(board, ",") + "|" + strings.Join(names, ",")
+}
@@ -0,0 +1,216 @@
+package replay
+
+import (
+	"math"
+	"testing"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+)
+
+const cashHand = `PokerStars Hand #245830112233:  Hold'em No Limit ($0.25/$0.50 USD) - 2023/03/04 15:15:42 ET
+Table 'Andromeda V' 6-max Seat #3 is the button
+Seat 1: Villain1 ($48.75 in chips)
+Seat 3: Hero ($50 in chips)
+Seat 5: big fish ($61.20 in chips)
+big fish: posts small blind $0.25
+Villain1: posts big blind $0.50
+*** HOLE CARDS ***
+Dealt to Hero [Qs Qd]
+Hero: raises $1 to $1.50
+big fish: calls $1.25
+Villain1: folds
+*** FLOP *** [Qh 7c 2d]
+big fish: checks
+Hero: bets $2
+big fish: raises $4 to $6
+Hero: calls $4
+*** TURN *** [Qh 7c 2d] [9s]
+big fish: bets $10
+Hero: raises $32.50 to $42.50 and is all-in
+big fish: calls $32.50
+*** RIVER *** [Qh 7c 2d 9s] [3h]
+*** SHOW DOWN ***
+big fish: shows [7d 7s] (three of a kind, Sevens)
+Hero: shows [Qs Qd] (three of a kind, Queens)
+Hero collected $97.50 from pot
+*** SUMMARY ***
+Total pot $100.50 | Rake $3
+Board [Qh 7c 2d 9s 3h]
+Seat 1: Villain1 (big blind) folded before Flop
+Seat 3: Hero (button) showed [Qs Qd] and won ($97.50) with three of a kind, Queens
+Seat 5: big fish (small blind) showed [7d 7s] and lost with three of a kind, Sevens
+`
+
+func parse(t *testing.T, text string) *handhistory.HandHistory {
+	hands, errs := handhistory.ParseString(text)
+	if len(errs) != 0 || len(hands) != 1 {
+		t.Fatalf("Expected 1 hand, got %d hands and %v", len(hands), errs)
+	}
+	return hands[0]
+}
+
+func TestBuild(t *testing.T) {
+	tl, err := Build(parse(t, cashHand), Options{Simulations: 200})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Start, 2 blinds, 3 preflop actions, flop, 4 actions, turn, 3 actions,
+	// river, 2 shows and the collection
+	if len(tl.Steps) != 19 {
+		t.Fatalf("Expected 19 steps, got %d", len(tl.Steps))
+	}
+
+	// The chips in front of the players and in the middle never change
+	total := int64(4875 + 5000 + 6120)
+	for _, step := range tl.Steps {
+		sum := step.Pot
+		for _, s := range step.Seats {
+			sum += s.Stack
+		}
+		if sum != total {
+			t.Errorf("Step %d (%s): expected %d chips, got %d", step.Index, step.Description, total, sum)
+		}
+	}
+
+	blinds := tl.Steps[2]
+	if blinds.Pot != 75 || blinds.CurrentBet != 50 || blinds.ToAct != "Hero" {
+		t.Errorf("Expected Hero to act facing 50 with 75 in the pot, got %+v", blinds)
+	}
+	if o := blinds.Options; o == nil || !o.Allows(game.Raise) || o.CallAmount != 50 || o.MinAmount != 100 || o.MaxAmount != 5000 {
+		t.Errorf("Unexpected preflop options: %+v", blinds.Options)
+	}
+
+	flop := tl.Steps[6]
+	if flop.Description != "Flop [Qh 7c 2d]" || len(flop.Board) != 3 || flop.ToAct != "big fish" {
+		t.Errorf("Unexpected flop step: %q board %v to act %q", flop.Description, flop.Board, flop.ToAct)
+	}
+	if o := flop.Options; o == nil || !o.Allows(game.Check) || !o.Allows(game.Bet) {
+		t.Errorf("Expected check or bet on the flop, got %+v", flop.Options)
+	}
+
+	// Cards shown down are known throughout
+	for _, s := range flop.Seats {
+		if (s.Equity != nil) != !s.Folded {
+			t.Errorf("Unexpected equity for %s: %v", s.Name, s.Equity)
+		}
+	}
+
+	raise := tl.Steps[13]
+	if raise.Action == nil || raise.Action.Type != handhistory.Raise || raise.Description != "Hero: raises $32.50 to $42.50 and is all-in" {
+		t.Errorf("Unexpected turn raise step: %+v", raise)
+	}
+	if hero := raise.Seats[1]; !hero.AllIn || hero.Stack != 0 || hero.Bet != 4250 {
+		t.Errorf("Expected Hero all-in for 4250, got %+v", hero)
+	}
+	// Facing an all-in, the caller can only call or fold
+	if o := raise.Options; o == nil || o.Allows(game.Raise) || o.CallAmount != 3250 {
+		t.Errorf("Unexpected options facing the all-in: %+v", raise.Options)
+	}
+
+	last := tl.Steps[len(tl.Steps)-1]
+	if last.Pot != 300 || last.Seats[1].Won != 9750 || last.Seats[1].Stack != 9750 {
+		t.Errorf("Expected Hero to win 9750 with the 300 rake left, got %+v", last)
+	}
+	if last.ToAct != "" || last.Options != nil {
+		t.Errorf("Expected nobody to act at the end, got %q", last.ToAct)
+	}
+}
+
+func TestBuildKnownHands(t *testing.T) {
+	hh := parse(t, cashHand)
+	hh.SeatOf("big fish").HoleCards = []string{"D7", "S7"}
+	hh.SeatOf("Villain1").HoleCards = []string{"C5", "C4"}
+
+	tl, err := Build(hh, Options{Simulations: 500})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// On the turn Hero's set of queens can only lose to the last seven
+	turn := tl.Steps[11]
+	if turn.Description != "Turn [9s]" {
+		t.Fatalf("Expected the turn at step 11, got %q", turn.Description)
+	}
+	hero, fish := turn.Seats[1].Equity, turn.Seats[2].Equity
+	if hero == nil || fish == nil || turn.Seats[0].Equity != nil {
+		t.Fatalf("Expected equities for the live players only, got %+v", turn.Seats)
+	}
+	if math.Abs(*fish-1.0/44) > 1e-9 || math.Abs(*hero+*fish-1) > 1e-9 {
+		t.Errorf("Expected big fish to have 1/44 equity, got %v and %v", *hero, *fish)
+	}
+
+	preflop := tl.Steps[2]
+	var sum float64
+	for _, s := range preflop.Seats {
+		if s.Equity == nil {
+			t.Fatalf("Expected equity for %s preflop", s.Name)
+		}
+		sum += *s.Equity
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected equities to add up to 1, got %v", sum)
+	}
+}
+
+// TestBuildSomeHandsKnown checks that hands known at a step are dealt
+// together against random hands for the others
+func TestBuildSomeHandsKnown(t *testing.T) {
+	hh := parse(t, cashHand)
+	hh.SeatOf("big fish").HoleCards = []string{"D7", "S7"}
+
+	tl, err := Build(hh, Options{Simulations: 2000, Seed: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	preflop := tl.Steps[2]
+	hero, fish := preflop.Seats[1].Equity, preflop.Seats[2].Equity
+	if hero == nil || fish == nil || preflop.Seats[0].Equity != nil {
+		t.Fatalf("Expected equities for the known hands only, got %+v", preflop.Seats)
+	}
+	// Queens against sevens and a random hand; neither sees the other as
+	// random
+	if *hero < 0.55 || *hero > 0.7 || *fish < 0.15 || *fish > 0.3 {
+		t.Errorf("Expected about 62%% and 22%%, got %v and %v", *hero, *fish)
+	}
+}
+
+func TestBuildHeroOnly(t *testing.T) {
+	hh := parse(t, cashHand)
+	hh.SeatOf("big fish").HoleCards = nil
+	var actions []handhistory.Action
+	for _, a := range hh.Actions {
+		if a.Type != handhistory.Show {
+			actions = append(actions, a)
+		}
+	}
+	hh.Actions = actions
+
+	tl, err := Build(hh, Options{Simulations: 200})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, s := range tl.Steps[6].Seats {
+		if (s.Equity != nil) != (s.Name == "Hero") {
+			t.Errorf("Expected equity for Hero only, got %v for %s", s.Equity, s.Name)
+		}
+	}
+	if e := tl.Steps[6].Seats[1].Equity; e == nil || *e < 0.8 {
+		t.Errorf("Expected top set to be a big favourite, got %v", e)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	hh := parse(t, cashHand)
+	hh.Limit = "Spread Limit"
+	if _, err := Build(hh, Options{NoEquity: true}); err == nil {
+		t.Errorf("Expected error for unknown betting structure")
+	}
+
+	hh = parse(t, cashHand)
+	hh.Actions[3].Player = "Nobody"
+	if _, err := Build(hh, Options{NoEquity: true}); err == nil {
+		t.Errorf("Expected error for unknown player")
+	}
+}
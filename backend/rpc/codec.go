@@ -0,0 +1,35 @@
+package rpc
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// JSONCodec carries the protobuf messages as JSON, with the field names of
+// the HTTP API. It is not registered: a server opts in with
+// grpc.ForceServerCodec(JSONCodec{}) and then serves JSON only, and a
+// client calls it with the grpc.ForceCodec(JSONCodec{}) call option, which
+// sends the content subtype "json", application/grpc+json on the wire.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("rpc: cannot encode %T as JSON, not a protobuf message", v)
+	}
+	return protojson.Marshal(m)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("rpc: cannot decode JSON into %T, not a protobuf message", v)
+	}
+	return protojson.Unmarshal(data, m)
+}
+
+func (JSONCodec) Name() string {
+	return "json"
+}
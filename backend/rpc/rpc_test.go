@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"texas-holdem-backend/api"
+	holdemv1 "texas-holdem-backend/proto/holdem/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testClient starts a server on an in-process listener and connects to it
+func testClient(t *testing.T, admit func() error, opts ...grpc.ServerOption) holdemv1.PokerClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := NewServer(admit, opts...)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return holdemv1.NewPokerClient(conn)
+}
+
+func TestEvaluateAndCompare(t *testing.T) {
+	c := testClient(t, nil)
+	ctx := context.Background()
+
+	eval, err := c.Evaluate(ctx, &holdemv1.EvaluateHandRequest{HoleCards: []string{"HA", "HK"}, BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if eval.BestHand != "Royal Flush" || eval.Strength == nil || eval.Strength.Label != "nuts" {
+		t.Errorf("Expected the nut royal flush, got %+v", eval)
+	}
+
+	cmp, err := c.Compare(ctx, &holdemv1.CompareHandsRequest{
+		Player1HoleCards: []string{"SA", "SK"},
+		Player2HoleCards: []string{"C2", "D7"},
+		CommunityCards:   []string{"HQ", "HJ", "HT", "D2", "C3"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmp.Winner != "Player 1" || cmp.Player1.BestHand != "Straight" {
+		t.Errorf("Expected player 1 to win with a straight, got %+v", cmp)
+	}
+}
+
+func TestShowdown(t *testing.T) {
+	resp, err := testClient(t, nil).Showdown(context.Background(), &holdemv1.ShowdownRequest{
+		Hands:          []*holdemv1.Hand{{Cards: []string{"SA", "SK"}}, {Cards: []string{"C2", "D7"}}, {Cards: []string{"DA", "DK"}}},
+		CommunityCards: []string{"HQ", "HJ", "HT", "D2", "C3"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Hands) != 3 || len(resp.Winners) != 2 || resp.Winners[0] != 0 || resp.Winners[1] != 2 {
+		t.Errorf("Expected players 0 and 2 to split, got %+v", resp)
+	}
+}
+
+func TestEquity(t *testing.T) {
+	c := testClient(t, nil)
+	ctx := context.Background()
+
+	// On the turn AA has 44 rivers, of which only the 2 remaining kings lose
+	resp, err := c.Equity(ctx, &holdemv1.EquityRequest{
+		Hands:      []*holdemv1.Hand{{Cards: []string{"HA", "DA"}}, {Cards: []string{"HK", "DK"}}},
+		BoardCards: []string{"C7", "S8", "D2", "H3"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.Exact || resp.Simulations != 44 || resp.Equity[0] != 42.0/44 {
+		t.Errorf("Expected 42 of 44 rivers for aces, got %+v", resp)
+	}
+
+	stream, err := c.EquityProgress(ctx, &holdemv1.EquityRequest{
+		Hands:          []*holdemv1.Hand{{Cards: []string{"HA", "DA"}}, {Cards: []string{"HK", "DK"}}},
+		NumSimulations: 2500,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var updates []*holdemv1.EquityResponse
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		updates = append(updates, m)
+	}
+	if len(updates) != 3 || updates[0].Done != 1000 || updates[2].Done != 2500 || updates[2].Simulations != 2500 {
+		t.Fatalf("Expected progress at 1000, 2000 and 2500 simulations, got %d updates", len(updates))
+	}
+	if last := updates[2].Equity; last[0] < 0.75 || last[0] > 0.88 || last[0]+last[1] < 0.999 {
+		t.Errorf("Expected aces to have about 82%% against kings, got %v", last)
+	}
+}
+
+func TestInvalidRequest(t *testing.T) {
+	_, err := testClient(t, nil).Evaluate(context.Background(), &holdemv1.EvaluateHandRequest{
+		HoleCards:  []string{"HA", "HA"},
+		BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"},
+	})
+	st, _ := status.FromError(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	var field string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok && len(br.FieldViolations) > 0 {
+			field = br.FieldViolations[0].Field
+		}
+	}
+	if field != "holeCards" {
+		t.Errorf("Expected the violation on holeCards, got %q", field)
+	}
+}
+
+func TestBusy(t *testing.T) {
+	c := testClient(t, func() error {
+		return &api.Error{Code: api.CodeUnavailable, Message: "Server busy"}
+	})
+	req := &holdemv1.EquityRequest{Hands: []*holdemv1.Hand{{Cards: []string{"HA", "DA"}}, {Cards: []string{"SK", "CK"}}}}
+	if _, err := c.Equity(context.Background(), req); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable, got %v", err)
+	}
+	stream, err := c.EquityProgress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable, got %v", err)
+	}
+}
+
+func TestJSONCodec(t *testing.T) {
+	c := testClient(t, nil, grpc.ForceServerCodec(JSONCodec{}))
+	req := &holdemv1.EvaluateHandRequest{HoleCards: []string{"HA", "HK"}, BoardCards: []string{"HQ", "HJ", "HT", "D2", "C3"}}
+
+	eval, err := c.Evaluate(context.Background(), req, grpc.ForceCodec(JSONCodec{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if eval.BestHand != "Royal Flush" || eval.Strength.GetLabel() != "nuts" {
+		t.Errorf("Expected the nut royal flush, got %v", eval)
+	}
+
+	// The server speaks JSON only once it is forced to
+	if _, err := c.Evaluate(context.Background(), req); err == nil {
+		t.Error("Expected a protobuf call to a JSON server to fail")
+	}
+}
@@ -0,0 +1,196 @@
+// Package rpc serves the evaluation, comparison and equity operations over
+// gRPC, next to the HTTP API and sharing its validation through the service
+// package. The service and its messages are defined in
+// proto/holdem/v1/poker.proto; messages are protobuf on the wire unless the
+// server is given JSONCodec.
+package rpc
+
+//go:generate protoc -I ../proto --go_out=../proto --go_opt=paths=source_relative --go-grpc_out=../proto --go-grpc_opt=paths=source_relative holdem/v1/poker.proto
+
+import (
+	"context"
+	"errors"
+
+	"texas-holdem-backend/api"
+	holdemv1 "texas-holdem-backend/proto/holdem/v1"
+	"texas-holdem-backend/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server answers the Poker service
+type Server struct {
+	holdemv1.UnimplementedPokerServer
+
+	// Admit, if set, is asked before each equity simulation; its error turns
+	// the call away
+	Admit func() error
+}
+
+// NewServer returns a gRPC server with the Poker service registered,
+// running equity simulations when admit allows. Pass
+// grpc.ForceServerCodec(JSONCodec{}) to serve JSON instead of protobuf.
+func NewServer(admit func() error, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	holdemv1.RegisterPokerServer(s, Server{Admit: admit})
+	return s
+}
+
+// admit reports whether another simulation may start
+func (s Server) admit() error {
+	if s.Admit == nil {
+		return nil
+	}
+	return s.Admit()
+}
+
+func (Server) Evaluate(ctx context.Context, req *holdemv1.EvaluateHandRequest) (*holdemv1.EvaluateHandResponse, error) {
+	resp, err := service.Evaluate(api.EvaluateHandRequest{HoleCards: req.HoleCards, BoardCards: req.BoardCards}, true)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return evaluateResponse(resp), nil
+}
+
+func (Server) Compare(ctx context.Context, req *holdemv1.CompareHandsRequest) (*holdemv1.CompareHandsResponse, error) {
+	resp, err := service.Compare(api.CompareHandsRequest{
+		Player1HoleCards: req.Player1HoleCards,
+		Player2HoleCards: req.Player2HoleCards,
+		CommunityCards:   req.CommunityCards,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &holdemv1.CompareHandsResponse{
+		Player1: evaluateResponse(resp.Player1),
+		Player2: evaluateResponse(resp.Player2),
+		Winner:  resp.Winner,
+	}, nil
+}
+
+func (Server) Showdown(ctx context.Context, req *holdemv1.ShowdownRequest) (*holdemv1.ShowdownResponse, error) {
+	resp, err := service.Showdown(api.ShowdownRequest{Hands: hands(req.Hands), CommunityCards: req.CommunityCards})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &holdemv1.ShowdownResponse{}
+	for _, h := range resp.Hands {
+		out.Hands = append(out.Hands, evaluateResponse(h))
+	}
+	for _, w := range resp.Winners {
+		out.Winners = append(out.Winners, int32(w))
+	}
+	return out, nil
+}
+
+func (s Server) Equity(ctx context.Context, req *holdemv1.EquityRequest) (*holdemv1.EquityResponse, error) {
+	if err := s.admit(); err != nil {
+		return nil, toStatus(err)
+	}
+	resp, err := service.Equity(ctx, equityRequest(req), nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return equityResponse(resp), nil
+}
+
+func (s Server) EquityProgress(req *holdemv1.EquityRequest, stream holdemv1.Poker_EquityProgressServer) error {
+	if err := s.admit(); err != nil {
+		return toStatus(err)
+	}
+	var sendErr error
+	_, err := service.Equity(stream.Context(), equityRequest(req), func(progress api.EquityResponse) {
+		if sendErr == nil {
+			sendErr = stream.Send(equityResponse(progress))
+		}
+	})
+	if err == nil {
+		err = sendErr
+	}
+	return toStatus(err)
+}
+
+// hands turns each player's hole cards into the lists the service takes
+func hands(in []*holdemv1.Hand) [][]string {
+	out := make([][]string, len(in))
+	for i, h := range in {
+		out[i] = h.GetCards()
+	}
+	return out
+}
+
+func equityRequest(req *holdemv1.EquityRequest) api.EquityRequest {
+	return api.EquityRequest{
+		Hands:          hands(req.Hands),
+		BoardCards:     req.BoardCards,
+		NumSimulations: int(req.NumSimulations),
+		Seed:           req.Seed,
+	}
+}
+
+func equityResponse(resp api.EquityResponse) *holdemv1.EquityResponse {
+	return &holdemv1.EquityResponse{
+		Equity:      resp.Equity,
+		Done:        int32(resp.Done),
+		Simulations: int32(resp.Simulations),
+		Exact:       resp.Exact,
+	}
+}
+
+func evaluateResponse(resp api.EvaluateHandResponse) *holdemv1.EvaluateHandResponse {
+	out := &holdemv1.EvaluateHandResponse{BestHand: resp.BestHand, HandValue: resp.HandValue, Cards: resp.Cards}
+	if s := resp.Strength; s != nil {
+		out.Strength = &holdemv1.HandStrength{
+			Nuts:         rankedHand(s.Nuts),
+			Position:     int32(s.Position),
+			Label:        s.Label,
+			Beats:        s.Beats,
+			CombosAhead:  int32(s.CombosAhead),
+			CombosTied:   int32(s.CombosTied),
+			CombosBehind: int32(s.CombosBehind),
+		}
+		for _, h := range s.Ahead {
+			out.Strength.Ahead = append(out.Strength.Ahead, rankedHand(h))
+		}
+	}
+	return out
+}
+
+func rankedHand(h api.RankedHand) *holdemv1.RankedHand {
+	return &holdemv1.RankedHand{Hand: h.Hand, Value: h.Value, Combos: int32(h.Combos), Example: h.Example}
+}
+
+// toStatus turns an error into a gRPC status. An invalid request becomes
+// InvalidArgument, with the field at fault as a BadRequest detail, and a
+// busy server Unavailable.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var e *api.Error
+	switch {
+	case errors.As(err, &e) && e.Code == api.CodeInvalidRequest:
+		st := status.New(codes.InvalidArgument, e.Message)
+		if e.Field != "" {
+			if detailed, derr := st.WithDetails(&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: e.Field, Description: e.Message}},
+			}); derr == nil {
+				st = detailed
+			}
+		}
+		return st.Err()
+	case errors.As(err, &e) && e.Code == api.CodeUnavailable:
+		return status.Error(codes.Unavailable, e.Message)
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
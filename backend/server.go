@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// serverConfig holds the HTTP server's timeouts and limits. Each can be set
+// through the environment variable named beside it; durations are written
+// like "30s" and sizes in bytes.
+type serverConfig struct {
+	ReadHeaderTimeout time.Duration // HTTP_READ_HEADER_TIMEOUT
+	ReadTimeout       time.Duration // HTTP_READ_TIMEOUT, headers and body
+	WriteTimeout      time.Duration // HTTP_WRITE_TIMEOUT, from the end of the headers to the end of the response
+	IdleTimeout       time.Duration // HTTP_IDLE_TIMEOUT, between requests on a kept-alive connection
+
+	// DrainDelay is how long the server keeps accepting requests after
+	// SIGTERM, reporting not ready so that it is taken out of the load
+	// balancer first (SHUTDOWN_DELAY)
+	DrainDelay time.Duration
+	// ShutdownGrace is how long requests may then keep running before they
+	// are cancelled (SHUTDOWN_GRACE)
+	ShutdownGrace time.Duration
+
+	MaxBodyBytes   int64 // MAX_BODY_BYTES, for JSON requests
+	MaxUploadBytes int64 // MAX_UPLOAD_BYTES, for hand histories and batches
+
+	// SimulationCapacity is how many simulations may run at once; more are
+	// turned away as unavailable (SIMULATION_CAPACITY)
+	SimulationCapacity int
+}
+
+// cancelWait is how long cancelled requests get to answer before their
+// connections are closed
+const cancelWait = 5 * time.Second
+
+func defaultServerConfig() serverConfig {
+	return serverConfig{
+		ReadHeaderTimeout:  10 * time.Second,
+		ReadTimeout:        30 * time.Second,
+		WriteTimeout:       2 * time.Minute,
+		IdleTimeout:        2 * time.Minute,
+		DrainDelay:         5 * time.Second,
+		ShutdownGrace:      20 * time.Second,
+		MaxBodyBytes:       1 << 20,
+		MaxUploadBytes:     32 << 20,
+		SimulationCapacity: 8,
+	}
+}
+
+// loadServerConfig reads the configuration from getenv, keeping the
+// defaults for variables that are not set
+func loadServerConfig(getenv func(string) string) (serverConfig, error) {
+	cfg := defaultServerConfig()
+	durations := []struct {
+		name string
+		v    *time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", &cfg.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", &cfg.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", &cfg.IdleTimeout},
+		{"SHUTDOWN_DELAY", &cfg.DrainDelay},
+		{"SHUTDOWN_GRACE", &cfg.ShutdownGrace},
+	}
+	for _, d := range durations {
+		s := getenv(d.name)
+		if s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(s)
+		if err != nil || v < 0 {
+			return cfg, fmt.Errorf("%s: %q is not a duration", d.name, s)
+		}
+		*d.v = v
+	}
+	sizes := []struct {
+		name string
+		v    *int64
+	}{
+		{"MAX_BODY_BYTES", &cfg.MaxBodyBytes},
+		{"MAX_UPLOAD_BYTES", &cfg.MaxUploadBytes},
+	}
+	for _, sz := range sizes {
+		s := getenv(sz.name)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v <= 0 {
+			return cfg, fmt.Errorf("%s: %q is not a size in bytes", sz.name, s)
+		}
+		*sz.v = v
+	}
+	if s := getenv("SIMULATION_CAPACITY"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			return cfg, fmt.Errorf("SIMULATION_CAPACITY: %q is not a positive number", s)
+		}
+		cfg.SimulationCapacity = v
+	}
+	return cfg, nil
+}
+
+// newServer returns an HTTP server for h with the configured timeouts
+func newServer(cfg serverConfig, h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// limitBody caps the size of request bodies. Reading past max fails with an
+// *http.MaxBytesError, which handlers report as 413.
+func limitBody(max int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, max)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serve runs srv on lis until ctx is done, then drains it: after the drain
+// delay no new connections are accepted and active requests may finish.
+// Requests still running after the grace period have their contexts
+// cancelled, which stops their simulations, and cancelWait later any
+// connection left is closed.
+func serve(ctx context.Context, srv *http.Server, lis net.Listener, cfg serverConfig) error {
+	base, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv.BaseContext = func(net.Listener) context.Context { return base }
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(lis) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("draining", "delay", cfg.DrainDelay.String(), "grace", cfg.ShutdownGrace.String())
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(cfg.DrainDelay):
+	}
+	timer := time.AfterFunc(cfg.ShutdownGrace, func() {
+		slog.Warn("grace period over, cancelling requests")
+		cancel()
+	})
+	defer timer.Stop()
+	shutdown, stop := context.WithTimeout(context.Background(), cfg.ShutdownGrace+cancelWait)
+	defer stop()
+	if err := srv.Shutdown(shutdown); err != nil {
+		srv.Close()
+		return fmt.Errorf("draining: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("drained")
+	return nil
+}
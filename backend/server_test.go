@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/api"
+	"texas-holdem-backend/health"
+	"texas-holdem-backend/metrics"
+	"texas-holdem-backend/poker"
+	"texas-holdem-backend/store"
+)
+
+func TestLoadServerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(serverConfig) bool
+		wantErr bool
+	}{
+		{"defaults", nil, func(c serverConfig) bool { return c == defaultServerConfig() }, false},
+		{
+			"overrides",
+			map[string]string{"HTTP_WRITE_TIMEOUT": "90s", "SHUTDOWN_GRACE": "1m", "MAX_BODY_BYTES": "2048", "SIMULATION_CAPACITY": "3"},
+			func(c serverConfig) bool {
+				return c.WriteTimeout == 90*time.Second && c.ShutdownGrace == time.Minute && c.MaxBodyBytes == 2048 &&
+					c.SimulationCapacity == 3 && c.ReadTimeout == defaultServerConfig().ReadTimeout
+			},
+			false,
+		},
+		{"bad duration", map[string]string{"HTTP_READ_TIMEOUT": "30"}, nil, true},
+		{"negative duration", map[string]string{"SHUTDOWN_GRACE": "-1s"}, nil, true},
+		{"bad size", map[string]string{"MAX_UPLOAD_BYTES": "32MB"}, nil, true},
+		{"zero size", map[string]string{"MAX_BODY_BYTES": "0"}, nil, true},
+		{"bad capacity", map[string]string{"SIMULATION_CAPACITY": "many"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadServerConfig(func(name string) string { return tt.env[name] })
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("Unexpected configuration: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestBodyLimits(t *testing.T) {
+	cfg := defaultServerConfig()
+	cfg.MaxBodyBytes = 100
+	cfg.MaxUploadBytes = 256
+	r := configuredRouter(t, cfg)
+	evaluate := `{"holeCards": ["HA", "HK"], "boardCards": ["HQ", "HJ", "HT", "D2", "C3"]}`
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		tooLong bool
+		max     float64
+	}{
+		{"json under the limit", "/evaluate", evaluate, false, 0},
+		{"json over the limit", "/evaluate", "{" + strings.Repeat(" ", 100) + evaluate[1:], true, 100},
+		{"upload over the json limit", "/evaluate/batch", "[" + evaluate + "," + evaluate + "]", false, 0},
+		{"upload over its limit", "/evaluate/batch", "[" + strings.Repeat(evaluate+",", 3) + evaluate + "]", true, 256},
+		{"hand history over its limit", "/hands/import", strings.Repeat("x", 300), true, 256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("POST", api.Prefix+tt.path, strings.NewReader(tt.body)))
+			if !tt.tooLong {
+				if rec.Code != http.StatusOK {
+					t.Errorf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("Expected status 413, got %d: %s", rec.Code, rec.Body)
+			}
+			var e api.Error
+			json.Unmarshal(rec.Body.Bytes(), &e)
+			if e.Code != api.CodeTooLarge || e.Details["max"] != tt.max {
+				t.Errorf("Expected a too_large error with max %v, got %+v", tt.max, e)
+			}
+		})
+	}
+}
+
+// startServer serves h on a local port until the returned context is
+// cancelled; serve's result arrives on the channel
+func startServer(t *testing.T, cfg serverConfig, h http.Handler) (string, context.CancelFunc, chan error) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, newServer(cfg, h), lis, cfg) }()
+	t.Cleanup(cancel)
+	return "http://" + lis.Addr().String(), cancel, done
+}
+
+func TestServeDrains(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	})
+	cfg := defaultServerConfig()
+	cfg.DrainDelay = 0
+	url, shutdown, done := startServer(t, cfg, h)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	res := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		res <- result{status: resp.StatusCode, body: string(body)}
+	}()
+	<-started
+	shutdown()
+
+	// New connections are refused while the request is still running
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the listener to close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Expected serve to wait for the request, got %v", err)
+	default:
+	}
+
+	close(release)
+	if got := <-res; got.err != nil || got.status != http.StatusOK || got.body != "finished" {
+		t.Errorf("Expected the request to finish, got %+v", got)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestServeCancelsAfterGrace(t *testing.T) {
+	cfg := defaultServerConfig()
+	cfg.DrainDelay = 0
+	cfg.ShutdownGrace = 50 * time.Millisecond
+	started := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	url, shutdown, done := startServer(t, cfg, h)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+	shutdown()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(cancelWait):
+		t.Fatal("Expected the request to be cancelled")
+	}
+	if got := <-status; got != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", got)
+	}
+}
+
+func TestReadHeaderTimeout(t *testing.T) {
+	cfg := defaultServerConfig()
+	cfg.ReadHeaderTimeout = 50 * time.Millisecond
+	url, _, _ := startServer(t, cfg, http.NotFoundHandler())
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: poker\r\n")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Errorf("Expected the server to close the connection, got %v", err)
+	}
+}
+
+// TestSimulateSeeded checks that the chunks of a simulation share one
+// generator, giving the odds of a single run with the request's seed
+func TestSimulateSeeded(t *testing.T) {
+	req := api.MonteCarloRequest{HoleCards: []string{"HA", "SA"}, BoardCards: []string{"D7", "C2", "S9"}, NumPlayers: 3, NumSimulations: 2500, Seed: 42}
+	win, tie, loss, err := simulate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	w, ti, l := poker.MonteCarloSeeded(req.HoleCards, req.BoardCards, req.NumPlayers, req.NumSimulations, req.Seed)
+	if math.Abs(win-w) > 1e-9 || math.Abs(tie-ti) > 1e-9 || math.Abs(loss-l) > 1e-9 {
+		t.Errorf("Expected %v/%v/%v, got %v/%v/%v", w, ti, l, win, tie, loss)
+	}
+}
+
+func TestMonteCarloCancelled(t *testing.T) {
+	r := testRouter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := `{"holeCards": ["HA", "HK"], "boardCards": ["HQ", "HJ", "D2"], "numPlayers": 3, "numSimulations": 100000}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", api.Prefix+"/montecarlo", strings.NewReader(body)).WithContext(ctx))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d: %s", rec.Code, rec.Body)
+	}
+	var e api.Error
+	json.Unmarshal(rec.Body.Bytes(), &e)
+	if e.Code != api.CodeUnavailable {
+		t.Errorf("Expected code %s, got %s", api.CodeUnavailable, e.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	cfg := defaultServerConfig()
+	checker := health.NewChecker(func() error { return nil }, cfg.SimulationCapacity, metrics.SimulationsInFlight)
+	r, err := newRouter(store.NewMemory(), cfg, checker)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	readyz := func() (int, api.ReadinessResponse) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		var got api.ReadinessResponse
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		return rec.Code, got
+	}
+
+	if code, got := readyz(); code != http.StatusServiceUnavailable || got.SelfTest != "running" {
+		t.Errorf("Expected 503 before the self-test, got %d %+v", code, got)
+	}
+	checker.Run()
+	if code, got := readyz(); code != http.StatusOK || !got.Ready {
+		t.Errorf("Expected 200 once the self-test passed, got %d %+v", code, got)
+	}
+	checker.Drain()
+	if code, got := readyz(); code != http.StatusServiceUnavailable || !got.Draining {
+		t.Errorf("Expected 503 while draining, got %d %+v", code, got)
+	}
+
+	// The process is alive all along
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+// TestSimulationCapacity checks that a busy server stays ready and turns
+// simulations away until one finishes
+func TestSimulationCapacity(t *testing.T) {
+	cfg := defaultServerConfig()
+	cfg.SimulationCapacity = 1
+	r := configuredRouter(t, cfg)
+	montecarlo := func() *httptest.ResponseRecorder {
+		body := `{"holeCards": ["HA", "HK"], "boardCards": ["HQ", "HJ", "D2"], "numPlayers": 2, "numSimulations": 100}`
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("POST", api.Prefix+"/montecarlo", strings.NewReader(body)))
+		return rec
+	}
+
+	sim := metrics.StartSimulation("montecarlo")
+	rec := montecarlo()
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After at capacity, got %d %v", rec.Code, rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected a busy server to stay ready, got %d: %s", rec.Code, rec.Body)
+	}
+
+	sim.Done(0)
+	if rec := montecarlo(); rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 once the simulation finished, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// TestServeDrainDelay checks that requests are still served for the drain
+// delay, while the load balancer takes the server out
+func TestServeDrainDelay(t *testing.T) {
+	cfg := defaultServerConfig()
+	cfg.DrainDelay = 200 * time.Millisecond
+	url, shutdown, done := startServer(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	start := time.Now()
+	shutdown()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Expected requests to be served during the delay, got %v", err)
+	}
+	resp.Body.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < cfg.DrainDelay {
+		t.Errorf("Expected serve to wait %v, returned after %v", cfg.DrainDelay, elapsed)
+	}
+}
@@ -0,0 +1,311 @@
+// Package service holds the evaluation operations behind both the HTTP and
+// the gRPC servers, with the validation of their requests. Invalid requests
+// are reported as an *api.Error naming the field at fault.
+package service
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"texas-holdem-backend/api"
+	"texas-holdem-backend/logging"
+	"texas-holdem-backend/metrics"
+	"texas-holdem-backend/poker"
+)
+
+const (
+	// MaxPlayers is the most hands a showdown or equity request may hold
+	MaxPlayers = 10
+
+	// DefaultSimulations is the number of boards sampled for equity when
+	// the request does not say
+	DefaultSimulations = 10000
+
+	MinSimulations = 100
+	MaxSimulations = 100000
+
+	// equityChunk is how many boards are sampled between progress reports
+	equityChunk = 1000
+)
+
+// cardSet collects the cards of a request, reporting the first that cannot
+// be parsed or that was already dealt
+type cardSet map[string]bool
+
+func (s cardSet) add(field string, cards []string) ([]string, error) {
+	normalized := make([]string, len(cards))
+	for i, card := range cards {
+		c, err := poker.ParseCard(card)
+		if err != nil {
+			e := api.InvalidField(field, "%v", err)
+			e.Details = map[string]interface{}{"card": card}
+			return nil, e
+		}
+		normalized[i] = c.Suit + c.Rank
+		if s[normalized[i]] {
+			e := api.InvalidField(field, "card %s used twice", normalized[i])
+			e.Details = map[string]interface{}{"card": card}
+			return nil, e
+		}
+		s[normalized[i]] = true
+	}
+	return normalized, nil
+}
+
+func result(hand, value string, cards []string) api.EvaluateHandResponse {
+	return api.EvaluateHandResponse{BestHand: hand, HandValue: value, Cards: cards}
+}
+
+// handStrength copies a holding's place among the others into the response
+func handStrength(s *poker.HandStrength) *api.HandStrength {
+	ahead := make([]api.RankedHand, len(s.Ahead))
+	for i, h := range s.Ahead {
+		ahead[i] = api.RankedHand(h)
+	}
+	return &api.HandStrength{
+		Nuts: api.RankedHand(s.Nuts), Position: s.Position, Label: s.Label, Beats: s.Beats,
+		CombosAhead: s.CombosAhead, CombosTied: s.CombosTied, CombosBehind: s.CombosBehind, Ahead: ahead,
+	}
+}
+
+// Evaluate scores one hand of 2 hole cards and 5 board cards, and places it
+// among every holding on the board when strength is set
+func Evaluate(req api.EvaluateHandRequest, strength bool) (api.EvaluateHandResponse, error) {
+	if len(req.HoleCards) != 2 {
+		return api.EvaluateHandResponse{}, api.InvalidField("holeCards", "Must provide exactly 2 hole cards and 5 board cards")
+	}
+	if len(req.BoardCards) != 5 {
+		return api.EvaluateHandResponse{}, api.InvalidField("boardCards", "Must provide exactly 2 hole cards and 5 board cards")
+	}
+	seen := cardSet{}
+	hole, err := seen.add("holeCards", req.HoleCards)
+	if err != nil {
+		return api.EvaluateHandResponse{}, err
+	}
+	board, err := seen.add("boardCards", req.BoardCards)
+	if err != nil {
+		return api.EvaluateHandResponse{}, err
+	}
+
+	response := result(poker.EvaluateHand(append(hole, board...)))
+	metrics.Evaluated("evaluate", 1)
+	if strength {
+		if s, err := poker.RelativeStrength(hole, board); err == nil {
+			response.Strength = handStrength(s)
+		}
+	}
+	return response, nil
+}
+
+// Compare evaluates two players' hands on the same community cards
+func Compare(req api.CompareHandsRequest) (api.CompareHandsResponse, error) {
+	if len(req.Player1HoleCards) != 2 {
+		return api.CompareHandsResponse{}, api.InvalidField("player1HoleCards", "Player 1: Must provide exactly 2 hole cards")
+	}
+	if len(req.Player2HoleCards) != 2 {
+		return api.CompareHandsResponse{}, api.InvalidField("player2HoleCards", "Player 2: Must provide exactly 2 hole cards")
+	}
+	if len(req.CommunityCards) != 5 {
+		return api.CompareHandsResponse{}, api.InvalidField("communityCards", "Must provide exactly 5 community cards")
+	}
+	seen := cardSet{}
+	hole1, err := seen.add("player1HoleCards", req.Player1HoleCards)
+	if err != nil {
+		return api.CompareHandsResponse{}, err
+	}
+	hole2, err := seen.add("player2HoleCards", req.Player2HoleCards)
+	if err != nil {
+		return api.CompareHandsResponse{}, err
+	}
+	board, err := seen.add("communityCards", req.CommunityCards)
+	if err != nil {
+		return api.CompareHandsResponse{}, err
+	}
+
+	// Each player's 7 cards = 2 hole cards + 5 community cards
+	allCards1 := append(hole1, board...)
+	allCards2 := append(hole2, board...)
+	metrics.Evaluated("compare", 2)
+	return api.CompareHandsResponse{
+		Player1: result(poker.EvaluateHand(allCards1)),
+		Player2: result(poker.EvaluateHand(allCards2)),
+		Winner:  poker.CompareHands(allCards1, allCards2),
+	}, nil
+}
+
+// players checks the hole cards of a multiway request
+func players(seen cardSet, hands [][]string) ([][]string, error) {
+	if len(hands) < 2 || len(hands) > MaxPlayers {
+		return nil, api.InvalidField("hands", "Must provide between 2 and %d hands", MaxPlayers).WithLimits(2, MaxPlayers)
+	}
+	normalized := make([][]string, len(hands))
+	for i, hand := range hands {
+		if len(hand) != 2 {
+			return nil, api.InvalidField("hands", "Hand %d: Must provide exactly 2 hole cards", i+1)
+		}
+		var err error
+		if normalized[i], err = seen.add("hands", hand); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
+
+// Showdown evaluates every player's hand on the same community cards and
+// finds the winners
+func Showdown(req api.ShowdownRequest) (api.ShowdownResponse, error) {
+	seen := cardSet{}
+	hands, err := players(seen, req.Hands)
+	if err != nil {
+		return api.ShowdownResponse{}, err
+	}
+	if len(req.CommunityCards) != 5 {
+		return api.ShowdownResponse{}, api.InvalidField("communityCards", "Must provide exactly 5 community cards")
+	}
+	board, err := seen.add("communityCards", req.CommunityCards)
+	if err != nil {
+		return api.ShowdownResponse{}, err
+	}
+
+	response := api.ShowdownResponse{Hands: make([]api.EvaluateHandResponse, len(hands))}
+	all := make([][]string, len(hands))
+	best := 0
+	for i, hole := range hands {
+		all[i] = append(hole, board...)
+		response.Hands[i] = result(poker.EvaluateHand(all[i]))
+		if poker.CompareHands(all[i], all[best]) == "Player 1" {
+			best = i
+		}
+	}
+	for i := range all {
+		if i == best || poker.CompareHands(all[i], all[best]) == "Tie" {
+			response.Winners = append(response.Winners, i)
+		}
+	}
+	metrics.Evaluated("showdown", len(hands))
+	return response, nil
+}
+
+// CheckMonteCarlo validates a Monte Carlo request, returning it with its
+// cards normalized
+func CheckMonteCarlo(req api.MonteCarloRequest) (api.MonteCarloRequest, error) {
+	if len(req.HoleCards) != 2 {
+		return req, api.InvalidField("holeCards", "Must provide exactly 2 hole cards")
+	}
+	if len(req.BoardCards) > 5 {
+		return req, api.InvalidField("boardCards", "Board cards cannot exceed 5 cards")
+	}
+	if req.NumPlayers < 2 || req.NumPlayers > MaxPlayers {
+		return req, api.InvalidField("numPlayers", "Number of players must be between 2 and %d", MaxPlayers).WithLimits(2, MaxPlayers)
+	}
+	if req.NumSimulations < MinSimulations || req.NumSimulations > MaxSimulations {
+		return req, api.InvalidField("numSimulations", "Number of simulations must be between %d and %d", MinSimulations, MaxSimulations).
+			WithLimits(MinSimulations, MaxSimulations)
+	}
+	seen := cardSet{}
+	var err error
+	if req.HoleCards, err = seen.add("holeCards", req.HoleCards); err != nil {
+		return req, err
+	}
+	if req.BoardCards, err = seen.add("boardCards", req.BoardCards); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// Equity computes each player's share of the pot with the board still to
+// come. Sampled equities are reported to progress, when it is not nil,
+// after every chunk of boards; the context is checked between chunks, so a
+// cancelled request stops early with the context's error.
+func Equity(ctx context.Context, req api.EquityRequest, progress func(api.EquityResponse)) (api.EquityResponse, error) {
+	seen := cardSet{}
+	hands, err := players(seen, req.Hands)
+	if err != nil {
+		return api.EquityResponse{}, err
+	}
+	if len(req.BoardCards) > 5 {
+		return api.EquityResponse{}, api.InvalidField("boardCards", "Board cards cannot exceed 5 cards")
+	}
+	board, err := seen.add("boardCards", req.BoardCards)
+	if err != nil {
+		return api.EquityResponse{}, err
+	}
+	if req.NumSimulations == 0 {
+		req.NumSimulations = DefaultSimulations
+	}
+	if req.NumSimulations < MinSimulations || req.NumSimulations > MaxSimulations {
+		return api.EquityResponse{}, api.InvalidField("numSimulations", "Number of simulations must be between %d and %d", MinSimulations, MaxSimulations).
+			WithLimits(MinSimulations, MaxSimulations)
+	}
+
+	start := time.Now()
+	sim := metrics.StartSimulation("equity")
+	done := 0
+	defer func() {
+		sim.Done(done)
+		logging.FromContext(ctx).Info("simulation",
+			"kind", "equity",
+			"numPlayers", len(hands),
+			"numSimulations", req.NumSimulations,
+			"boardCards", len(board),
+			"done", done,
+			"durationMs", time.Since(start).Milliseconds(),
+		)
+	}()
+
+	// One or two cards to come are enumerated in one go
+	if toCome := 5 - len(board); toCome <= 2 {
+		equity, err := poker.HandsEquity(hands, board, 0, nil)
+		if err != nil {
+			return api.EquityResponse{}, err
+		}
+		runouts := 1
+		for i := 0; i < toCome; i++ {
+			runouts = runouts * (52 - len(seen) - i) / (i + 1)
+		}
+		done = runouts
+		response := api.EquityResponse{Equity: equity, Done: runouts, Simulations: runouts, Exact: true}
+		if progress != nil {
+			progress(response)
+		}
+		return response, nil
+	}
+
+	// Every chunk draws from the request's generator, so a seed reproduces
+	// the whole run
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	response := api.EquityResponse{Equity: make([]float64, len(hands)), Simulations: req.NumSimulations}
+	sums := make([]float64, len(hands))
+	for response.Done < req.NumSimulations {
+		if err := ctx.Err(); err != nil {
+			return api.EquityResponse{}, err
+		}
+		n := equityChunk
+		if left := req.NumSimulations - response.Done; left < n {
+			n = left
+		}
+		equity, err := poker.HandsEquity(hands, board, n, rng)
+		if err != nil {
+			return api.EquityResponse{}, err
+		}
+		response.Done += n
+		done = response.Done
+		for i, e := range equity {
+			sums[i] += e * float64(n)
+			response.Equity[i] = sums[i] / float64(response.Done)
+		}
+		if progress != nil {
+			progress(api.EquityResponse{
+				Equity:      append([]float64(nil), response.Equity...),
+				Done:        response.Done,
+				Simulations: response.Simulations,
+			})
+		}
+	}
+	return response, nil
+}
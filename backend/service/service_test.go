@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"texas-holdem-backend/api"
+)
+
+func TestValidation(t *testing.T) {
+	board := []string{"HQ", "HJ", "HT", "D2", "C3"}
+	tests := []struct {
+		name  string
+		call  func() error
+		field string
+	}{
+		{"hole cards", func() error {
+			_, err := Evaluate(api.EvaluateHandRequest{HoleCards: []string{"HA"}, BoardCards: board}, false)
+			return err
+		}, "holeCards"},
+		{"bad card", func() error {
+			_, err := Evaluate(api.EvaluateHandRequest{HoleCards: []string{"HA", "XK"}, BoardCards: board}, false)
+			return err
+		}, "holeCards"},
+		{"card dealt twice", func() error {
+			_, err := Compare(api.CompareHandsRequest{Player1HoleCards: []string{"HA", "HK"}, Player2HoleCards: []string{"SA", "hq"}, CommunityCards: board})
+			return err
+		}, "communityCards"},
+		{"one player", func() error {
+			_, err := Showdown(api.ShowdownRequest{Hands: [][]string{{"HA", "HK"}}, CommunityCards: board})
+			return err
+		}, "hands"},
+		{"monte carlo card dealt twice", func() error {
+			_, err := CheckMonteCarlo(api.MonteCarloRequest{HoleCards: []string{"HA", "ha"}, NumPlayers: 2, NumSimulations: 100})
+			return err
+		}, "holeCards"},
+		{"monte carlo players", func() error {
+			_, err := CheckMonteCarlo(api.MonteCarloRequest{HoleCards: []string{"HA", "HK"}, NumPlayers: 11, NumSimulations: 100})
+			return err
+		}, "numPlayers"},
+		{"simulations", func() error {
+			_, err := Equity(context.Background(), api.EquityRequest{Hands: [][]string{{"HA", "HK"}, {"SA", "SK"}}, NumSimulations: 10}, nil)
+			return err
+		}, "numSimulations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e *api.Error
+			if err := tt.call(); !errors.As(err, &e) || e.Field != tt.field || e.Code != api.CodeInvalidRequest {
+				t.Errorf("Expected an invalid %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestEquityCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := 0
+	_, err := Equity(ctx, api.EquityRequest{Hands: [][]string{{"HA", "DA"}, {"HK", "DK"}}, NumSimulations: 5000}, func(api.EquityResponse) {
+		updates++
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) || updates != 1 {
+		t.Errorf("Expected to stop after the first chunk, got %d updates and %v", updates, err)
+	}
+}
+
+func TestEquitySeeded(t *testing.T) {
+	req := api.EquityRequest{Hands: [][]string{{"HA", "DA"}, {"HK", "DK"}}, NumSimulations: 2500, Seed: 11}
+	first, err := Equity(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	again, _ := Equity(context.Background(), req, nil)
+	for i := range first.Equity {
+		if first.Equity[i] != again.Equity[i] {
+			t.Fatalf("Expected the same equities from seed %d, got %v and %v", req.Seed, first.Equity, again.Equity)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+)
+
+// HandStats is what a single hand contributes to one player's statistics.
+// Each pair of fields is an opportunity and whether the player took it.
+type HandStats struct {
+	Position string
+
+	VPIP bool // Every hand dealt is a VPIP and PFR opportunity
+	PFR  bool
+
+	ThreeBetOpportunity bool // Faced a single raise preflop
+	ThreeBet            bool
+
+	FacedThreeBet bool // Opened and was re-raised
+	FoldedToThree bool
+
+	CBetOpportunity bool // Was the preflop aggressor and could bet the flop first
+	CBet            bool
+
+	Aggressive int // Postflop bets and raises
+	Passive    int // Postflop calls
+
+	SawFlop        bool
+	WentToShowdown bool
+	WonAtShowdown  bool
+}
+
+// Analyze works out the statistics of every player dealt into a hand
+func Analyze(hh *handhistory.HandHistory) map[string]*HandStats {
+	out := make(map[string]*HandStats)
+	positions := Positions(hh)
+	for name, pos := range positions {
+		out[name] = &HandStats{Position: pos}
+	}
+
+	raises := 0            // Preflop raises, not counting the big blind
+	opener := ""           // Player who made the first preflop raise
+	preflopAggressor := "" // Player who made the last preflop raise
+	folded := make(map[string]bool)
+	flopBet := false
+	showdown := false
+
+	for _, a := range hh.Actions {
+		p, ok := out[a.Player]
+		if !ok {
+			continue
+		}
+
+		switch a.Street {
+		case game.Preflop:
+			if !isDecision(a.Type) {
+				break
+			}
+			if raises == 1 && a.Player != opener {
+				p.ThreeBetOpportunity = true
+			}
+			if raises == 2 && a.Player == opener && !p.FacedThreeBet {
+				p.FacedThreeBet = true
+				p.FoldedToThree = a.Type == handhistory.Fold
+			}
+			switch a.Type {
+			case handhistory.Call:
+				p.VPIP = true
+			case handhistory.Raise, handhistory.Bet:
+				p.VPIP = true
+				p.PFR = true
+				raises++
+				if raises == 1 {
+					opener = a.Player
+				}
+				if raises == 2 && p.ThreeBetOpportunity {
+					p.ThreeBet = true
+				}
+				preflopAggressor = a.Player
+			}
+
+		case game.Flop, game.Turn, game.River:
+			if a.Street == game.Flop && isDecision(a.Type) {
+				if a.Player == preflopAggressor && !flopBet && !p.CBetOpportunity {
+					p.CBetOpportunity = true
+					p.CBet = a.Type == handhistory.Bet
+				}
+				if a.Type == handhistory.Bet || a.Type == handhistory.Raise {
+					flopBet = true
+				}
+			}
+			switch a.Type {
+			case handhistory.Bet, handhistory.Raise:
+				p.Aggressive++
+			case handhistory.Call:
+				p.Passive++
+			}
+
+		case game.Showdown:
+			if a.Type == handhistory.Show || a.Type == handhistory.Muck {
+				showdown = true
+			}
+		}
+		if a.Type == handhistory.Fold {
+			folded[a.Player] = true
+		}
+	}
+
+	// Everyone who had not folded when the flop came saw it
+	if len(hh.Board) >= 3 {
+		for name, p := range out {
+			if !foldedBefore(hh, name, game.Flop) {
+				p.SawFlop = true
+			}
+		}
+	}
+
+	live := 0
+	for name := range out {
+		if !folded[name] {
+			live++
+		}
+	}
+	if live > 1 {
+		showdown = true
+	}
+	won := make(map[string]bool)
+	for _, a := range hh.Actions {
+		if a.Type == handhistory.Collect && a.Amount > 0 {
+			won[a.Player] = true
+		}
+	}
+	for name, p := range out {
+		if showdown && p.SawFlop && !folded[name] {
+			p.WentToShowdown = true
+			p.WonAtShowdown = won[name]
+		}
+	}
+	return out
+}
+
+func isDecision(t handhistory.ActionType) bool {
+	switch t {
+	case handhistory.Fold, handhistory.Check, handhistory.Call, handhistory.Bet, handhistory.Raise:
+		return true
+	}
+	return false
+}
+
+func foldedBefore(hh *handhistory.HandHistory, name string, street game.Street) bool {
+	for _, a := range hh.Actions {
+		if a.Player == name && a.Type == handhistory.Fold && a.Street < street {
+			return true
+		}
+	}
+	return false
+}
+
+// Positions names the position of every player dealt into a hand: the
+// blinds, the button, and counting back from it the cutoff (CO) and hijack
+// (HJ). Seats before those are UTG, UTG+1 and so on. Heads-up the button
+// posts the small blind and is called BTN.
+func Positions(hh *handhistory.HandHistory) map[string]string {
+	var seats []handhistory.Seat
+	for _, s := range hh.Seats {
+		if !s.SittingOut {
+			seats = append(seats, s)
+		}
+	}
+	sort.Slice(seats, func(i, j int) bool { return seats[i].Number < seats[j].Number })
+
+	// Rotate so the first seat is the one after the button
+	start := 0
+	for i, s := range seats {
+		if s.Number > hh.ButtonSeat {
+			start = i
+			break
+		}
+	}
+	order := append(append([]handhistory.Seat(nil), seats[start:]...), seats[:start]...)
+
+	positions := make(map[string]string)
+	n := len(order)
+	if n == 2 {
+		positions[order[1].Name] = "BTN"
+		positions[order[0].Name] = "BB"
+		return positions
+	}
+	tail := []string{"HJ", "CO", "BTN"}
+	for i, s := range order {
+		switch {
+		case i == 0:
+			positions[s.Name] = "SB"
+		case i == 1:
+			positions[s.Name] = "BB"
+		case n-i <= len(tail):
+			positions[s.Name] = tail[len(tail)-(n-i)]
+		case i == 2:
+			positions[s.Name] = "UTG"
+		default:
+			positions[s.Name] = "UTG+" + strconv.Itoa(i-2)
+		}
+	}
+	return positions
+}
@@ -0,0 +1,228 @@
+// Package stats aggregates per-player HUD statistics over hand histories.
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"texas-holdem-backend/handhistory"
+)
+
+// Stat is a statistic with its sample size. For percentages Count is how
+// often the player took an Opportunity and Value is Count/Opportunities.
+type Stat struct {
+	Count         int     `json:"count"`
+	Opportunities int     `json:"opportunities"`
+	Value         float64 `json:"value"`
+}
+
+func newStat(count, opportunities int) Stat {
+	s := Stat{Count: count, Opportunities: opportunities}
+	if opportunities > 0 {
+		s.Value = float64(count) / float64(opportunities)
+	}
+	return s
+}
+
+// PlayerStats are the HUD statistics of one player. The aggression factor
+// counts postflop bets and raises against postflop calls, so its
+// Opportunities are the calls and its Value can exceed 1.
+type PlayerStats struct {
+	ID         string `json:"id"` // See handhistory.HandHistory.PlayerID
+	Player     string `json:"player"`
+	Hands      int    `json:"hands"`
+	VPIP       Stat   `json:"vpip"`
+	PFR        Stat   `json:"pfr"`
+	ThreeBet   Stat   `json:"threeBet"`
+	FoldTo3Bet Stat   `json:"foldToThreeBet"`
+	CBet       Stat   `json:"cbet"`
+	Aggression Stat   `json:"aggressionFactor"`
+	WTSD       Stat   `json:"wtsd"`
+	WonAtSD    Stat   `json:"wsd"`
+	SawFlop    Stat   `json:"sawFlop"`
+}
+
+// totals are the raw counters behind PlayerStats
+type totals struct {
+	hands                  int
+	vpip, pfr              int
+	threeBetOpp, threeBet  int
+	facedThreeBet, foldTo3 int
+	cbetOpp, cbet          int
+	aggressive, passive    int
+	sawFlop, wtsd, wonAtSD int
+}
+
+func (t *totals) add(h *HandStats) {
+	t.hands++
+	t.vpip += b2i(h.VPIP)
+	t.pfr += b2i(h.PFR)
+	t.threeBetOpp += b2i(h.ThreeBetOpportunity)
+	t.threeBet += b2i(h.ThreeBet)
+	t.facedThreeBet += b2i(h.FacedThreeBet)
+	t.foldTo3 += b2i(h.FoldedToThree)
+	t.cbetOpp += b2i(h.CBetOpportunity)
+	t.cbet += b2i(h.CBet)
+	t.aggressive += h.Aggressive
+	t.passive += h.Passive
+	t.sawFlop += b2i(h.SawFlop)
+	t.wtsd += b2i(h.WentToShowdown)
+	t.wonAtSD += b2i(h.WonAtShowdown)
+}
+
+func (t *totals) merge(o *totals) {
+	t.hands += o.hands
+	t.vpip += o.vpip
+	t.pfr += o.pfr
+	t.threeBetOpp += o.threeBetOpp
+	t.threeBet += o.threeBet
+	t.facedThreeBet += o.facedThreeBet
+	t.foldTo3 += o.foldTo3
+	t.cbetOpp += o.cbetOpp
+	t.cbet += o.cbet
+	t.aggressive += o.aggressive
+	t.passive += o.passive
+	t.sawFlop += o.sawFlop
+	t.wtsd += o.wtsd
+	t.wonAtSD += o.wonAtSD
+}
+
+func (t *totals) stats(id, player string) PlayerStats {
+	return PlayerStats{
+		ID:         id,
+		Player:     player,
+		Hands:      t.hands,
+		VPIP:       newStat(t.vpip, t.hands),
+		PFR:        newStat(t.pfr, t.hands),
+		ThreeBet:   newStat(t.threeBet, t.threeBetOpp),
+		FoldTo3Bet: newStat(t.foldTo3, t.facedThreeBet),
+		CBet:       newStat(t.cbet, t.cbetOpp),
+		Aggression: newStat(t.aggressive, t.passive),
+		WTSD:       newStat(t.wtsd, t.sawFlop),
+		WonAtSD:    newStat(t.wonAtSD, t.wtsd),
+		SawFlop:    newStat(t.sawFlop, t.hands),
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Filter selects the hands statistics are computed over. Zero values match
+// everything; From and To are compared by calendar day in UTC, inclusive.
+type Filter struct {
+	Player   string // A name, matching that name at every site and table, or an ID
+	From     time.Time
+	To       time.Time
+	Stakes   string // As returned by Stakes, e.g. "$0.25/$0.50"
+	Position string // "SB", "BB", "UTG", "UTG+1", ..., "HJ", "CO" or "BTN"
+}
+
+// bucket groups the hands of one player that no filter can tell apart
+type bucket struct {
+	id       string
+	player   string
+	day      string
+	stakes   string
+	position string
+}
+
+const dayLayout = "2006-01-02"
+
+// Aggregator keeps running statistics as hands are added. Totals are kept
+// per player, day, stakes and position, so queries merge a handful of
+// buckets instead of rescanning hands. It is safe for concurrent use.
+type Aggregator struct {
+	mu      sync.RWMutex
+	buckets map[bucket]*totals
+	hands   int
+}
+
+// NewAggregator creates an empty aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{buckets: make(map[bucket]*totals)}
+}
+
+// Stakes describes the blinds of a hand the way filters refer to them
+func Stakes(hh *handhistory.HandHistory) string {
+	return hh.FormatAmount(hh.SmallBlind) + "/" + hh.FormatAmount(hh.BigBlind)
+}
+
+// Add folds a hand into the statistics
+func (a *Aggregator) Add(hh *handhistory.HandHistory) {
+	perPlayer := Analyze(hh)
+	day := hh.Time.UTC().Format(dayLayout)
+	stakes := Stakes(hh)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hands++
+	for player, h := range perPlayer {
+		key := bucket{id: hh.PlayerID(player), player: player, day: day, stakes: stakes, position: h.Position}
+		t, ok := a.buckets[key]
+		if !ok {
+			t = &totals{}
+			a.buckets[key] = t
+		}
+		t.add(h)
+	}
+}
+
+// Hands returns the number of hands added
+func (a *Aggregator) Hands() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.hands
+}
+
+// Query returns the statistics of every player with hands matching the
+// filter, sorted by number of hands and then by name. Players are told apart
+// by ID, so the same name at two tables gives two entries.
+func (a *Aggregator) Query(f Filter) []PlayerStats {
+	from, to := "", ""
+	if !f.From.IsZero() {
+		from = f.From.UTC().Format(dayLayout)
+	}
+	if !f.To.IsZero() {
+		to = f.To.UTC().Format(dayLayout)
+	}
+
+	a.mu.RLock()
+	merged := make(map[bucket]*totals) // By ID and name only
+	for key, t := range a.buckets {
+		if (f.Player != "" && key.player != f.Player && key.id != f.Player) ||
+			(from != "" && key.day < from) ||
+			(to != "" && key.day > to) ||
+			(f.Stakes != "" && key.stakes != f.Stakes) ||
+			(f.Position != "" && key.position != f.Position) {
+			continue
+		}
+		player := bucket{id: key.id, player: key.player}
+		m, ok := merged[player]
+		if !ok {
+			m = &totals{}
+			merged[player] = m
+		}
+		m.merge(t)
+	}
+	a.mu.RUnlock()
+
+	out := make([]PlayerStats, 0, len(merged))
+	for player, t := range merged {
+		out = append(out, t.stats(player.id, player.player))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hands != out[j].Hands {
+			return out[i].Hands > out[j].Hands
+		}
+		if out[i].Player != out[j].Player {
+			return out[i].Player < out[j].Player
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
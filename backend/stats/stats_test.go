@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"texas-holdem-backend/handhistory"
+)
+
+const threeBetHand = `PokerStars Hand #1001:  Hold'em No Limit ($0.50/$1 USD) - 2023/03/04 15:15:42 ET
+Table 'Vega' 6-max Seat #4 is the button
+Seat 1: Ann ($100 in chips)
+Seat 2: Ben ($100 in chips)
+Seat 3: Cid ($100 in chips)
+Seat 4: Dee ($100 in chips)
+Seat 5: Eve ($100 in chips)
+Seat 6: Fay ($100 in chips)
+Eve: posts small blind $0.50
+Fay: posts big blind $1
+*** HOLE CARDS ***
+Ann: raises $2 to $3
+Ben: folds
+Cid: raises $6 to $9
+Dee: folds
+Eve: folds
+Fay: folds
+Ann: folds
+Uncalled bet ($6) returned to Cid
+Cid collected $7.50 from pot
+*** SUMMARY ***
+Total pot $7.50 | Rake $0
+`
+
+const cbetHand = `PokerStars Hand #1002:  Hold'em No Limit ($0.25/$0.50 USD) - 2023/03/05 15:15:42 ET
+Table 'Andromeda V' 6-max Seat #3 is the button
+Seat 1: Villain1 ($48.75 in chips)
+Seat 3: Hero ($50 in chips)
+Seat 5: big fish ($61.20 in chips)
+big fish: posts small blind $0.25
+Villain1: posts big blind $0.50
+*** HOLE CARDS ***
+Dealt to Hero [Qs Qd]
+Hero: raises $1 to $1.50
+big fish: calls $1.25
+Villain1: folds
+*** FLOP *** [Qh 7c 2d]
+big fish: checks
+Hero: bets $2
+big fish: raises $4 to $6
+Hero: calls $4
+*** TURN *** [Qh 7c 2d] [9s]
+big fish: bets $10
+Hero: raises $32.50 to $42.50 and is all-in
+big fish: calls $32.50
+*** RIVER *** [Qh 7c 2d 9s] [3h]
+*** SHOW DOWN ***
+big fish: shows [7d 7s] (three of a kind, Sevens)
+Hero: shows [Qs Qd] (three of a kind, Queens)
+Hero collected $97.50 from pot
+*** SUMMARY ***
+Total pot $100.50 | Rake $3
+`
+
+func parse(t *testing.T, text string) *handhistory.HandHistory {
+	hands, errs := handhistory.ParseString(text)
+	if len(errs) != 0 || len(hands) != 1 {
+		t.Fatalf("Expected 1 hand, got %d hands and %v", len(hands), errs)
+	}
+	return hands[0]
+}
+
+func TestPositions(t *testing.T) {
+	got := Positions(parse(t, threeBetHand))
+	expected := map[string]string{"Ann": "UTG", "Ben": "HJ", "Cid": "CO", "Dee": "BTN", "Eve": "SB", "Fay": "BB"}
+	for name, pos := range expected {
+		if got[name] != pos {
+			t.Errorf("Expected %s in %s, got %s", name, pos, got[name])
+		}
+	}
+
+	hh := parse(t, cbetHand)
+	hh.Seats = hh.Seats[1:]
+	got = Positions(hh)
+	if got["Hero"] != "BTN" || got["big fish"] != "BB" {
+		t.Errorf("Expected heads-up BTN and BB, got %v", got)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     string
+		player   string
+		expected HandStats
+	}{
+		{"Opener folds to 3-bet", threeBetHand, "Ann", HandStats{Position: "UTG", VPIP: true, PFR: true, FacedThreeBet: true, FoldedToThree: true}},
+		{"Folds to open", threeBetHand, "Ben", HandStats{Position: "HJ", ThreeBetOpportunity: true}},
+		{"3-bets", threeBetHand, "Cid", HandStats{Position: "CO", VPIP: true, PFR: true, ThreeBetOpportunity: true, ThreeBet: true}},
+		{"Folds to 3-bet cold", threeBetHand, "Dee", HandStats{Position: "BTN"}},
+		{"C-bets and wins", cbetHand, "Hero", HandStats{Position: "BTN", VPIP: true, PFR: true, CBetOpportunity: true, CBet: true,
+			Aggressive: 2, Passive: 1, SawFlop: true, WentToShowdown: true, WonAtShowdown: true}},
+		{"Calls down and loses", cbetHand, "big fish", HandStats{Position: "SB", VPIP: true, ThreeBetOpportunity: true,
+			Aggressive: 2, Passive: 1, SawFlop: true, WentToShowdown: true}},
+		{"Big blind folds", cbetHand, "Villain1", HandStats{Position: "BB", ThreeBetOpportunity: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(parse(t, tt.hand))[tt.player]
+			if got == nil || *got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	agg := NewAggregator()
+	agg.Add(parse(t, threeBetHand))
+	agg.Add(parse(t, cbetHand))
+	agg.Add(parse(t, cbetHand))
+
+	if agg.Hands() != 3 {
+		t.Errorf("Expected 3 hands, got %d", agg.Hands())
+	}
+
+	all := agg.Query(Filter{})
+	if len(all) != 9 || all[0].Hands != 2 {
+		t.Fatalf("Expected 9 players led by the ones with 2 hands, got %+v", all)
+	}
+
+	hero := agg.Query(Filter{Player: "Hero"})
+	if len(hero) != 1 {
+		t.Fatalf("Expected stats for Hero only, got %+v", hero)
+	}
+	h := hero[0]
+	if h.Hands != 2 || h.VPIP != (Stat{2, 2, 1}) || h.CBet != (Stat{2, 2, 1}) || h.WonAtSD != (Stat{2, 2, 1}) {
+		t.Errorf("Unexpected stats for Hero: %+v", h)
+	}
+	if h.Aggression != (Stat{4, 2, 2}) {
+		t.Errorf("Expected aggression factor 2 from 4 bets and 2 calls, got %+v", h.Aggression)
+	}
+
+	filters := []struct {
+		name    string
+		filter  Filter
+		players int
+	}{
+		{"Position", Filter{Position: "CO"}, 1},
+		{"Stakes", Filter{Stakes: "$0.25/$0.50"}, 3},
+		{"Until", Filter{To: time.Date(2023, 3, 4, 0, 0, 0, 0, time.UTC)}, 6},
+		{"From", Filter{From: time.Date(2023, 3, 5, 0, 0, 0, 0, time.UTC)}, 3},
+		{"No match", Filter{Stakes: "$1/$2"}, 0},
+	}
+	for _, tt := range filters {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agg.Query(tt.filter); len(got) != tt.players {
+				t.Errorf("Expected %d players, got %+v", tt.players, got)
+			}
+		})
+	}
+
+	fold := agg.Query(Filter{Player: "Ann"})[0].FoldTo3Bet
+	if fold != (Stat{1, 1, 1}) {
+		t.Errorf("Expected Ann to fold to her only 3-bet, got %+v", fold)
+	}
+}
+
+func TestAggregatorTellsPlayersApart(t *testing.T) {
+	agg := NewAggregator()
+	imported := parse(t, cbetHand)
+	agg.Add(imported)
+	live := parse(t, cbetHand)
+	live.Site, live.TableName = "", "main"
+	agg.Add(live)
+
+	heroes := agg.Query(Filter{Player: "Hero"})
+	if len(heroes) != 2 || heroes[0].ID != "@main:Hero" || heroes[1].ID != imported.PlayerID("Hero") {
+		t.Fatalf("Expected two players named Hero, got %+v", heroes)
+	}
+	if got := agg.Query(Filter{Player: "@main:Hero"}); len(got) != 1 || got[0].Hands != 1 {
+		t.Errorf("Expected the live Hero only, got %+v", got)
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"texas-holdem-backend/handhistory"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket names. Hands are kept by key, with an index of keys in the order
+// they were saved and one per table for the hands dealt by the engine.
+var (
+	metaBucket       = []byte("meta")
+	handsBucket      = []byte("hands")
+	handOrderBucket  = []byte("hand_order")
+	tableHandsBucket = []byte("table_hands")
+	sessionsBucket   = []byte("sessions")
+	playersBucket    = []byte("players")
+	jobsBucket       = []byte("jobs")
+)
+
+// Bolt is a Store backed by a bbolt database file
+type Bolt struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates the database at path and brings its schema up
+// to date
+func OpenBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db, migrations); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Bolt{db: db}, nil
+}
+
+func seqKey(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
+
+func (b *Bolt) put(bucket []byte, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	})
+}
+
+func (b *Bolt) get(bucket []byte, key string, v interface{}) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, v)
+	})
+}
+
+// SaveHand implements Store
+func (b *Bolt) SaveHand(hh *handhistory.HandHistory) (bool, error) {
+	data, err := json.Marshal(hh)
+	if err != nil {
+		return false, err
+	}
+	key := []byte(handKey(hh))
+	created := false
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		hands := tx.Bucket(handsBucket)
+		if hands.Get(key) == nil {
+			created = true
+			order := tx.Bucket(handOrderBucket)
+			seq, err := order.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := order.Put(seqKey(seq), key); err != nil {
+				return err
+			}
+			if hh.Site == "" {
+				index := append([]byte(hh.TableName+"\x00"), seqKey(seq)...)
+				if err := tx.Bucket(tableHandsBucket).Put(index, key); err != nil {
+					return err
+				}
+			}
+		}
+		return hands.Put(key, data)
+	})
+	return created, err
+}
+
+// Hand implements Store
+func (b *Bolt) Hand(site, handID string) (*handhistory.HandHistory, error) {
+	hh := &handhistory.HandHistory{}
+	if err := b.get(handsBucket, site+"#"+handID, hh); err != nil {
+		return nil, err
+	}
+	return hh, nil
+}
+
+// handsIn decodes the hands whose keys are the values of an index bucket,
+// limited to index keys with the given prefix
+func (b *Bolt) handsIn(index, prefix []byte) ([]*handhistory.HandHistory, error) {
+	var out []*handhistory.HandHistory
+	err := b.db.View(func(tx *bolt.Tx) error {
+		hands := tx.Bucket(handsBucket)
+		c := tx.Bucket(index).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			hh := &handhistory.HandHistory{}
+			if err := json.Unmarshal(hands.Get(v), hh); err != nil {
+				return err
+			}
+			out = append(out, hh)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// TableHands implements Store
+func (b *Bolt) TableHands(tableID string) ([]*handhistory.HandHistory, error) {
+	return b.handsIn(tableHandsBucket, []byte(tableID+"\x00"))
+}
+
+// Hands implements Store
+func (b *Bolt) Hands() ([]*handhistory.HandHistory, error) {
+	return b.handsIn(handOrderBucket, nil)
+}
+
+// SaveSession implements Store
+func (b *Bolt) SaveSession(s Session) error {
+	return b.put(sessionsBucket, s.ID, s)
+}
+
+// Session implements Store
+func (b *Bolt) Session(id string) (Session, error) {
+	var s Session
+	err := b.get(sessionsBucket, id, &s)
+	return s, err
+}
+
+// Sessions implements Store
+func (b *Bolt) Sessions(tableID string) ([]Session, error) {
+	out := []Session{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(_, data []byte) error {
+			var s Session
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+			if tableID == "" || s.TableID == tableID {
+				out = append(out, s)
+			}
+			return nil
+		})
+	})
+	sortSessions(out)
+	return out, err
+}
+
+// SavePlayer implements Store
+func (b *Bolt) SavePlayer(p Player) error {
+	return b.put(playersBucket, p.ID, p)
+}
+
+// Player implements Store
+func (b *Bolt) Player(id string) (Player, error) {
+	var p Player
+	err := b.get(playersBucket, id, &p)
+	return p, err
+}
+
+// Players implements Store
+func (b *Bolt) Players() ([]Player, error) {
+	out := []Player{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(playersBucket).ForEach(func(_, data []byte) error {
+			var p Player
+			if err := json.Unmarshal(data, &p); err != nil {
+				return err
+			}
+			out = append(out, p)
+			return nil
+		})
+	})
+	sortPlayers(out)
+	return out, err
+}
+
+// SaveJob implements Store
+func (b *Bolt) SaveJob(j Job) error {
+	return b.put(jobsBucket, j.ID, j)
+}
+
+// Job implements Store
+func (b *Bolt) Job(id string) (Job, error) {
+	var j Job
+	err := b.get(jobsBucket, id, &j)
+	return j, err
+}
+
+// Close implements Store
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"texas-holdem-backend/handhistory"
+)
+
+// Memory is a Store that keeps records in the process. Records are copied
+// in and out through JSON, so it behaves like Bolt down to what survives
+// the round trip.
+type Memory struct {
+	mu        sync.RWMutex
+	hands     map[string][]byte
+	handOrder []string
+	sessions  map[string][]byte
+	players   map[string][]byte
+	jobs      map[string][]byte
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		hands:    make(map[string][]byte),
+		sessions: make(map[string][]byte),
+		players:  make(map[string][]byte),
+		jobs:     make(map[string][]byte),
+	}
+}
+
+func (m *Memory) put(bucket map[string][]byte, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket[key] = data
+	return nil
+}
+
+func (m *Memory) get(bucket map[string][]byte, key string, v interface{}) error {
+	m.mu.RLock()
+	data, ok := bucket[key]
+	m.mu.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SaveHand implements Store
+func (m *Memory) SaveHand(hh *handhistory.HandHistory) (bool, error) {
+	data, err := json.Marshal(hh)
+	if err != nil {
+		return false, err
+	}
+	key := handKey(hh)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, exists := m.hands[key]
+	if !exists {
+		m.handOrder = append(m.handOrder, key)
+	}
+	m.hands[key] = data
+	return !exists, nil
+}
+
+// Hand implements Store
+func (m *Memory) Hand(site, handID string) (*handhistory.HandHistory, error) {
+	hh := &handhistory.HandHistory{}
+	if err := m.get(m.hands, site+"#"+handID, hh); err != nil {
+		return nil, err
+	}
+	return hh, nil
+}
+
+func (m *Memory) handsWhere(match func(hh *handhistory.HandHistory) bool) ([]*handhistory.HandHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []*handhistory.HandHistory
+	for _, key := range m.handOrder {
+		hh := &handhistory.HandHistory{}
+		if err := json.Unmarshal(m.hands[key], hh); err != nil {
+			return nil, err
+		}
+		if match(hh) {
+			out = append(out, hh)
+		}
+	}
+	return out, nil
+}
+
+// TableHands implements Store
+func (m *Memory) TableHands(tableID string) ([]*handhistory.HandHistory, error) {
+	return m.handsWhere(func(hh *handhistory.HandHistory) bool {
+		return hh.Site == "" && hh.TableName == tableID
+	})
+}
+
+// Hands implements Store
+func (m *Memory) Hands() ([]*handhistory.HandHistory, error) {
+	return m.handsWhere(func(*handhistory.HandHistory) bool { return true })
+}
+
+// SaveSession implements Store
+func (m *Memory) SaveSession(s Session) error {
+	return m.put(m.sessions, s.ID, s)
+}
+
+// Session implements Store
+func (m *Memory) Session(id string) (Session, error) {
+	var s Session
+	err := m.get(m.sessions, id, &s)
+	return s, err
+}
+
+// Sessions implements Store
+func (m *Memory) Sessions(tableID string) ([]Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Session{}
+	for _, data := range m.sessions {
+		var s Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if tableID == "" || s.TableID == tableID {
+			out = append(out, s)
+		}
+	}
+	sortSessions(out)
+	return out, nil
+}
+
+// SavePlayer implements Store
+func (m *Memory) SavePlayer(p Player) error {
+	return m.put(m.players, p.ID, p)
+}
+
+// Player implements Store
+func (m *Memory) Player(name string) (Player, error) {
+	var p Player
+	err := m.get(m.players, name, &p)
+	return p, err
+}
+
+// Players implements Store
+func (m *Memory) Players() ([]Player, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := []Player{}
+	for _, data := range m.players {
+		var p Player
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	sortPlayers(out)
+	return out, nil
+}
+
+// SaveJob implements Store
+func (m *Memory) SaveJob(j Job) error {
+	return m.put(m.jobs, j.ID, j)
+}
+
+// Job implements Store
+func (m *Memory) Job(id string) (Job, error) {
+	var j Job
+	err := m.get(m.jobs, id, &j)
+	return j, err
+}
+
+// Close implements Store
+func (m *Memory) Close() error {
+	return nil
+}
+
+func sortSessions(sessions []Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].Started.Equal(sessions[j].Started) {
+			return sessions[i].Started.Before(sessions[j].Started)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// migration upgrades the database schema to its version. Migrations run in
+// order, each in its own transaction, and are never edited once released:
+// schema changes are made by appending a new one.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *bolt.Tx) error
+}
+
+var migrations = []migration{
+	{1, "create buckets", func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{handsBucket, handOrderBucket, tableHandsBucket, sessionsBucket, playersBucket, jobsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+var schemaVersionKey = []byte("schema_version")
+
+// schemaVersion returns the version the database was last migrated to, 0
+// for a new database
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	meta := tx.Bucket(metaBucket)
+	if meta == nil {
+		return 0, nil
+	}
+	v := meta.Get(schemaVersionKey)
+	if v == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(v))
+}
+
+// migrate applies the migrations newer than the database's schema version.
+// A database written by a newer version of the server is left untouched.
+func migrate(db *bolt.DB, migrations []migration) error {
+	var current int
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		current, err = schemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reading schema version: %v", err)
+	}
+	if latest := migrations[len(migrations)-1].version; current > latest {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		err := db.Update(func(tx *bolt.Tx) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			meta, err := tx.CreateBucketIfNotExists(metaBucket)
+			if err != nil {
+				return err
+			}
+			return meta.Put(schemaVersionKey, []byte(strconv.Itoa(m.version)))
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %v", m.version, m.name, err)
+		}
+		slog.Info("applied database migration", "version", m.version, "name", m.name)
+	}
+	return nil
+}
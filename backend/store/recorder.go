@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"texas-holdem-backend/handhistory"
+)
+
+// SessionGap is how long a table can go without a hand before the next
+// hand dealt there starts a new session
+const SessionGap = 30 * time.Minute
+
+// Recorder saves hands as they are played or imported and keeps the table
+// sessions and player profiles they belong to up to date. It is safe for
+// concurrent use.
+type Recorder struct {
+	store Store
+
+	mu       sync.Mutex
+	sessions map[string]*Session // Latest session of each table
+}
+
+// NewRecorder creates a recorder writing to the store
+func NewRecorder(s Store) *Recorder {
+	return &Recorder{store: s, sessions: make(map[string]*Session)}
+}
+
+// Record saves a hand and reports whether it was new. Profiles and sessions
+// count each hand once, however often it is recorded.
+func (r *Recorder) Record(hh *handhistory.HandHistory) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created, err := r.store.SaveHand(hh)
+	if err != nil || !created {
+		return created, err
+	}
+	for _, s := range hh.Seats {
+		if s.SittingOut {
+			continue
+		}
+		if err := r.seen(hh, s.Name); err != nil {
+			return true, err
+		}
+	}
+	if hh.Site == "" {
+		return true, r.extendSession(hh)
+	}
+	return true, nil
+}
+
+func (r *Recorder) seen(hh *handhistory.HandHistory, name string) error {
+	p, err := r.store.Player(hh.PlayerID(name))
+	if errors.Is(err, ErrNotFound) {
+		p = newPlayer(hh, name)
+	} else if err != nil {
+		return err
+	}
+	p.count(hh.Time)
+	return r.store.SavePlayer(p)
+}
+
+// newPlayer starts the profile of a player first seen in hh
+func newPlayer(hh *handhistory.HandHistory, name string) Player {
+	p := Player{ID: hh.PlayerID(name), Name: name, Site: hh.Site, FirstSeen: hh.Time, LastSeen: hh.Time}
+	if hh.Site == "" {
+		p.TableID = hh.TableName
+	}
+	return p
+}
+
+// count adds a hand played at the given time to the profile
+func (p *Player) count(at time.Time) {
+	p.Hands++
+	if at.Before(p.FirstSeen) {
+		p.FirstSeen = at
+	}
+	if at.After(p.LastSeen) {
+		p.LastSeen = at
+	}
+}
+
+func (r *Recorder) extendSession(hh *handhistory.HandHistory) error {
+	s, ok := r.sessions[hh.TableName]
+	if !ok {
+		// Carry on the table's last session from before a restart
+		sessions, err := r.store.Sessions(hh.TableName)
+		if err != nil {
+			return err
+		}
+		if len(sessions) > 0 {
+			s = &sessions[len(sessions)-1]
+		}
+	}
+	if s == nil || hh.Time.Sub(s.Ended) > SessionGap {
+		s = &Session{ID: NewID(), TableID: hh.TableName, Started: hh.Time, FirstHand: hh.HandID}
+	}
+	r.sessions[hh.TableName] = s
+
+	s.Ended = hh.Time
+	s.Hands++
+	s.LastHand = hh.HandID
+	for _, seat := range hh.Seats {
+		if !seat.SittingOut && !contains(s.Players, seat.Name) {
+			s.Players = append(s.Players, seat.Name)
+		}
+	}
+	return r.store.SaveSession(*s)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
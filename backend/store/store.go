@@ -0,0 +1,116 @@
+// Package store persists hand histories, table sessions, player profiles
+// and simulation results. Bolt keeps them in a single file on disk; Memory
+// keeps them in the process for tests and throwaway servers.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"texas-holdem-backend/handhistory"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store is where the backend keeps everything that must outlive the
+// process. Implementations are safe for concurrent use.
+type Store interface {
+	// SaveHand stores a hand and reports whether it was new. A hand with
+	// the same key replaces the stored one but keeps its place in order.
+	SaveHand(hh *handhistory.HandHistory) (bool, error)
+	// Hand looks up an imported hand by site and hand ID
+	Hand(site, handID string) (*handhistory.HandHistory, error)
+	// TableHands returns the hands recorded at a live table, oldest first
+	TableHands(tableID string) ([]*handhistory.HandHistory, error)
+	// Hands returns every hand in the order it was first saved
+	Hands() ([]*handhistory.HandHistory, error)
+
+	SaveSession(s Session) error
+	Session(id string) (Session, error)
+	// Sessions returns the sessions of a table, or of every table when
+	// tableID is empty, by start time
+	Sessions(tableID string) ([]Session, error)
+
+	SavePlayer(p Player) error
+	Player(id string) (Player, error)
+	// Players returns every player profile sorted by name, then ID
+	Players() ([]Player, error)
+
+	SaveJob(j Job) error
+	Job(id string) (Job, error)
+
+	Close() error
+}
+
+// Session is a stretch of play at one live table
+type Session struct {
+	ID        string    `json:"id"`
+	TableID   string    `json:"tableId"`
+	Started   time.Time `json:"started"`
+	Ended     time.Time `json:"ended"`
+	Hands     int       `json:"hands"`
+	FirstHand string    `json:"firstHand"`
+	LastHand  string    `json:"lastHand"`
+	Players   []string  `json:"players"`
+}
+
+// Player is the profile of a player seen at the tables or in imported
+// hands. DisplayName and Notes are edited by users; the rest is kept up to
+// date as hands are recorded.
+type Player struct {
+	ID          string    `json:"id"` // See HandHistory.PlayerID
+	Name        string    `json:"name"`
+	Site        string    `json:"site,omitempty"`
+	TableID     string    `json:"tableId,omitempty"` // For players at the live tables
+	DisplayName string    `json:"displayName,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	Hands       int       `json:"hands"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// Job is a simulation request and its result
+type Job struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"` // e.g. "montecarlo"
+	Request  json.RawMessage `json:"request,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Created  time.Time       `json:"created"`
+	Finished time.Time       `json:"finished,omitempty"`
+}
+
+// NewID returns a random identifier for sessions and jobs
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sortPlayers orders profiles by name, then ID
+func sortPlayers(players []Player) {
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Name != players[j].Name {
+			return players[i].Name < players[j].Name
+		}
+		return players[i].ID < players[j].ID
+	})
+}
+
+// handKey identifies a hand. Imported hands are unique per site; hands
+// dealt by the engine have no site and are numbered per table.
+func handKey(hh *handhistory.HandHistory) string {
+	if hh.Site == "" {
+		return tableKey(hh.TableName, hh.HandID)
+	}
+	return hh.Site + "#" + hh.HandID
+}
+
+func tableKey(tableID, handID string) string {
+	return "@" + tableID + "#" + handID
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var start = time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+
+func tableHand(table, id string, at time.Time, players ...string) *handhistory.HandHistory {
+	hh := &handhistory.HandHistory{
+		HandID:     id,
+		Game:       "Hold'em",
+		Limit:      "No Limit",
+		SmallBlind: 1,
+		BigBlind:   2,
+		Time:       at,
+		TableName:  table,
+		ButtonSeat: 1,
+		Actions: []handhistory.Action{
+			{Street: game.Preflop, Player: players[0], Type: handhistory.PostSmallBlind, Amount: 1},
+			{Street: game.Showdown, Player: players[0], Type: handhistory.Show, Cards: []string{"HA", "DA"}},
+		},
+	}
+	for i, name := range players {
+		hh.Seats = append(hh.Seats, handhistory.Seat{Number: i + 1, Name: name, Stack: 200})
+	}
+	return hh
+}
+
+func importedHand(id string) *handhistory.HandHistory {
+	hh := tableHand("Andromeda V", id, start, "Hero", "Villain")
+	hh.Site = "PokerStars"
+	hh.Currency = "USD"
+	hh.Tournament = &handhistory.Tournament{ID: "42", BuyIn: "$10+$1 USD"}
+	return hh
+}
+
+// stores runs a test against every implementation
+func stores(t *testing.T, test func(t *testing.T, s Store)) {
+	t.Run("Memory", func(t *testing.T) {
+		test(t, NewMemory())
+	})
+	t.Run("Bolt", func(t *testing.T) {
+		s, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer s.Close()
+		test(t, s)
+	})
+}
+
+func TestHands(t *testing.T) {
+	stores(t, func(t *testing.T, s Store) {
+		for _, hh := range []*handhistory.HandHistory{
+			tableHand("main", "1", start, "Alice", "Bob"),
+			importedHand("1"),
+			tableHand("side", "1", start, "Carol", "Dave"),
+			tableHand("main", "2", start.Add(time.Minute), "Alice", "Bob"),
+		} {
+			if created, err := s.SaveHand(hh); err != nil || !created {
+				t.Fatalf("Expected hand %s at %q to be new, got %v, %v", hh.HandID, hh.TableName, created, err)
+			}
+		}
+
+		// Saving again replaces the hand in place
+		replaced := tableHand("main", "1", start, "Alice", "Bob")
+		replaced.Rake = 5
+		if created, err := s.SaveHand(replaced); err != nil || created {
+			t.Errorf("Expected the hand to be replaced, got %v, %v", created, err)
+		}
+
+		main, err := s.TableHands("main")
+		if err != nil || len(main) != 2 || main[0].HandID != "1" || main[0].Rake != 5 || main[1].HandID != "2" {
+			t.Errorf("Unexpected hands at main: %+v, %v", main, err)
+		}
+		if main[0].Actions[1].Street != game.Showdown || main[0].Actions[1].Cards[1] != "DA" {
+			t.Errorf("Expected actions to survive storage, got %+v", main[0].Actions)
+		}
+
+		all, err := s.Hands()
+		if err != nil || len(all) != 4 || all[1].Site != "PokerStars" || all[2].TableName != "side" {
+			t.Errorf("Unexpected hands: %+v, %v", all, err)
+		}
+
+		hh, err := s.Hand("PokerStars", "1")
+		if err != nil || hh.Tournament == nil || hh.Tournament.BuyIn != "$10+$1 USD" || !hh.Time.Equal(start) {
+			t.Errorf("Unexpected imported hand: %+v, %v", hh, err)
+		}
+		if _, err := s.Hand("PokerStars", "2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if hands, err := s.TableHands("empty"); err != nil || len(hands) != 0 {
+			t.Errorf("Expected no hands, got %+v, %v", hands, err)
+		}
+	})
+}
+
+func TestRecords(t *testing.T) {
+	stores(t, func(t *testing.T, s Store) {
+		if _, err := s.Session("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a session, got %v", err)
+		}
+		if _, err := s.Player("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a player, got %v", err)
+		}
+		if _, err := s.Job("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a job, got %v", err)
+		}
+
+		s.SaveSession(Session{ID: "b", TableID: "main", Started: start.Add(time.Hour)})
+		s.SaveSession(Session{ID: "a", TableID: "main", Started: start})
+		s.SaveSession(Session{ID: "c", TableID: "side", Started: start})
+		if got, _ := s.Sessions("main"); len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+			t.Errorf("Expected sessions a and b in order, got %+v", got)
+		}
+		if got, _ := s.Sessions(""); len(got) != 3 {
+			t.Errorf("Expected 3 sessions, got %+v", got)
+		}
+
+		s.SavePlayer(Player{ID: "@main:Zed", Name: "Zed"})
+		s.SavePlayer(Player{ID: "@main:Amy", Name: "Amy", Notes: "Limps a lot"})
+		if got, _ := s.Players(); len(got) != 2 || got[0].ID != "@main:Amy" || got[0].Notes != "Limps a lot" {
+			t.Errorf("Unexpected players: %+v", got)
+		}
+
+		job := Job{ID: "j1", Kind: "montecarlo", Result: json.RawMessage(`{"win":0.5}`), Created: start}
+		if err := s.SaveJob(job); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, err := s.Job("j1"); err != nil || string(got.Result) != `{"win":0.5}` || !got.Created.Equal(start) {
+			t.Errorf("Unexpected job: %+v, %v", got, err)
+		}
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	stores(t, func(t *testing.T, s Store) {
+		r := NewRecorder(s)
+		hands := []*handhistory.HandHistory{
+			tableHand("main", "1", start, "Alice", "Bob"),
+			tableHand("main", "2", start.Add(5*time.Minute), "Alice", "Carol"),
+			tableHand("main", "2", start.Add(5*time.Minute), "Alice", "Carol"),
+			tableHand("main", "3", start.Add(time.Hour), "Alice", "Bob"),
+			importedHand("7"),
+			tableHand("side", "1", start, "Alice", "Hero"),
+		}
+		for _, hh := range hands {
+			if _, err := r.Record(hh); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		sessions, _ := s.Sessions("main")
+		if len(sessions) != 2 {
+			t.Fatalf("Expected a new session after an hour's break, got %+v", sessions)
+		}
+		first := sessions[0]
+		if first.Hands != 2 || first.FirstHand != "1" || first.LastHand != "2" || len(first.Players) != 3 ||
+			!first.Ended.Equal(start.Add(5*time.Minute)) {
+			t.Errorf("Unexpected first session: %+v", first)
+		}
+
+		alice, err := s.Player("@main:Alice")
+		if err != nil || alice.Hands != 3 || alice.TableID != "main" || !alice.FirstSeen.Equal(start) || !alice.LastSeen.Equal(start.Add(time.Hour)) {
+			t.Errorf("Unexpected profile: %+v, %v", alice, err)
+		}
+		if hero, err := s.Player("PokerStars:Hero"); err != nil || hero.Hands != 1 || hero.Site != "PokerStars" {
+			t.Errorf("Expected imported hands to count towards profiles, got %+v, %v", hero, err)
+		}
+
+		// The same name at another table or site is another player
+		if other, err := s.Player("@side:Alice"); err != nil || other.Hands != 1 {
+			t.Errorf("Expected a profile per table, got %+v, %v", other, err)
+		}
+		if players, _ := s.Players(); len(players) != 7 {
+			t.Errorf("Expected 7 profiles, got %+v", players)
+		}
+
+		// A new recorder, as after a restart, carries on the last session
+		if _, err := NewRecorder(s).Record(tableHand("main", "4", start.Add(61*time.Minute), "Alice", "Bob")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sessions, _ := s.Sessions("main"); len(sessions) != 2 || sessions[1].Hands != 2 {
+			t.Errorf("Expected the second session to continue, got %+v", sessions)
+		}
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.SaveHand(importedHand("1"))
+	s.Close()
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var applied []int
+	version := len(migrations) + 1
+	next := append(append([]migration{}, migrations...), migration{version, "test", func(tx *bolt.Tx) error {
+		applied = append(applied, version)
+		_, err := tx.CreateBucket([]byte("extra"))
+		return err
+	}})
+	for i := 0; i < 2; i++ {
+		if err := migrate(db, next); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected the new migration to run once, ran %d times", len(applied))
+	}
+	db.View(func(tx *bolt.Tx) error {
+		if v, _ := schemaVersion(tx); v != version {
+			t.Errorf("Expected schema version %d, got %d", version, v)
+		}
+		return nil
+	})
+	db.Close()
+
+	// The new version is unknown to this build, so the database is refused
+	if _, err := OpenBolt(path); err == nil {
+		t.Errorf("Expected an error opening a newer database")
+	}
+
+	failing := []migration{{1, "fail", func(tx *bolt.Tx) error {
+		tx.CreateBucket([]byte("half"))
+		return errors.New("boom")
+	}}}
+	db, _ = bolt.Open(filepath.Join(t.TempDir(), "fail.db"), 0600, nil)
+	defer db.Close()
+	if err := migrate(db, failing); err == nil {
+		t.Errorf("Expected the failing migration to be reported")
+	}
+	db.View(func(tx *bolt.Tx) error {
+		if v, _ := schemaVersion(tx); v != 0 || tx.Bucket([]byte("half")) != nil {
+			t.Errorf("Expected the failed migration to be rolled back, got version %d", v)
+		}
+		return nil
+	})
+}
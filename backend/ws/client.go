@@ -0,0 +1,244 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"texas-holdem-backend/game"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 4096
+)
+
+var (
+	errNotJoined = errors.New("join a table first")
+	errNoSeat    = errors.New("take a seat first")
+)
+
+// client is one WebSocket connection. The seat and player ID are only
+// touched while the room lock is held.
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+
+	room     *Room
+	playerID string
+	seat     int
+}
+
+// deliver queues a message, dropping the client if it cannot keep up.
+// Before joining a room only the client's own read loop sends to it, so
+// closing the connection is enough: the loop ends and closes send.
+func (c *client) deliver(msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		if c.room == nil {
+			c.conn.Close()
+			return
+		}
+		delete(c.room.clients, c)
+		close(c.send)
+	}
+}
+
+func (c *client) readPump() {
+	defer func() {
+		if c.room != nil {
+			c.room.mu.Lock()
+			if c.room.clients[c] {
+				delete(c.room.clients, c)
+				close(c.send)
+			}
+			c.room.disconnected(c)
+			c.room.mu.Unlock()
+		} else {
+			close(c.send)
+		}
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.reply(serverMessage{Type: "error", Message: "invalid message: " + err.Error()})
+			continue
+		}
+		if err := c.handle(msg); err != nil {
+			c.reply(serverMessage{Type: "error", Message: err.Error()})
+		}
+	}
+}
+
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// reply sends a message to this client only
+func (c *client) reply(msg serverMessage) {
+	if c.room == nil {
+		c.deliver(mustMarshal(msg))
+		return
+	}
+	c.room.mu.Lock()
+	defer c.room.mu.Unlock()
+	if c.room.clients[c] {
+		c.deliver(mustMarshal(msg))
+	}
+}
+
+func (c *client) handle(msg clientMessage) error {
+	if msg.Type == "join" {
+		return c.join(msg)
+	}
+	if c.room == nil {
+		return errNotJoined
+	}
+
+	room := c.room
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	switch msg.Type {
+	case "sit":
+		if c.playerID != "" {
+			return game.ErrAlreadySeated
+		}
+		name := msg.Name
+		if name == "" {
+			name = fmt.Sprintf("Player %d", msg.Seat+1)
+		}
+		token := newToken()
+		if err := room.table.Sit(token, name, msg.Seat, msg.BuyIn); err != nil {
+			return err
+		}
+		c.playerID = token
+		c.seat = msg.Seat
+		seat := msg.Seat
+		c.deliver(mustMarshal(serverMessage{Type: "seated", Token: token, Seat: &seat}))
+
+	case "addBot":
+		// Only players at the table may fill its seats with bots
+		if c.playerID == "" {
+			return errNoSeat
+		}
+		if err := room.AddBot(msg.Bot, msg.Seat, msg.BuyIn); err != nil {
+			return err
+		}
+
+	case "action":
+		if c.playerID == "" {
+			return errNoSeat
+		}
+		if msg.Action == nil {
+			return errors.New("missing action")
+		}
+		if err := room.table.Act(c.playerID, *msg.Action); err != nil {
+			return err
+		}
+
+	case "leave":
+		if c.playerID == "" {
+			return errNoSeat
+		}
+		if err := room.table.Leave(c.playerID); err != nil {
+			return err
+		}
+		c.playerID = ""
+		c.seat = -1
+
+	case "state":
+		room.sendState(c)
+		return nil
+
+	default:
+		return errors.New("unknown message type: " + msg.Type)
+	}
+
+	room.afterChange()
+	return nil
+}
+
+// join attaches the client to a table, resuming a seat when the token of a
+// seated player is given
+func (c *client) join(msg clientMessage) error {
+	if c.room != nil {
+		return errors.New("already joined a table")
+	}
+	if msg.TableID == "" {
+		return errors.New("missing tableId")
+	}
+
+	room := c.hub.Room(msg.TableID)
+	room.mu.Lock()
+	for room.closed {
+		// The room closed after the hub handed it out; a new one replaces it
+		room.mu.Unlock()
+		room = c.hub.Room(msg.TableID)
+		room.mu.Lock()
+	}
+	defer room.mu.Unlock()
+
+	c.room = room
+	room.clients[c] = true
+	if msg.Token != "" {
+		p := room.table.Player(msg.Token)
+		if p == nil {
+			room.sendState(c)
+			return game.ErrNotSeated
+		}
+		c.playerID = p.ID
+		c.seat = p.Seat
+		room.reconnected(p.ID)
+	}
+
+	room.sendState(c)
+	if !room.table.InHand() {
+		room.afterChange()
+	}
+	return nil
+}
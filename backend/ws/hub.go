@@ -0,0 +1,419 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"texas-holdem-backend/bot"
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config holds the settings shared by every table of a hub
+type Config struct {
+	Table         game.TableConfig
+	ActionTimeout time.Duration // Time a player has to act before being checked or folded
+	NextHandDelay time.Duration // Pause between hands
+	BotDelay      time.Duration // Time a bot takes to act
+	// DisconnectGrace is how long a seated player who lost the connection
+	// keeps the seat before leaving the table
+	DisconnectGrace time.Duration
+
+	// OnHand, if set, is called with every finished hand of every table
+	OnHand func(*handhistory.HandHistory)
+	// LoadHands, if set, returns the hands a table played before the
+	// server started, oldest first
+	LoadHands func(tableID string) []*handhistory.HandHistory
+}
+
+// DefaultConfig is a 9-max no-limit 1/2 table with a 30 second clock
+func DefaultConfig() Config {
+	return Config{
+		Table: game.TableConfig{
+			MaxSeats:   9,
+			SmallBlind: 1,
+			BigBlind:   2,
+			Structure:  game.NoLimit{},
+		},
+		ActionTimeout:   30 * time.Second,
+		NextHandDelay:   3 * time.Second,
+		BotDelay:        time.Second,
+		DisconnectGrace: time.Minute,
+	}
+}
+
+// Hub owns the live tables and the WebSocket connections to them. A table
+// is removed once nobody is watching it and no player holds a seat there.
+type Hub struct {
+	cfg      Config
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	rooms map[string]*Room
+}
+
+// NewHub creates a hub whose tables are created on first join
+func NewHub(cfg Config) *Hub {
+	return &Hub{
+		cfg: cfg,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		rooms: make(map[string]*Room),
+	}
+}
+
+// Lookup returns the room with the given ID if it exists
+func (h *Hub) Lookup(id string) (*Room, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	room, ok := h.rooms[id]
+	return room, ok
+}
+
+// Room returns the table room with the given ID, creating it if needed
+func (h *Hub) Room(id string) *Room {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[id]
+	if !ok {
+		room = newRoom(h, id, h.cfg)
+		h.rooms[id] = room
+	}
+	return room
+}
+
+// remove forgets a room that has closed
+func (h *Hub) remove(room *Room) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rooms[room.id] == room {
+		delete(h.rooms, room.id)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &client{
+		hub:  h,
+		conn: conn,
+		send: make(chan []byte, 256),
+		seat: -1,
+	}
+	go c.writePump()
+	c.readPump()
+}
+
+// Room is a live table and the clients watching or playing at it
+type Room struct {
+	id  string
+	hub *Hub
+	cfg Config
+
+	mu         sync.Mutex
+	table      *game.Table
+	clients    map[*client]bool
+	bots       map[string]bot.Bot
+	away       map[string]*time.Timer // Grace timers of disconnected players, by player ID
+	hands      []*handhistory.HandHistory
+	actTimer   *time.Timer
+	handTimer  *time.Timer
+	timerToken int
+	closed     bool
+}
+
+func newRoom(hub *Hub, id string, cfg Config) *Room {
+	room := &Room{
+		id:      id,
+		hub:     hub,
+		cfg:     cfg,
+		table:   game.NewTable(id, cfg.Table),
+		clients: make(map[*client]bool),
+		bots:    make(map[string]bot.Bot),
+		away:    make(map[string]*time.Timer),
+	}
+	room.table.Subscribe(room.broadcast)
+	handhistory.NewRecorder(room.table, room.record)
+	if cfg.LoadHands != nil {
+		room.restore(cfg.LoadHands(id))
+	}
+	return room
+}
+
+// maxHands is the number of hand histories kept per table
+const maxHands = 1000
+
+func (r *Room) record(hh *handhistory.HandHistory) {
+	r.hands = append(r.hands, hh)
+	if len(r.hands) > maxHands {
+		r.hands = r.hands[len(r.hands)-maxHands:]
+	}
+	if r.cfg.OnHand != nil {
+		r.cfg.OnHand(hh)
+	}
+}
+
+// restore takes up the hand histories of an earlier run of the table and
+// numbers new hands after them
+func (r *Room) restore(hands []*handhistory.HandHistory) {
+	if len(hands) > maxHands {
+		hands = hands[len(hands)-maxHands:]
+	}
+	r.hands = hands
+	for _, hh := range hands {
+		if n, err := strconv.Atoi(hh.HandID); err == nil && n > r.table.HandNumber() {
+			r.table.SetHandNumber(n)
+		}
+	}
+}
+
+// Hands returns the recorded hand histories of the table, oldest first
+func (r *Room) Hands() []*handhistory.HandHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*handhistory.HandHistory(nil), r.hands...)
+}
+
+// PlayerName returns the name of the player seated with the given token,
+// the one the sit message returned
+func (r *Room) PlayerName(token string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.table.Player(token); p != nil {
+		return p.Name, true
+	}
+	return "", false
+}
+
+// broadcast sends a table event to the clients allowed to see it. It is
+// called by the table while the room lock is held.
+func (r *Room) broadcast(e game.Event) {
+	msg := mustMarshal(serverMessage{Type: "event", Event: &e})
+	for c := range r.clients {
+		if e.Private && c.seat != e.Seat {
+			continue
+		}
+		c.deliver(msg)
+	}
+}
+
+// sendStates sends every client its own view of the table
+func (r *Room) sendStates() {
+	for c := range r.clients {
+		r.sendState(c)
+	}
+}
+
+func (r *Room) sendState(c *client) {
+	state := r.table.State(c.playerID)
+	c.deliver(mustMarshal(serverMessage{Type: "state", State: &state}))
+}
+
+// afterChange schedules the action clock and the next hand. It must be
+// called with the room lock held after every change to the table.
+func (r *Room) afterChange() {
+	r.timerToken++
+	token := r.timerToken
+	if r.actTimer != nil {
+		r.actTimer.Stop()
+	}
+
+	if p := r.table.ToAct(); p != nil {
+		id := p.ID
+		delay := r.cfg.ActionTimeout
+		if _, ok := r.bots[id]; ok {
+			delay = r.cfg.BotDelay
+		}
+		r.actTimer = time.AfterFunc(delay, func() { r.timeout(id, token) })
+	} else if !r.table.InHand() && r.table.CanStart() && r.handTimer == nil {
+		r.handTimer = time.AfterFunc(r.cfg.NextHandDelay, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.handTimer = nil
+			if r.closed {
+				return
+			}
+			if err := r.table.StartHand(); err == nil {
+				r.afterChange()
+			}
+		})
+	}
+
+	r.sendStates()
+}
+
+// timeout acts for the player whose clock ran out: a bot plays its
+// decision, anybody else is checked or folded. Bots decide on their own
+// view of the table without holding the room lock, so that slow
+// strategies do not hold up the clients of the room.
+func (r *Room) timeout(id string, token int) {
+	r.mu.Lock()
+	if token != r.timerToken {
+		r.mu.Unlock()
+		return
+	}
+	b, isBot := r.bots[id]
+	var state game.TableState
+	if isBot {
+		state = r.table.State(id)
+	}
+	r.mu.Unlock()
+
+	var decision game.Action
+	if isBot {
+		decision = b.Decide(state)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token != r.timerToken {
+		return
+	}
+	action := r.table.TimeoutAction(id)
+	if isBot {
+		action = decision
+	}
+	if err := r.table.Act(id, action); err != nil {
+		r.table.Act(id, r.table.TimeoutAction(id))
+	}
+	r.afterChange()
+}
+
+// disconnected is called with the room lock held when a client's
+// connection ends. A seated player keeps the seat for the grace period,
+// in case they reconnect, and then leaves the table.
+func (r *Room) disconnected(c *client) {
+	if id := c.playerID; id != "" && !r.watching(id) && r.away[id] == nil {
+		r.away[id] = time.AfterFunc(r.cfg.DisconnectGrace, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.away[id] == nil {
+				return
+			}
+			delete(r.away, id)
+			if r.table.Leave(id) == nil {
+				r.afterChange()
+			}
+			r.closeIfIdle()
+		})
+	}
+	r.closeIfIdle()
+}
+
+// reconnected stops the grace timer of a player who is back
+func (r *Room) reconnected(id string) {
+	if t := r.away[id]; t != nil {
+		t.Stop()
+		delete(r.away, id)
+	}
+}
+
+// watching reports whether a client of the player is connected
+func (r *Room) watching(id string) bool {
+	for c := range r.clients {
+		if c.playerID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// closeIfIdle closes the room and removes it from the hub once it has no
+// clients and no disconnected player is waiting to come back. It is called
+// with the room lock held.
+func (r *Room) closeIfIdle() {
+	if r.closed || len(r.clients) > 0 || len(r.away) > 0 {
+		return
+	}
+	r.closed = true
+	r.stopTimers()
+	r.hub.remove(r)
+}
+
+// AddBot seats a bot with the given strategy in a free seat
+func (r *Room) AddBot(strategy string, seat, buyIn int) error {
+	b, ok := bot.New(strategy, nil)
+	if !ok {
+		return fmt.Errorf("unknown bot strategy: %s", strategy)
+	}
+	id := newToken()
+	name := fmt.Sprintf("Bot %d (%s)", seat+1, strategy)
+	if err := r.table.Sit(id, name, seat, buyIn); err != nil {
+		return err
+	}
+	r.bots[id] = b
+	return nil
+}
+
+// Close stops the room's timers
+func (r *Room) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopTimers()
+	for id, t := range r.away {
+		t.Stop()
+		delete(r.away, id)
+	}
+}
+
+func (r *Room) stopTimers() {
+	r.timerToken++
+	if r.actTimer != nil {
+		r.actTimer.Stop()
+	}
+	if r.handTimer != nil {
+		r.handTimer.Stop()
+		r.handTimer = nil
+	}
+}
+
+// clientMessage is a message sent by a client
+type clientMessage struct {
+	Type    string       `json:"type"`
+	TableID string       `json:"tableId,omitempty"`
+	Token   string       `json:"token,omitempty"`
+	Name    string       `json:"name,omitempty"`
+	Seat    int          `json:"seat,omitempty"`
+	BuyIn   int          `json:"buyIn,omitempty"`
+	Bot     string       `json:"bot,omitempty"`
+	Action  *game.Action `json:"action,omitempty"`
+}
+
+// serverMessage is a message sent to a client
+type serverMessage struct {
+	Type    string           `json:"type"`
+	Token   string           `json:"token,omitempty"`
+	Seat    *int             `json:"seat,omitempty"`
+	Message string           `json:"message,omitempty"`
+	Event   *game.Event      `json:"event,omitempty"`
+	State   *game.TableState `json:"state,omitempty"`
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("encoding websocket message", "error", err)
+	}
+	return data
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,300 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"texas-holdem-backend/game"
+	"texas-holdem-backend/handhistory"
+
+	"github.com/gorilla/websocket"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dial(t *testing.T, server *httptest.Server) *testClient {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn}
+}
+
+func (c *testClient) send(msg clientMessage) {
+	c.t.Helper()
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("Failed to send: %v", err)
+	}
+}
+
+// waitFor reads messages until one matches, failing after a timeout
+func (c *testClient) waitFor(match func(serverMessage) bool) serverMessage {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.t.Fatalf("Failed waiting for message: %v", err)
+		}
+		var msg serverMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.t.Fatalf("Invalid message %s: %v", data, err)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+func isEvent(typ game.EventType) func(serverMessage) bool {
+	return func(m serverMessage) bool {
+		return m.Type == "event" && m.Event.Type == typ
+	}
+}
+
+func newTestServer(t *testing.T, timeout time.Duration) *httptest.Server {
+	cfg := DefaultConfig()
+	cfg.ActionTimeout = timeout
+	cfg.NextHandDelay = 10 * time.Millisecond
+	hub := NewHub(cfg)
+	server := httptest.NewServer(hub)
+	t.Cleanup(func() {
+		hub.Room("t1").Close()
+		server.Close()
+	})
+	return server
+}
+
+func TestPlayersOnlySeeOwnHoleCards(t *testing.T) {
+	server := newTestServer(t, time.Minute)
+
+	alice := dial(t, server)
+	bob := dial(t, server)
+	watcher := dial(t, server)
+
+	alice.send(clientMessage{Type: "join", TableID: "t1"})
+	alice.send(clientMessage{Type: "sit", Seat: 0, BuyIn: 100, Name: "Alice"})
+	alice.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+
+	watcher.send(clientMessage{Type: "join", TableID: "t1"})
+	watcher.waitFor(func(m serverMessage) bool { return m.Type == "state" })
+
+	bob.send(clientMessage{Type: "join", TableID: "t1"})
+	bob.send(clientMessage{Type: "sit", Seat: 1, BuyIn: 100, Name: "Bob"})
+	bob.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+
+	for _, c := range []*testClient{alice, bob} {
+		msg := c.waitFor(isEvent(game.EventHoleCards))
+		if len(msg.Event.Cards) != 2 {
+			t.Fatalf("Expected 2 hole cards, got %v", msg.Event.Cards)
+		}
+		state := c.waitFor(func(m serverMessage) bool { return m.Type == "state" && m.State.InHand })
+		for _, s := range state.State.Seats {
+			if s.Seat != state.State.YourSeat && len(s.Cards) != 0 {
+				t.Errorf("Seat %d saw seat %d cards", state.State.YourSeat, s.Seat)
+			}
+		}
+	}
+
+	// The watcher gets the public events but never any hole cards
+	watcher.waitFor(isEvent(game.EventBigBlind))
+	state := watcher.waitFor(func(m serverMessage) bool { return m.Type == "state" && m.State.InHand })
+	for _, s := range state.State.Seats {
+		if len(s.Cards) != 0 {
+			t.Errorf("Watcher saw seat %d cards", s.Seat)
+		}
+	}
+}
+
+func TestReconnectRestoresSeat(t *testing.T) {
+	server := newTestServer(t, time.Minute)
+
+	alice := dial(t, server)
+	alice.send(clientMessage{Type: "join", TableID: "t1"})
+	alice.send(clientMessage{Type: "sit", Seat: 2, BuyIn: 100, Name: "Alice"})
+	seated := alice.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+
+	bob := dial(t, server)
+	bob.send(clientMessage{Type: "join", TableID: "t1"})
+	bob.send(clientMessage{Type: "sit", Seat: 5, BuyIn: 100, Name: "Bob"})
+	hole := alice.waitFor(isEvent(game.EventHoleCards))
+	alice.conn.Close()
+
+	again := dial(t, server)
+	again.send(clientMessage{Type: "join", TableID: "t1", Token: seated.Token})
+	state := again.waitFor(func(m serverMessage) bool { return m.Type == "state" })
+
+	if state.State.YourSeat != 2 {
+		t.Fatalf("Expected to resume seat 2, got %d", state.State.YourSeat)
+	}
+	for _, s := range state.State.Seats {
+		if s.Seat == 2 && strings.Join(s.Cards, " ") != strings.Join(hole.Event.Cards, " ") {
+			t.Errorf("Expected restored hole cards %v, got %v", hole.Event.Cards, s.Cards)
+		}
+	}
+}
+
+func TestActionTimeout(t *testing.T) {
+	server := newTestServer(t, 50*time.Millisecond)
+
+	alice := dial(t, server)
+	alice.send(clientMessage{Type: "join", TableID: "t1"})
+	alice.send(clientMessage{Type: "sit", Seat: 0, BuyIn: 100, Name: "Alice"})
+
+	bob := dial(t, server)
+	bob.send(clientMessage{Type: "join", TableID: "t1"})
+	bob.send(clientMessage{Type: "sit", Seat: 1, BuyIn: 100, Name: "Bob"})
+
+	// Nobody acts: the small blind facing a bet is folded by the clock
+	msg := alice.waitFor(isEvent(game.EventAction))
+	if msg.Event.Action != game.Fold {
+		t.Errorf("Expected a timeout fold, got %s", msg.Event.Action)
+	}
+}
+
+func TestRejectsActionOutOfTurn(t *testing.T) {
+	server := newTestServer(t, time.Minute)
+
+	alice := dial(t, server)
+	alice.send(clientMessage{Type: "join", TableID: "t1"})
+	alice.send(clientMessage{Type: "sit", Seat: 0, BuyIn: 100, Name: "Alice"})
+
+	bob := dial(t, server)
+	bob.send(clientMessage{Type: "join", TableID: "t1"})
+	bob.send(clientMessage{Type: "sit", Seat: 1, BuyIn: 100, Name: "Bob"})
+	bob.waitFor(isEvent(game.EventHoleCards))
+
+	// Heads-up the button (Alice) acts first preflop
+	bob.send(clientMessage{Type: "action", Action: &game.Action{Type: game.Check}})
+	msg := bob.waitFor(func(m serverMessage) bool { return m.Type == "error" })
+	if msg.Message != game.ErrNotYourTurn.Error() {
+		t.Errorf("Expected not-your-turn error, got %q", msg.Message)
+	}
+}
+
+func TestRejectsTakenName(t *testing.T) {
+	server := newTestServer(t, time.Minute)
+
+	first := dial(t, server)
+	first.send(clientMessage{Type: "join", TableID: "t1"})
+	first.send(clientMessage{Type: "sit", Seat: 0, BuyIn: 100, Name: "Alice"})
+	first.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+
+	second := dial(t, server)
+	second.send(clientMessage{Type: "join", TableID: "t1"})
+	second.send(clientMessage{Type: "sit", Seat: 1, BuyIn: 100, Name: "Alice"})
+	msg := second.waitFor(func(m serverMessage) bool { return m.Type == "error" || m.Type == "seated" })
+	if msg.Type != "error" || msg.Message != game.ErrNameTaken.Error() {
+		t.Errorf("Expected a name-taken error, got %+v", msg)
+	}
+
+	// Without a name the seat number tells players apart
+	second.send(clientMessage{Type: "sit", Seat: 1, BuyIn: 100})
+	second.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+	third := dial(t, server)
+	third.send(clientMessage{Type: "join", TableID: "t1"})
+	third.send(clientMessage{Type: "sit", Seat: 2, BuyIn: 100})
+	third.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+}
+
+func TestBotsPlayEachOther(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NextHandDelay = 10 * time.Millisecond
+	cfg.BotDelay = time.Millisecond
+	hub := NewHub(cfg)
+	server := httptest.NewServer(hub)
+	t.Cleanup(func() {
+		hub.Room("bots").Close()
+		server.Close()
+	})
+
+	// Spectators cannot seat bots
+	watcher := dial(t, server)
+	watcher.send(clientMessage{Type: "join", TableID: "bots"})
+	watcher.send(clientMessage{Type: "addBot", Bot: "random", Seat: 0, BuyIn: 100})
+	msg := watcher.waitFor(func(m serverMessage) bool { return m.Type == "error" })
+	if msg.Message != errNoSeat.Error() {
+		t.Errorf("Expected a take-a-seat error, got %q", msg.Message)
+	}
+
+	// A player seats two bots and leaves them to it
+	host := dial(t, server)
+	host.send(clientMessage{Type: "join", TableID: "bots"})
+	host.send(clientMessage{Type: "sit", Seat: 8, BuyIn: 100, Name: "Host"})
+	host.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+	host.send(clientMessage{Type: "addBot", Bot: "nonsense", Seat: 4, BuyIn: 100})
+	msg = host.waitFor(func(m serverMessage) bool { return m.Type == "error" })
+	if !strings.Contains(msg.Message, "unknown bot strategy") {
+		t.Errorf("Expected unknown strategy error, got %q", msg.Message)
+	}
+	host.send(clientMessage{Type: "addBot", Bot: "random", Seat: 0, BuyIn: 100})
+	host.send(clientMessage{Type: "addBot", Bot: "tag", Seat: 3, BuyIn: 100})
+	host.send(clientMessage{Type: "leave"})
+
+	watcher.waitFor(isEvent(game.EventLeave))
+	watcher.waitFor(isEvent(game.EventHandEnd))
+}
+
+func TestDisconnectedPlayerLeaves(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NextHandDelay = time.Hour
+	cfg.DisconnectGrace = 50 * time.Millisecond
+	hub := NewHub(cfg)
+	server := httptest.NewServer(hub)
+	t.Cleanup(server.Close)
+
+	alice := dial(t, server)
+	alice.send(clientMessage{Type: "join", TableID: "t1"})
+	alice.send(clientMessage{Type: "sit", Seat: 0, BuyIn: 100, Name: "Alice"})
+	alice.waitFor(func(m serverMessage) bool { return m.Type == "seated" })
+	watcher := dial(t, server)
+	watcher.send(clientMessage{Type: "join", TableID: "t1"})
+	watcher.waitFor(func(m serverMessage) bool { return m.Type == "state" })
+
+	alice.conn.Close()
+	msg := watcher.waitFor(isEvent(game.EventLeave))
+	if msg.Event.Name != "Alice" {
+		t.Errorf("Expected Alice to leave, got %+v", msg.Event)
+	}
+
+	// Once the last client goes the table is removed
+	watcher.conn.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := hub.Lookup("t1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the idle table to be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRoomRestoresHands(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LoadHands = func(tableID string) []*handhistory.HandHistory {
+		return []*handhistory.HandHistory{
+			{HandID: "41", TableName: tableID},
+			{HandID: "42", TableName: tableID},
+		}
+	}
+	room := NewHub(cfg).Room("restored")
+	defer room.Close()
+
+	if hands := room.Hands(); len(hands) != 2 || hands[1].HandID != "42" {
+		t.Errorf("Expected the 2 stored hands, got %+v", hands)
+	}
+	if n := room.table.HandNumber(); n != 42 {
+		t.Errorf("Expected new hands to be numbered after 42, got %d", n)
+	}
+}
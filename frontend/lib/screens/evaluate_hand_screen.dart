@@ -257,6 +257,17 @@ class _EvaluateHandScreenState extends State<EvaluateHandScreen> {
                       const SizedBox(height: 16),
                       _buildResultRow('Best Hand', _result!['bestHand']),
                       _buildResultRow('Hand Value', _result!['handValue']),
+                      if (_result!['strength'] != null) ...[
+                        _buildResultRow('Strength', _result!['strength']['label']),
+                        _buildResultRow(
+                          'Beats',
+                          '${(_result!['strength']['beats'] * 100).toStringAsFixed(1)}% of holdings',
+                        ),
+                        _buildResultRow(
+                          'Combos Ahead',
+                          '${_result!['strength']['combosAhead']}',
+                        ),
+                      ],
                       const SizedBox(height: 16),
                       const Text(
                         'Best 5 Cards:',
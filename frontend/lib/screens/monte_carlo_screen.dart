@@ -349,7 +349,9 @@ class _MonteCarloScreenState extends State<MonteCarloScreen> {
                       const SizedBox(height: 16),
                       Center(
                         child: Text(
-                          '${_result!['simulations']} simulations',
+                          _result!['source'] == 'table'
+                              ? 'Precomputed preflop equity'
+                              : '${_result!['simulations']} simulations',
                           style: TextStyle(
                             fontSize: 12,
                             color: Colors.white.withOpacity(0.7),
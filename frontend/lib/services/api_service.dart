@@ -3,13 +3,26 @@ import 'package:http/http.dart' as http;
 
 class ApiService {
   // Use relative URL - nginx will proxy /api/* to the backend service
-  static const String baseUrl = '';
+  static const String baseUrl = '/api/v1';
+
+  // Errors come back as {"code", "message", "field", "details"}
+  static String _errorMessage(http.Response response) {
+    try {
+      final error = jsonDecode(response.body);
+      if (error['field'] != null) {
+        return '${error['field']}: ${error['message']}';
+      }
+      return error['message'] ?? response.body;
+    } catch (_) {
+      return response.body;
+    }
+  }
 
   Future<Map<String, dynamic>> evaluateHand(
       List<String> holeCards, List<String> boardCards) async {
     try {
       final response = await http.post(
-        Uri.parse('$baseUrl/api/evaluate'),
+        Uri.parse('$baseUrl/evaluate'),
         headers: {'Content-Type': 'application/json'},
         body: jsonEncode({
           'holeCards': holeCards,
@@ -20,7 +33,7 @@ class ApiService {
       if (response.statusCode == 200) {
         return jsonDecode(response.body);
       } else {
-        throw Exception('Failed to evaluate hand: ${response.body}');
+        throw Exception('Failed to evaluate hand: ${_errorMessage(response)}');
       }
     } catch (e) {
       throw Exception('Error connecting to server: $e');
@@ -33,7 +46,7 @@ class ApiService {
       List<String> communityCards) async {
     try {
       final response = await http.post(
-        Uri.parse('$baseUrl/api/compare'),
+        Uri.parse('$baseUrl/compare'),
         headers: {'Content-Type': 'application/json'},
         body: jsonEncode({
           'player1HoleCards': player1Hole,
@@ -45,7 +58,7 @@ class ApiService {
       if (response.statusCode == 200) {
         return jsonDecode(response.body);
       } else {
-        throw Exception('Failed to compare hands: ${response.body}');
+        throw Exception('Failed to compare hands: ${_errorMessage(response)}');
       }
     } catch (e) {
       throw Exception('Error connecting to server: $e');
@@ -59,7 +72,7 @@ class ApiService {
       int numSimulations) async {
     try {
       final response = await http.post(
-        Uri.parse('$baseUrl/api/montecarlo'),
+        Uri.parse('$baseUrl/montecarlo'),
         headers: {'Content-Type': 'application/json'},
         body: jsonEncode({
           'holeCards': holeCards,
@@ -72,7 +85,7 @@ class ApiService {
       if (response.statusCode == 200) {
         return jsonDecode(response.body);
       } else {
-        throw Exception('Failed to run Monte Carlo: ${response.body}');
+        throw Exception('Failed to run Monte Carlo: ${_errorMessage(response)}');
       }
     } catch (e) {
       throw Exception('Error connecting to server: $e');
@@ -31,7 +31,7 @@ export default function () {
     boardCards: ['HQ', 'HJ', 'HT', 'D2', 'C3'],
   });
 
-  const evaluateRes = http.post(`${BASE_URL}/api/evaluate`, evaluatePayload, {
+  const evaluateRes = http.post(`${BASE_URL}/api/v1/evaluate`, evaluatePayload, {
     headers: { 'Content-Type': 'application/json' },
   });
 
@@ -43,18 +43,14 @@ export default function () {
   sleep(1);
 
   // Test 3: Compare hands
+  // Fields as in the CompareHandsRequest schema of /openapi.json
   const comparePayload = JSON.stringify({
-    player1: {
-      holeCards: ['HA', 'HK'],
-      boardCards: ['HQ', 'HJ', 'HT', 'D2', 'C3'],
-    },
-    player2: {
-      holeCards: ['SA', 'SK'],
-      boardCards: ['HQ', 'HJ', 'HT', 'D2', 'C3'],
-    },
+    player1HoleCards: ['HA', 'HK'],
+    player2HoleCards: ['SA', 'SK'],
+    communityCards: ['HQ', 'HJ', 'HT', 'D2', 'C3'],
   });
 
-  const compareRes = http.post(`${BASE_URL}/api/compare`, comparePayload, {
+  const compareRes = http.post(`${BASE_URL}/api/v1/compare`, comparePayload, {
     headers: { 'Content-Type': 'application/json' },
   });
 
@@ -73,7 +69,7 @@ export default function () {
     numSimulations: 1000,
   });
 
-  const monteCarloRes = http.post(`${BASE_URL}/api/montecarlo`, monteCarloPayload, {
+  const monteCarloRes = http.post(`${BASE_URL}/api/v1/montecarlo`, monteCarloPayload, {
     headers: { 'Content-Type': 'application/json' },
   });
 